@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type AirportCatalog interface {
+	Find(code string) (airport.Airport, error)
+	Search(query string, limit int) []airport.Airport
+}
+
+type Response struct {
+	IATACode  string  `json:"iata_code"`
+	ICAOCode  string  `json:"icao_code"`
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Country   string  `json:"country"`
+	TimeZone  string  `json:"time_zone"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Adapter looks an airport up by its IATA code (v1/{code}) or lists the
+// airports matching what a user is typing (v1?q=bog)
+func Adapter(catalog AirportCatalog) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		if code, ok := req.PathParameters["code"]; ok {
+			found, err := catalog.Find(strings.ToUpper(code))
+			if err == airport.ErrUnknownAirport {
+				return internal.Error(http.StatusNotFound, err), nil
+			}
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+			responseBytes, _ := json.Marshal(toResponse(found))
+			return internal.Respond(http.StatusOK, string(responseBytes)), nil
+		}
+
+		// Get request parameters
+		query := req.QueryStringParameters["q"]
+		if internal.TrimLines(query) == "" {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+		limit := defaultLimit
+		if v, ok := req.QueryStringParameters["limit"]; ok {
+			l, err := strconv.Atoi(v)
+			if err != nil || l <= 0 || l > maxLimit {
+				return internal.Error(http.StatusBadRequest, errors.New("invalid limit")), nil
+			}
+			limit = l
+		}
+
+		// Respond
+		airports := catalog.Search(query, limit)
+		response := make([]Response, len(airports))
+		for i, a := range airports {
+			response[i] = toResponse(a)
+		}
+		responseBytes, _ := json.Marshal(response)
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func toResponse(a airport.Airport) Response {
+	return Response{
+		IATACode:  a.IATACode,
+		ICAOCode:  a.ICAOCode,
+		Name:      a.Name,
+		City:      a.City,
+		Country:   a.Country,
+		TimeZone:  a.TimeZone,
+		Latitude:  a.Latitude,
+		Longitude: a.Longitude,
+	}
+}
+
+func main() {
+	catalog, err := airport.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	lambda.Start(Adapter(catalog))
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type AirportCatalogMock struct {
+	mock.Mock
+}
+
+func (m *AirportCatalogMock) Find(code string) (airport.Airport, error) {
+	ret := m.Called(code)
+	return ret.Get(0).(airport.Airport), ret.Error(1)
+}
+
+func (m *AirportCatalogMock) Search(query string, limit int) []airport.Airport {
+	ret := m.Called(query, limit)
+	return ret.Get(0).([]airport.Airport)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		catalog *AirportCatalogMock
+	}
+
+	bog := airport.Airport{
+		IATACode:  "BOG",
+		ICAOCode:  "SKBO",
+		Name:      "El Dorado International Airport",
+		City:      "Bogotá",
+		Country:   "CO",
+		TimeZone:  "America/Bogota",
+		Latitude:  4.7016,
+		Longitude: -74.1469,
+	}
+	bogBody := `{"iata_code":"BOG","icao_code":"SKBO","name":"El Dorado International Airport",
+		"city":"Bogotá","country":"CO","time_zone":"America/Bogota","latitude":4.7016,"longitude":-74.1469}`
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code with the airport of the code",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"code": "bog",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(bogBody),
+			},
+			mocks: mocks{
+				catalog: &AirportCatalogMock{},
+			},
+			mocker: func(m mocks) {
+				m.catalog.On("Find", "BOG").Return(bog, nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status code because the airport is unknown",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"code": "XXX",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, airport.ErrUnknownAirport),
+				),
+			},
+			mocks: mocks{
+				catalog: &AirportCatalogMock{},
+			},
+			mocker: func(m mocks) {
+				m.catalog.On("Find", "XXX").Return(airport.Airport{}, airport.ErrUnknownAirport).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code with the airports matching the query",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"q": "bog",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`[` + bogBody + `]`),
+			},
+			mocks: mocks{
+				catalog: &AirportCatalogMock{},
+			},
+			mocker: func(m mocks) {
+				m.catalog.On("Search", "bog", defaultLimit).Return([]airport.Airport{bog}).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code with an empty list because nothing matches",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"q":     "zzz",
+					"limit": "3",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: `[]`,
+			},
+			mocks: mocks{
+				catalog: &AirportCatalogMock{},
+			},
+			mocker: func(m mocks) {
+				m.catalog.On("Search", "zzz", 3).Return([]airport.Airport{}).Once()
+			},
+		},
+		{
+			name: "Get a 400 status code because the query is missing",
+			req:  events.APIGatewayProxyRequest{},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				catalog: &AirportCatalogMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status code because the limit is too big",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"q":     "bog",
+					"limit": "500",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid limit"]}`),
+			},
+			mocks: mocks{
+				catalog: &AirportCatalogMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.catalog)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.catalog.AssertExpectations(t)
+		})
+	}
+
+}
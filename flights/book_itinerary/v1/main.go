@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/itinerary"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/policy"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/pricing"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/ssr"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/meetupaws/flight_seat_reservation/internal/payment"
+)
+
+var (
+	ErrMixedCurrencies = errors.New("mixed_currencies")
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	HoldSeat(reservation model.Reservation, until time.Time) error
+	ReleaseSeat(flightID string, seatID string, passengerID string) error
+	ReserveItinerary(reservations []model.Reservation) ([]model.Reservation, error)
+}
+
+type Enqueuer interface {
+	SendMsg(msg interface{}, queue string) error
+	SendDelayedMsg(msg interface{}, queue string, delay time.Duration) error
+}
+
+type Request struct {
+	PassengerID      string       `json:"passenger_id"`
+	ExitRowConfirmed bool         `json:"exit_row_confirmed"`
+	PaymentToken     string       `json:"payment_token"`
+	Legs             []RequestLeg `json:"legs"`
+	// SpecialRequests are IATA SSR codes, e.g. WCHR or VGML
+	SpecialRequests []string `json:"special_requests"`
+}
+
+type RequestLeg struct {
+	FlightID string `json:"flight_id"`
+	SeatID   string `json:"seat_id"`
+}
+
+type Response struct {
+	Locator     string        `json:"locator"`
+	PassengerID string        `json:"passenger_id"`
+	Legs        []ResponseLeg `json:"legs"`
+}
+
+type ResponseLeg struct {
+	FlightID string `json:"flight_id"`
+	SeatID   string `json:"seat_id"`
+}
+
+// seatHoldDuration is how long the seats are kept for the passenger while the
+// payment is authorized
+const seatHoldDuration = 10 * time.Minute
+
+// Adapter books a seat on every leg of a trip with connections under one
+// record locator, either every seat is reserved or none. The legs go in the
+// order they are flown and every connection must be at least minConnection
+// long. The price of all the seats is charged at once
+func Adapter(
+	flightsRepo FlightsRepository,
+	airports itinerary.AirportCatalog,
+	payments payment.PaymentProvider,
+	enqueuer Enqueuer,
+	notificationsQueue string,
+	waitlistQueue string,
+	minConnection time.Duration,
+) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		request := Request{}
+		err := json.Unmarshal([]byte(req.Body), &request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Validations
+		if internal.TrimLines(request.PassengerID) == "" || len(request.Legs) == 0 {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+		for _, leg := range request.Legs {
+			if internal.TrimLines(leg.FlightID) == "" || internal.TrimLines(leg.SeatID) == "" {
+				return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+			}
+		}
+		if len(request.Legs) > itinerary.MaxLegs {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid legs")), nil
+		}
+		specialRequests, err := ssr.Validate(request.SpecialRequests)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Find the flights and check they connect
+		flights := make([]model.Flight, len(request.Legs))
+		for i, leg := range request.Legs {
+			flights[i], err = flightsRepo.Find(leg.FlightID)
+			if err == repository.ErrNoFlightsFound {
+				return internal.Error(http.StatusNotFound, err), nil
+			}
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+		}
+		err = itinerary.Validate(flights, airports, minConnection)
+		if errors.Is(err, itinerary.ErrInvalidItinerary) ||
+			errors.Is(err, itinerary.ErrConnectionTooShort) ||
+			err == airport.ErrUnknownAirport {
+			return internal.Error(http.StatusUnprocessableEntity, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Check the passenger is allowed to take every seat and price them
+		seats := make([]model.FlightSeat, len(flights))
+		reservations := make([]model.Reservation, len(flights))
+		total := model.Price{}
+		for i, flight := range flights {
+			seats[i] = getSeat(flight, request.Legs[i].SeatID)
+			quote := pricing.Quote{}
+			if seats[i].ID != "" {
+				err = policy.CheckSeat(seats[i], policy.Passenger{
+					Cabin:            policy.EntitledCabin(flight.Fares, seats[i]),
+					ExitRowConfirmed: request.ExitRowConfirmed,
+					SpecialRequests:  specialRequests,
+				})
+				if err != nil {
+					return internal.Error(http.StatusUnprocessableEntity, err), nil
+				}
+
+				quote, err = pricing.QuoteSeat(flight.Fares, seats[i])
+				if err != nil {
+					return internal.Error(http.StatusUnprocessableEntity, err), nil
+				}
+			}
+			if quote.Total > 0 {
+				if total.Currency != "" && total.Currency != quote.Currency {
+					return internal.Error(http.StatusUnprocessableEntity, ErrMixedCurrencies), nil
+				}
+				total.Amount += quote.Total
+				total.Currency = quote.Currency
+			}
+
+			reservations[i] = model.Reservation{
+				FlightID:    flight.ID,
+				SeatID:      request.Legs[i].SeatID,
+				PassengerID: request.PassengerID,
+				Price:       quote.Price(),
+
+				SpecialRequests: specialRequests,
+			}
+		}
+
+		// Priced seats are held until the payment is captured
+		authorization := payment.Authorization{}
+		if total.Amount > 0 {
+			if internal.TrimLines(request.PaymentToken) == "" {
+				return internal.Error(http.StatusBadRequest, errors.New("missing payment_token")), nil
+			}
+
+			until := time.Now().Add(seatHoldDuration)
+			for i, reservation := range reservations {
+				err = flightsRepo.HoldSeat(reservation, until)
+				if err != nil {
+					releaseSeats(flightsRepo, enqueuer, waitlistQueue, reservations[:i])
+					return reserveError(err), nil
+				}
+				watchHold(enqueuer, waitlistQueue, reservation)
+			}
+
+			authorization, err = payments.Authorize(payment.AuthorizationRequest{
+				Amount:    total.Amount,
+				Currency:  total.Currency,
+				Reference: reference(reservations),
+				Token:     request.PaymentToken,
+			})
+			if err != nil {
+				releaseSeats(flightsRepo, enqueuer, waitlistQueue, reservations)
+				if err == payment.ErrPaymentDeclined {
+					return internal.Error(http.StatusPaymentRequired, err), nil
+				}
+				return internal.Error(http.StatusBadGateway, err), nil
+			}
+			for i := range reservations {
+				reservations[i].PaymentID = authorization.ID
+			}
+
+			// Take the money while the seats are still held, seats are never
+			// confirmed without being paid
+			err = payments.Capture(authorization.ID)
+			if err != nil {
+				refundErr := payments.Refund(authorization.ID)
+				if refundErr != nil {
+					log.Printf("An error ocurred while refunding authorization %v: %v", authorization.ID, refundErr)
+				}
+				releaseSeats(flightsRepo, enqueuer, waitlistQueue, reservations)
+				return internal.Error(http.StatusBadGateway, err), nil
+			}
+		}
+
+		// Reserve the seats
+		reserved, err := flightsRepo.ReserveItinerary(reservations)
+		if err != nil {
+			if authorization.ID != "" {
+				refundErr := payments.Refund(authorization.ID)
+				if refundErr != nil {
+					log.Printf("An error ocurred while refunding authorization %v: %v", authorization.ID, refundErr)
+				}
+				releaseSeats(flightsRepo, enqueuer, waitlistQueue, reservations)
+			}
+			return reserveError(err), nil
+		}
+
+		// Send message to queue
+		msg := model.QueueMsgItinerary{
+			Type:            model.QueueMsgTypeItinerary,
+			Locator:         reserved[0].Locator,
+			UserID:          request.PassengerID,
+			Legs:            make([]model.QueueMsgLegSeat, len(flights)),
+			Price:           total,
+			SpecialRequests: specialRequests,
+		}
+		for i, flight := range flights {
+			msg.Legs[i] = model.QueueMsgLegSeat{
+				FlightID:          flight.ID,
+				FlightDeparture:   flight.Departure,
+				FlightOrigin:      flight.Origin,
+				FlightDestination: flight.Destination,
+				SeatLetter:        seats[i].Letter,
+				SeatRow:           seats[i].Row,
+			}
+		}
+		err = enqueuer.SendMsg(msg, notificationsQueue)
+		if err != nil {
+			log.Printf("An error ocurred while sending message to queue %v: %v", notificationsQueue, err)
+		}
+
+		// Respond
+		response := Response{
+			Locator:     reserved[0].Locator,
+			PassengerID: request.PassengerID,
+			Legs:        make([]ResponseLeg, len(reserved)),
+		}
+		for i, r := range reserved {
+			response.Legs[i] = ResponseLeg{
+				FlightID: r.FlightID,
+				SeatID:   r.SeatID,
+			}
+		}
+		responseBytes, _ := json.Marshal(response)
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func reserveError(err error) events.APIGatewayProxyResponse {
+	if err == repository.ErrNoSeatFoundInFlight {
+		return internal.Error(http.StatusNotFound, err)
+	}
+	if err == repository.ErrSeatNotAvailable || err == repository.ErrFlightClosed {
+		return internal.Error(http.StatusUnprocessableEntity, err)
+	}
+	if err == repository.ErrStaleFlight {
+		return internal.Error(http.StatusConflict, err)
+	}
+	return internal.Error(http.StatusInternalServerError, err)
+}
+
+// reference tells the payment provider what is paid, e.g.
+// f1/1A+f2/3C/someone@some.com
+func reference(reservations []model.Reservation) string {
+	seats := make([]string, len(reservations))
+	for i, r := range reservations {
+		seats[i] = fmt.Sprintf("%v/%v", r.FlightID, r.SeatID)
+	}
+	return fmt.Sprintf("%v/%v", strings.Join(seats, "+"), reservations[0].PassengerID)
+}
+
+// watchHold checks the hold once it runs out, a seat neither reserved nor
+// released by then is freed for the waitlist
+func watchHold(enqueuer Enqueuer, waitlistQueue string, reservation model.Reservation) {
+	err := enqueuer.SendDelayedMsg(
+		model.QueueMsgSeatHoldExpired{
+			Type:        model.QueueMsgTypeSeatHoldExpired,
+			FlightID:    reservation.FlightID,
+			SeatID:      reservation.SeatID,
+			PassengerID: reservation.PassengerID,
+		},
+		waitlistQueue,
+		seatHoldDuration,
+	)
+	if err != nil {
+		log.Printf("An error ocurred while sending message to queue %v: %v", waitlistQueue, err)
+	}
+}
+
+// releaseSeats gives the seats back and lets the waitlist offer them to
+// somebody else
+func releaseSeats(flightsRepo FlightsRepository, enqueuer Enqueuer, waitlistQueue string, reservations []model.Reservation) {
+	for _, reservation := range reservations {
+		err := flightsRepo.ReleaseSeat(reservation.FlightID, reservation.SeatID, reservation.PassengerID)
+		if err != nil {
+			log.Printf("An error ocurred while releasing seat %v of flight %v: %v", reservation.SeatID, reservation.FlightID, err)
+			continue
+		}
+
+		err = enqueuer.SendMsg(
+			model.QueueMsgSeatReleased{
+				Type:     model.QueueMsgTypeSeatReleased,
+				FlightID: reservation.FlightID,
+				SeatID:   reservation.SeatID,
+			},
+			waitlistQueue,
+		)
+		if err != nil {
+			log.Printf("An error ocurred while sending message to queue %v: %v", waitlistQueue, err)
+		}
+	}
+}
+
+func getSeat(flight model.Flight, seatID string) model.FlightSeat {
+	for _, s := range flight.Seats {
+		if s.ID == seatID {
+			return s
+		}
+	}
+	return model.FlightSeat{}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	notificationsQueue := os.Getenv("NOTIFICATIONS_QUEUE")
+	if internal.TrimLines(notificationsQueue) == "" {
+		panic("NOTIFICATIONS_QUEUE is empty")
+	}
+	waitlistQueue := os.Getenv("WAITLIST_QUEUE")
+	if internal.TrimLines(waitlistQueue) == "" {
+		panic("WAITLIST_QUEUE is empty")
+	}
+	minConnection, err := time.ParseDuration(os.Getenv("MIN_CONNECTION_TIME"))
+	if err != nil {
+		panic("MIN_CONNECTION_TIME must be a duration, e.g. 45m")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	airports, err := airport.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	payments, err := payment.NewProvider(os.Getenv("PAYMENT_PROVIDER"), os.Getenv("STAGE"))
+	if err != nil {
+		panic(err)
+	}
+	sqsClient := sqs.New(session)
+	enqueuer := internal.NewEnqueuer(sqsClient)
+	lambda.Start(Adapter(flightsRepo, airports, payments, enqueuer, notificationsQueue, waitlistQueue, minConnection))
+}
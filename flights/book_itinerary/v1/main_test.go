@@ -0,0 +1,582 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/itinerary"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/meetupaws/flight_seat_reservation/internal/payment"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) ReserveItinerary(reservations []model.Reservation) ([]model.Reservation, error) {
+	ret := m.Called(reservations)
+	return ret.Get(0).([]model.Reservation), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) HoldSeat(reservation model.Reservation, until time.Time) error {
+	ret := m.Called(reservation, until)
+	return ret.Error(0)
+}
+
+func (m *FlightsRepositoryMock) ReleaseSeat(flightID string, seatID string, passengerID string) error {
+	ret := m.Called(flightID, seatID, passengerID)
+	return ret.Error(0)
+}
+
+type PaymentProviderMock struct {
+	mock.Mock
+}
+
+func (m *PaymentProviderMock) Authorize(req payment.AuthorizationRequest) (payment.Authorization, error) {
+	ret := m.Called(req)
+	return ret.Get(0).(payment.Authorization), ret.Error(1)
+}
+
+func (m *PaymentProviderMock) Capture(authorizationID string) error {
+	ret := m.Called(authorizationID)
+	return ret.Error(0)
+}
+
+func (m *PaymentProviderMock) Refund(authorizationID string) error {
+	ret := m.Called(authorizationID)
+	return ret.Error(0)
+}
+
+type EnqueuerMock struct {
+	mock.Mock
+}
+
+func (m *EnqueuerMock) SendMsg(msg interface{}, queue string) error {
+	ret := m.Called(msg, queue)
+	return ret.Error(0)
+}
+
+func (m *EnqueuerMock) SendDelayedMsg(msg interface{}, queue string, delay time.Duration) error {
+	ret := m.Called(msg, queue, delay)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+		payments    *PaymentProviderMock
+		enqueuer    *EnqueuerMock
+	}
+
+	airports, err := airport.DefaultCatalog()
+	require.NoError(t, err)
+
+	// Arrives in Medellín at 12:20, 100 minutes before the second leg departs
+	toMedellin := model.Flight{
+		ID:           "f1",
+		Departure:    "2020-05-01T11:30:00Z",
+		BlockMinutes: 50,
+		Origin:       "BOG",
+		Destination:  "MDE",
+		Seats: []model.FlightSeat{
+			{ID: "1A", Letter: "A", Row: 1},
+		},
+	}
+	toCartagena := model.Flight{
+		ID:          "f2",
+		Departure:   "2020-05-01T14:00:00Z",
+		Origin:      "MDE",
+		Destination: "CTG",
+		Seats: []model.FlightSeat{
+			{ID: "3C", Letter: "C", Row: 3},
+		},
+	}
+	fares := model.Fares{
+		Currency: "USD",
+		Cabins: map[string]int64{
+			model.CabinEconomy: 10000,
+		},
+	}
+	pricedToMedellin := toMedellin
+	pricedToMedellin.Fares = fares
+	pricedToCartagena := toCartagena
+	pricedToCartagena.Fares = fares
+
+	reservations := []model.Reservation{
+		{FlightID: "f1", SeatID: "1A", PassengerID: "someone@some.com"},
+		{FlightID: "f2", SeatID: "3C", PassengerID: "someone@some.com"},
+	}
+	price := model.Price{Amount: 10000, Currency: "USD"}
+	pricedReservations := []model.Reservation{
+		{FlightID: "f1", SeatID: "1A", PassengerID: "someone@some.com", Price: price},
+		{FlightID: "f2", SeatID: "3C", PassengerID: "someone@some.com", Price: price},
+	}
+	paidReservations := []model.Reservation{
+		{FlightID: "f1", SeatID: "1A", PassengerID: "someone@some.com", Price: price, PaymentID: "auth_1"},
+		{FlightID: "f2", SeatID: "3C", PassengerID: "someone@some.com", Price: price, PaymentID: "auth_1"},
+	}
+	authorization := payment.AuthorizationRequest{
+		Amount:    20000,
+		Currency:  "USD",
+		Reference: "f1/1A+f2/3C/someone@some.com",
+		Token:     "tok_visa",
+	}
+	legs := []model.QueueMsgLegSeat{
+		{
+			FlightID:          "f1",
+			FlightDeparture:   "2020-05-01T11:30:00Z",
+			FlightOrigin:      "BOG",
+			FlightDestination: "MDE",
+			SeatLetter:        "A",
+			SeatRow:           1,
+		},
+		{
+			FlightID:          "f2",
+			FlightDeparture:   "2020-05-01T14:00:00Z",
+			FlightOrigin:      "MDE",
+			FlightDestination: "CTG",
+			SeatLetter:        "C",
+			SeatRow:           3,
+		},
+	}
+	body := `{
+		"passenger_id": "someone@some.com",
+		"legs": [
+			{"flight_id": "f1", "seat_id": "1A"},
+			{"flight_id": "f2", "seat_id": "3C"}
+		]
+	}`
+	pricedBody := `{
+		"passenger_id": "someone@some.com",
+		"payment_token": "tok_visa",
+		"legs": [
+			{"flight_id": "f1", "seat_id": "1A"},
+			{"flight_id": "f2", "seat_id": "3C"}
+		]
+	}`
+	responseBody := `{
+		"locator":"K7QM2X",
+		"passenger_id":"someone@some.com",
+		"legs":[{"flight_id":"f1","seat_id":"1A"},{"flight_id":"f2","seat_id":"3C"}]
+	}`
+
+	type args struct {
+		minConnection time.Duration
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		args   args
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code after reserving a seat on every leg",
+			req: events.APIGatewayProxyRequest{
+				Body: body,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(responseBody),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			args: args{
+				minConnection: 45 * time.Minute,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(toMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(toCartagena, nil).Once()
+				m.flightsRepo.On("ReserveItinerary", reservations).Return(
+					[]model.Reservation{
+						{Locator: "K7QM2X", FlightID: "f1", SeatID: "1A", PassengerID: "someone@some.com"},
+						{Locator: "K7QM2X", FlightID: "f2", SeatID: "3C", PassengerID: "someone@some.com"},
+					},
+					nil,
+				).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgItinerary{
+						Type:    model.QueueMsgTypeItinerary,
+						Locator: "K7QM2X",
+						UserID:  "someone@some.com",
+						Legs:    legs,
+					},
+					"queue",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code after holding, paying and reserving the priced seats",
+			req: events.APIGatewayProxyRequest{
+				Body: pricedBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(responseBody),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			args: args{
+				minConnection: 45 * time.Minute,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(pricedToMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(pricedToCartagena, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[0], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[1], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.payments.On("Authorize", authorization).Return(payment.Authorization{ID: "auth_1"}, nil).Once()
+				m.flightsRepo.On("ReserveItinerary", paidReservations).Return(
+					[]model.Reservation{
+						{Locator: "K7QM2X", FlightID: "f1", SeatID: "1A", PassengerID: "someone@some.com"},
+						{Locator: "K7QM2X", FlightID: "f2", SeatID: "3C", PassengerID: "someone@some.com"},
+					},
+					nil,
+				).Once()
+				m.payments.On("Capture", "auth_1").Return(nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgItinerary{
+						Type:    model.QueueMsgTypeItinerary,
+						Locator: "K7QM2X",
+						UserID:  "someone@some.com",
+						Legs:    legs,
+						Price:   model.Price{Amount: 20000, Currency: "USD"},
+					},
+					"queue",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the connection is shorter than the minimum",
+			req: events.APIGatewayProxyRequest{
+				Body: body,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: fmt.Sprintf(`{"errors":["%s: leg 2"]}`, itinerary.ErrConnectionTooShort),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			args: args{
+				minConnection: 2 * time.Hour,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(toMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(toCartagena, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the second leg does not depart from where the first arrives",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"passenger_id": "someone@some.com",
+					"legs": [
+						{"flight_id": "f2", "seat_id": "3C"},
+						{"flight_id": "f1", "seat_id": "1A"}
+					]
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: fmt.Sprintf(`{"errors":["%s: leg 2"]}`, itinerary.ErrInvalidItinerary),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			args: args{
+				minConnection: 45 * time.Minute,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f2").Return(toCartagena, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(toMedellin, nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because a flight was not found",
+			req: events.APIGatewayProxyRequest{
+				Body: body,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(toMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because a leg has no seat_id",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"passenger_id": "someone@some.com",
+					"legs": [
+						{"flight_id": "f1", "seat_id": "1A"},
+						{"flight_id": "f2"}
+					]
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 422 status because a seat was taken and none was reserved",
+			req: events.APIGatewayProxyRequest{
+				Body: body,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrSeatNotAvailable),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			args: args{
+				minConnection: 45 * time.Minute,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(toMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(toCartagena, nil).Once()
+				m.flightsRepo.On("ReserveItinerary", reservations).
+					Return([]model.Reservation{}, repository.ErrSeatNotAvailable).Once()
+			},
+		},
+		{
+			name: "Get a 402 status and release every seat because the payment was declined",
+			req: events.APIGatewayProxyRequest{
+				Body: pricedBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusPaymentRequired,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, payment.ErrPaymentDeclined),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			args: args{
+				minConnection: 45 * time.Minute,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(pricedToMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(pricedToCartagena, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[0], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[1], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.payments.On("Authorize", authorization).Return(payment.Authorization{}, payment.ErrPaymentDeclined).Once()
+				m.flightsRepo.On("ReleaseSeat", "f1", "1A", "someone@some.com").Return(nil).Once()
+				m.flightsRepo.On("ReleaseSeat", "f2", "3C", "someone@some.com").Return(nil).Once()
+				m.enqueuer.On("SendMsg", mock.AnythingOfType("model.QueueMsgSeatReleased"), "waitlist").Return(nil).Twice()
+			},
+		},
+		{
+			name: "Get a 422 status and release the first seat because the second is held by somebody else",
+			req: events.APIGatewayProxyRequest{
+				Body: pricedBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrSeatNotAvailable),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			args: args{
+				minConnection: 45 * time.Minute,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(pricedToMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(pricedToCartagena, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[0], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[1], mock.AnythingOfType("time.Time")).
+					Return(repository.ErrSeatNotAvailable).Once()
+				m.flightsRepo.On("ReleaseSeat", "f1", "1A", "someone@some.com").Return(nil).Once()
+				m.enqueuer.On("SendMsg", mock.AnythingOfType("model.QueueMsgSeatReleased"), "waitlist").Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 502 status, refund the payment and release every seat because the payment could not be captured",
+			req: events.APIGatewayProxyRequest{
+				Body: pricedBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadGateway,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["provider_down"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			args: args{
+				minConnection: 45 * time.Minute,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(pricedToMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(pricedToCartagena, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[0], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[1], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.payments.On("Authorize", authorization).Return(payment.Authorization{ID: "auth_1"}, nil).Once()
+				m.payments.On("Capture", "auth_1").Return(errors.New("provider_down")).Once()
+				m.payments.On("Refund", "auth_1").Return(nil).Once()
+				m.flightsRepo.On("ReleaseSeat", "f1", "1A", "someone@some.com").Return(nil).Once()
+				m.flightsRepo.On("ReleaseSeat", "f2", "3C", "someone@some.com").Return(nil).Once()
+				m.enqueuer.On("SendMsg", mock.AnythingOfType("model.QueueMsgSeatReleased"), "waitlist").Return(nil).Twice()
+			},
+		},
+		{
+			name: "Get a 500 status and refund the payment because the seats could not be confirmed",
+			req: events.APIGatewayProxyRequest{
+				Body: pricedBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["some error"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			args: args{
+				minConnection: 45 * time.Minute,
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(pricedToMedellin, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(pricedToCartagena, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[0], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservations[1], mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", mock.AnythingOfType("model.QueueMsgSeatHoldExpired"), "waitlist", seatHoldDuration).Return(nil).Once()
+				m.payments.On("Authorize", authorization).Return(payment.Authorization{ID: "auth_1"}, nil).Once()
+				m.payments.On("Capture", "auth_1").Return(nil).Once()
+				m.flightsRepo.On("ReserveItinerary", paidReservations).
+					Return([]model.Reservation{}, errors.New("some error")).Once()
+				m.payments.On("Refund", "auth_1").Return(nil).Once()
+				m.flightsRepo.On("ReleaseSeat", "f1", "1A", "someone@some.com").Return(nil).Once()
+				m.flightsRepo.On("ReleaseSeat", "f2", "3C", "someone@some.com").Return(nil).Once()
+				m.enqueuer.On("SendMsg", mock.AnythingOfType("model.QueueMsgSeatReleased"), "waitlist").Return(nil).Twice()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			if tt.mocks.payments == nil {
+				tt.mocks.payments = &PaymentProviderMock{}
+			}
+			if tt.mocks.enqueuer == nil {
+				tt.mocks.enqueuer = &EnqueuerMock{}
+			}
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(
+				tt.mocks.flightsRepo,
+				airports,
+				tt.mocks.payments,
+				tt.mocks.enqueuer,
+				"queue",
+				"waitlist",
+				tt.args.minConnection,
+			)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+			tt.mocks.payments.AssertExpectations(t)
+			tt.mocks.enqueuer.AssertExpectations(t)
+		})
+	}
+
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/checkin"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/policy"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/pricing"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+var (
+	ErrSeatCostsMore        = errors.New("seat_costs_more_than_paid")
+	ErrSeatCurrencyMismatch = errors.New("seat_paid_in_another_currency")
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	ChangeSeat(flightID string, fromSeatID string, toSeatID string, passengerID string) (model.Reservation, error)
+}
+
+type Enqueuer interface {
+	SendMsg(msg interface{}, queue string) error
+}
+
+type Request struct {
+	FlightID         string `json:"flight_id"`
+	FromSeatID       string `json:"from_seat_id"`
+	ToSeatID         string `json:"to_seat_id"`
+	PassengerID      string `json:"passenger_id"`
+	ExitRowConfirmed bool   `json:"exit_row_confirmed"`
+}
+
+type Response struct {
+	Locator     string `json:"locator"`
+	FlightID    string `json:"flight_id"`
+	SeatID      string `json:"seat_id"`
+	PassengerID string `json:"passenger_id"`
+}
+
+func Adapter(
+	flightsRepo FlightsRepository,
+	enqueuer Enqueuer,
+	notificationsQueue string,
+	waitlistQueue string,
+	window checkin.Window,
+	now func() time.Time,
+) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		request := Request{}
+		err := json.Unmarshal([]byte(req.Body), &request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Validations
+		if internal.TrimLines(request.FlightID) == "" ||
+			internal.TrimLines(request.FromSeatID) == "" ||
+			internal.TrimLines(request.ToSeatID) == "" ||
+			internal.TrimLines(request.PassengerID) == "" {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+
+		// Find the flight and both seats
+		flight, err := flightsRepo.Find(request.FlightID)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Seats can not be changed once check-in closes
+		departure, err := model.ParseDeparture(flight.Departure)
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+		if window.IsClosed(departure, now()) {
+			return internal.Error(http.StatusUnprocessableEntity, checkin.ErrCheckInClosed), nil
+		}
+
+		from := getSeat(flight, request.FromSeatID)
+		to := getSeat(flight, request.ToSeatID)
+		if from.ID == "" || to.ID == "" {
+			return internal.Error(http.StatusNotFound, repository.ErrNoSeatFoundInFlight), nil
+		}
+		if from.PassengerID != request.PassengerID {
+			return internal.Error(http.StatusUnprocessableEntity, repository.ErrPassengerNotInSeat), nil
+		}
+
+		// Check the passenger is allowed to take the new seat and already paid
+		// for it
+		err = policy.CheckSeat(to, policy.Passenger{
+			Cabin:            policy.EntitledCabin(flight.Fares, from),
+			ExitRowConfirmed: request.ExitRowConfirmed,
+			SpecialRequests:  from.SpecialRequests,
+		})
+		if err != nil {
+			return internal.Error(http.StatusUnprocessableEntity, err), nil
+		}
+
+		quote, err := pricing.QuoteSeat(flight.Fares, to)
+		if err != nil {
+			return internal.Error(http.StatusUnprocessableEntity, err), nil
+		}
+		// Seats reserved before the flight had fares have no price, they can
+		// move to seats costing no more than theirs costs now
+		paid := from.Price
+		if paid.Amount == 0 {
+			fromQuote, err := pricing.QuoteSeat(flight.Fares, from)
+			if err != nil {
+				return internal.Error(http.StatusUnprocessableEntity, err), nil
+			}
+			paid = model.Price{Amount: fromQuote.Total, Currency: fromQuote.Currency}
+		}
+		// Amounts in different currencies can not be compared
+		if quote.Total > 0 && paid.Currency != quote.Currency {
+			return internal.Error(http.StatusUnprocessableEntity, ErrSeatCurrencyMismatch), nil
+		}
+		if quote.Total > paid.Amount {
+			return internal.Error(http.StatusUnprocessableEntity, ErrSeatCostsMore), nil
+		}
+
+		// Change seat
+		reservation, err := flightsRepo.ChangeSeat(flight.ID, from.ID, to.ID, request.PassengerID)
+		if err == repository.ErrNoSeatFoundInFlight {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err == repository.ErrSeatNotAvailable || err == repository.ErrPassengerNotInSeat || err == repository.ErrFlightClosed {
+			return internal.Error(http.StatusUnprocessableEntity, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Send message to queue
+		err = enqueuer.SendMsg(
+			model.QueueMsgSeatChanged{
+				Type:              model.QueueMsgTypeSeatChanged,
+				Locator:           reservation.Locator,
+				FlightID:          flight.ID,
+				FlightDeparture:   flight.Departure,
+				FlightOrigin:      flight.Origin,
+				FlightDestination: flight.Destination,
+				FromSeatID:        from.ID,
+				ToSeatLetter:      to.Letter,
+				ToSeatRow:         to.Row,
+				UserID:            request.PassengerID,
+			},
+			notificationsQueue,
+		)
+		if err != nil {
+			log.Printf("An error ocurred while sending message to queue %v: %v", notificationsQueue, err)
+		}
+
+		// The old seat can go to the waitlist
+		err = enqueuer.SendMsg(
+			model.QueueMsgSeatReleased{
+				Type:     model.QueueMsgTypeSeatReleased,
+				FlightID: flight.ID,
+				SeatID:   from.ID,
+			},
+			waitlistQueue,
+		)
+		if err != nil {
+			log.Printf("An error ocurred while sending message to queue %v: %v", waitlistQueue, err)
+		}
+
+		// Respond
+		responseBytes, _ := json.Marshal(Response{
+			Locator:     reservation.Locator,
+			FlightID:    reservation.FlightID,
+			SeatID:      reservation.SeatID,
+			PassengerID: reservation.PassengerID,
+		})
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func getSeat(flight model.Flight, seatID string) model.FlightSeat {
+	for _, s := range flight.Seats {
+		if s.ID == seatID {
+			return s
+		}
+	}
+	return model.FlightSeat{}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	notificationsQueue := os.Getenv("NOTIFICATIONS_QUEUE")
+	if internal.TrimLines(notificationsQueue) == "" {
+		panic("NOTIFICATIONS_QUEUE is empty")
+	}
+	waitlistQueue := os.Getenv("WAITLIST_QUEUE")
+	if internal.TrimLines(waitlistQueue) == "" {
+		panic("WAITLIST_QUEUE is empty")
+	}
+	window, err := checkin.ParseWindow(os.Getenv("CHECK_IN_OPENS"), os.Getenv("CHECK_IN_CLOSES"))
+	if err != nil {
+		panic(err)
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	sqsClient := sqs.New(session)
+	enqueuer := internal.NewEnqueuer(sqsClient)
+	lambda.Start(Adapter(flightsRepo, enqueuer, notificationsQueue, waitlistQueue, window, time.Now))
+}
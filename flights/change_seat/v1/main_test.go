@@ -0,0 +1,497 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/checkin"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/policy"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) ChangeSeat(flightID string, fromSeatID string, toSeatID string, passengerID string) (model.Reservation, error) {
+	ret := m.Called(flightID, fromSeatID, toSeatID, passengerID)
+	return ret.Get(0).(model.Reservation), ret.Error(1)
+}
+
+type EnqueuerMock struct {
+	mock.Mock
+}
+
+func (m *EnqueuerMock) SendMsg(msg interface{}, queue string) error {
+	ret := m.Called(msg, queue)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+		enqueuer    *EnqueuerMock
+	}
+
+	flight := model.Flight{
+		ID:        "f1",
+		Departure: "2020-05-01T00:00:00+0000",
+		Fares: model.Fares{
+			Currency: "USD",
+			Cabins: map[string]int64{
+				model.CabinEconomy: 10000,
+			},
+			Surcharges: map[string]int64{
+				model.SeatPositionAisle: 1000,
+				model.SurchargeExitRow:  2000,
+			},
+		},
+		Seats: []model.FlightSeat{
+			{
+				ID:          "23B",
+				Letter:      "B",
+				Row:         23,
+				Position:    model.SeatPositionMiddle,
+				Cabin:       model.CabinEconomy,
+				PassengerID: "someone@some.com",
+				Price: model.Price{
+					Amount:   11000,
+					Currency: "USD",
+				},
+				Locator: "K7QM2X",
+			},
+			{
+				ID:       "23C",
+				Letter:   "C",
+				Row:      23,
+				Position: model.SeatPositionAisle,
+				Cabin:    model.CabinEconomy,
+			},
+			{
+				ID:       "12C",
+				Letter:   "C",
+				Row:      12,
+				Position: model.SeatPositionAisle,
+				Cabin:    model.CabinEconomy,
+				ExitRow:  true,
+			},
+		},
+	}
+
+	// paidWith is the flight with the given price paid for seat 23B
+	paidWith := func(price model.Price) model.Flight {
+		f := flight
+		f.Seats = append([]model.FlightSeat{}, flight.Seats...)
+		f.Seats[0].Price = price
+		return f
+	}
+
+	now := time.Date(2020, 4, 30, 12, 0, 0, 0, time.UTC)
+	window := checkin.Window{
+		Opens:  24 * time.Hour,
+		Closes: time.Hour,
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code after moving the passenger to an aisle seat",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "23C",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"locator":"K7QM2X",
+					"flight_id":"f1",
+					"seat_id":"23C",
+					"passenger_id":"someone@some.com"
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				m.flightsRepo.On("ChangeSeat", "f1", "23B", "23C", "someone@some.com").Return(
+					model.Reservation{
+						Locator:     "K7QM2X",
+						FlightID:    "f1",
+						SeatID:      "23C",
+						PassengerID: "someone@some.com",
+					},
+					nil,
+				).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgSeatChanged{
+						Type:            model.QueueMsgTypeSeatChanged,
+						Locator:         "K7QM2X",
+						FlightID:        "f1",
+						FlightDeparture: "2020-05-01T00:00:00+0000",
+						FromSeatID:      "23B",
+						ToSeatLetter:    "C",
+						ToSeatRow:       23,
+						UserID:          "someone@some.com",
+					},
+					"queue",
+				).Return(nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgSeatReleased{
+						Type:     model.QueueMsgTypeSeatReleased,
+						FlightID: "f1",
+						SeatID:   "23B",
+					},
+					"waitlist",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because check-in already closed",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "23C",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, checkin.ErrCheckInClosed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				departing := flight
+				departing.Departure = "2020-04-30T12:30:00+0000"
+				m.flightsRepo.On("Find", "f1").Return(departing, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the passenger is not in the seat",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "23C",
+						"passenger_id": "other@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrPassengerNotInSeat),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the new seat is in a cabin the passenger did not book",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "2A",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, policy.ErrCabinNotAllowed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				withBusiness := flight
+				withBusiness.Seats = append([]model.FlightSeat{}, flight.Seats...)
+				withBusiness.Seats = append(withBusiness.Seats, model.FlightSeat{ID: "2A", Letter: "A", Row: 2, Cabin: model.CabinBusiness})
+				m.flightsRepo.On("Find", "f1").Return(withBusiness, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the exit row was not confirmed",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "12C",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, policy.ErrExitRowNotConfirmed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the new seat costs more than what was paid",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "12C",
+						"passenger_id": "someone@some.com",
+						"exit_row_confirmed": true
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, ErrSeatCostsMore),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the seat was paid in another currency",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "23C",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, ErrSeatCurrencyMismatch),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(paidWith(model.Price{Amount: 11000, Currency: "EUR"}), nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the new seat costs more than the seat reserved before fares",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "23C",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, ErrSeatCostsMore),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(paidWith(model.Price{}), nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the new seat was taken meanwhile",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "23C",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrSeatNotAvailable),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				m.flightsRepo.On("ChangeSeat", "f1", "23B", "23C", "someone@some.com").Return(model.Reservation{}, repository.ErrSeatNotAvailable).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because the new seat is not in the flight",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "99Z",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoSeatFoundInFlight),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because of missing fields",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 500 status because the flight could not be found",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"from_seat_id": "23B",
+						"to_seat_id": "23C",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(model.Flight{}, errors.New("unexpected")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo, tt.mocks.enqueuer, "queue", "waitlist", window, func() time.Time { return now })
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+			tt.mocks.enqueuer.AssertExpectations(t)
+		})
+	}
+
+}
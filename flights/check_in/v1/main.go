@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/boardingpass"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/checkin"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	CheckIn(flightID string, seatID string, passengerID string, at time.Time) (int, error)
+}
+
+type ReservationsRepository interface {
+	FindByLocator(locator string) ([]model.Reservation, error)
+}
+
+type Enqueuer interface {
+	SendMsg(msg interface{}, queue string) error
+}
+
+type Request struct {
+	Locator       string `json:"locator"`
+	PassengerID   string `json:"passenger_id"`
+	PassengerName string `json:"passenger_name"`
+}
+
+type Response struct {
+	Locator     string `json:"locator"`
+	FlightID    string `json:"flight_id"`
+	SeatID      string `json:"seat_id"`
+	PassengerID string `json:"passenger_id"`
+	CheckedInAt string `json:"checked_in_at"`
+}
+
+// leg is a reservation of the locator along with its flight
+type leg struct {
+	reservation model.Reservation
+	flight      model.Flight
+	departure   time.Time
+}
+
+func Adapter(
+	flightsRepo FlightsRepository,
+	reservationsRepo ReservationsRepository,
+	enqueuer Enqueuer,
+	notificationsQueue string,
+	carrier string,
+	window checkin.Window,
+	now func() time.Time,
+) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		request := Request{}
+		err := json.Unmarshal([]byte(req.Body), &request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Validations
+		if internal.TrimLines(request.Locator) == "" ||
+			internal.TrimLines(request.PassengerID) == "" {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+
+		// Find the reservations of the passenger, one per flight
+		found, err := reservationsRepo.FindByLocator(request.Locator)
+		if err != nil && err != repository.ErrNoReservationsFound {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+		reservations := []model.Reservation{}
+		for _, r := range found {
+			if r.PassengerID == request.PassengerID {
+				reservations = append(reservations, r)
+			}
+		}
+		if len(reservations) == 0 {
+			return internal.Error(http.StatusNotFound, repository.ErrNoReservationsFound), nil
+		}
+
+		// Find the flights of the reservations and go through them in the order
+		// they depart, the departure of the flight is the one that counts as it
+		// could have been delayed
+		legs := make([]leg, len(reservations))
+		for i, reservation := range reservations {
+			flight, err := flightsRepo.Find(reservation.FlightID)
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+			departure, err := model.ParseDeparture(flight.Departure)
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+			legs[i] = leg{reservation: reservation, flight: flight, departure: departure}
+		}
+		sort.SliceStable(legs, func(i, j int) bool {
+			return legs[i].departure.Before(legs[j].departure)
+		})
+
+		// Check in the first flight whose check-in is open, skipping the ones
+		// the passenger already checked in
+		checkedInAt := now()
+		var windowErr error
+		for _, l := range legs {
+			reservation, flight, departure := l.reservation, l.flight, l.departure
+			if findSeat(flight, reservation.SeatID).CheckedInAt != "" {
+				continue
+			}
+			err = window.Check(departure, checkedInAt)
+			if err != nil {
+				if windowErr == nil {
+					windowErr = err
+				}
+				continue
+			}
+
+			sequence, err := flightsRepo.CheckIn(reservation.FlightID, reservation.SeatID, reservation.PassengerID, checkedInAt)
+			if err == repository.ErrAlreadyCheckedIn {
+				continue
+			}
+			if err == repository.ErrFlightClosed || err == repository.ErrPassengerNotInSeat {
+				return internal.Error(http.StatusUnprocessableEntity, err), nil
+			}
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+
+			// Send the boarding pass, the passenger is checked in even if it fails.
+			// Flights without a flight number are the ones of the configured carrier
+			passCarrier := carrier
+			if flight.Carrier != "" {
+				passCarrier = flight.Carrier
+			}
+			bcbp, err := boardingpass.Encode(boardingpass.Pass{
+				PassengerName: passengerName(request),
+				Locator:       reservation.Locator,
+				Origin:        flight.Origin,
+				Destination:   flight.Destination,
+				Carrier:       passCarrier,
+				FlightNumber:  flight.FlightNumber,
+				Departure:     departure.In(flight.Location()),
+				Seat:          findSeat(flight, reservation.SeatID),
+				Sequence:      sequence,
+			})
+			if err != nil {
+				log.Printf("Could not encode the boarding pass of %v: %v", reservation.Locator, err)
+			} else {
+				err = enqueuer.SendMsg(
+					model.QueueMsgBoardingPass{
+						Type:              model.QueueMsgTypeBoardingPass,
+						Locator:           reservation.Locator,
+						FlightID:          flight.ID,
+						FlightDeparture:   flight.Departure,
+						FlightOrigin:      flight.Origin,
+						FlightDestination: flight.Destination,
+						SeatID:            reservation.SeatID,
+						UserID:            reservation.PassengerID,
+						BCBP:              bcbp,
+					},
+					notificationsQueue,
+				)
+				if err != nil {
+					log.Printf("An error ocurred while sending message to queue %v: %v", notificationsQueue, err)
+				}
+			}
+
+			responseBytes, _ := json.Marshal(Response{
+				Locator:     reservation.Locator,
+				FlightID:    reservation.FlightID,
+				SeatID:      reservation.SeatID,
+				PassengerID: reservation.PassengerID,
+				CheckedInAt: checkedInAt.UTC().Format(time.RFC3339),
+			})
+			return internal.Respond(http.StatusOK, string(responseBytes)), nil
+		}
+
+		if windowErr != nil {
+			return internal.Error(http.StatusUnprocessableEntity, windowErr), nil
+		}
+		return internal.Error(http.StatusConflict, repository.ErrAlreadyCheckedIn), nil
+	}
+}
+
+// passengerName is the name given at check-in, passengers that do not give one
+// are named after their email
+func passengerName(request Request) string {
+	if internal.TrimLines(request.PassengerName) != "" {
+		return request.PassengerName
+	}
+	return strings.Split(request.PassengerID, "@")[0]
+}
+
+func findSeat(flight model.Flight, seatID string) model.FlightSeat {
+	for _, s := range flight.Seats {
+		if s.ID == seatID {
+			return s
+		}
+	}
+	return model.FlightSeat{}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	notificationsQueue := os.Getenv("NOTIFICATIONS_QUEUE")
+	if internal.TrimLines(notificationsQueue) == "" {
+		panic("NOTIFICATIONS_QUEUE is empty")
+	}
+	carrier := os.Getenv("CARRIER")
+	if internal.TrimLines(carrier) == "" {
+		panic("CARRIER is empty")
+	}
+	window, err := checkin.ParseWindow(os.Getenv("CHECK_IN_OPENS"), os.Getenv("CHECK_IN_CLOSES"))
+	if err != nil {
+		panic(err)
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	reservationsRepo := repository.NewReservationsRepository(dynamodbClient, reservationsTable)
+	sqsClient := sqs.New(session)
+	enqueuer := internal.NewEnqueuer(sqsClient)
+	lambda.Start(Adapter(flightsRepo, reservationsRepo, enqueuer, notificationsQueue, carrier, window, time.Now))
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/checkin"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) CheckIn(flightID string, seatID string, passengerID string, at time.Time) (int, error) {
+	ret := m.Called(flightID, seatID, passengerID, at)
+	return ret.Int(0), ret.Error(1)
+}
+
+type ReservationsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ReservationsRepositoryMock) FindByLocator(locator string) ([]model.Reservation, error) {
+	ret := m.Called(locator)
+	return ret.Get(0).([]model.Reservation), ret.Error(1)
+}
+
+type EnqueuerMock struct {
+	mock.Mock
+}
+
+func (m *EnqueuerMock) SendMsg(msg interface{}, queue string) error {
+	ret := m.Called(msg, queue)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo      *FlightsRepositoryMock
+		reservationsRepo *ReservationsRepositoryMock
+		enqueuer         *EnqueuerMock
+	}
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	window := checkin.Window{
+		Opens:  24 * time.Hour,
+		Closes: time.Hour,
+	}
+	reservation := model.Reservation{
+		Locator:     "K7QM2X",
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "someone@some.com",
+		Departure:   "2020-05-02T00:00:00+0000",
+	}
+	flight := model.Flight{
+		ID:          "f1",
+		Departure:   "2020-05-02T00:00:00+0000",
+		Origin:      "BOG",
+		Destination: "MDE",
+		Seats: []model.FlightSeat{
+			{ID: "1A", Letter: "A", Row: 1, Cabin: model.CabinEconomy, PassengerID: "someone@some.com", Locator: "K7QM2X"},
+			{ID: "1B", Letter: "B", Row: 1, Cabin: model.CabinEconomy, PassengerID: "other@some.com", CheckedInAt: "2020-05-01T10:00:00Z"},
+		},
+	}
+	connection := model.Reservation{
+		Locator:     "K7QM2X",
+		FlightID:    "f2",
+		SeatID:      "3C",
+		PassengerID: "someone@some.com",
+		Departure:   "2020-05-02T03:00:00+0000",
+	}
+	connectingFlight := model.Flight{
+		ID:          "f2",
+		Departure:   "2020-05-02T03:00:00+0000",
+		Origin:      "MDE",
+		Destination: "CTG",
+		Seats: []model.FlightSeat{
+			{ID: "3C", Letter: "C", Row: 3, Cabin: model.CabinEconomy, PassengerID: "someone@some.com", Locator: "K7QM2X"},
+		},
+	}
+	validBody := `{"locator": "K7QM2X", "passenger_id": "someone@some.com"}`
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code after checking in and send the boarding pass",
+			req: events.APIGatewayProxyRequest{
+				Body: `{"locator": "K7QM2X", "passenger_id": "someone@some.com", "passenger_name": "Doe/John"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"locator":"K7QM2X",
+					"flight_id":"f1",
+					"seat_id":"1A",
+					"passenger_id":"someone@some.com",
+					"checked_in_at":"2020-05-01T12:00:00Z"
+				}`),
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				m.flightsRepo.On("CheckIn", "f1", "1A", "someone@some.com", now).Return(2, nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgBoardingPass{
+						Type:              model.QueueMsgTypeBoardingPass,
+						Locator:           "K7QM2X",
+						FlightID:          "f1",
+						FlightDeparture:   "2020-05-02T00:00:00+0000",
+						FlightOrigin:      "BOG",
+						FlightDestination: "MDE",
+						SeatID:            "1A",
+						UserID:            "someone@some.com",
+						BCBP:              "M1DOE/JOHN            EK7QM2X BOGMDEAV      123Y001A0002 100",
+					},
+					"queue",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code after checking in and send the boarding pass with the flight number on the local date",
+			req: events.APIGatewayProxyRequest{
+				Body: `{"locator": "K7QM2X", "passenger_id": "someone@some.com", "passenger_name": "Doe/John"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"locator":"K7QM2X",
+					"flight_id":"f1",
+					"seat_id":"1A",
+					"passenger_id":"someone@some.com",
+					"checked_in_at":"2020-05-01T12:00:00Z"
+				}`),
+			},
+			mocker: func(m mocks) {
+				withFlightNumber := flight
+				withFlightNumber.Carrier = "LA"
+				withFlightNumber.FlightNumber = "45"
+				withFlightNumber.OriginTimeZone = "America/Bogota"
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(withFlightNumber, nil).Once()
+				m.flightsRepo.On("CheckIn", "f1", "1A", "someone@some.com", now).Return(2, nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgBoardingPass{
+						Type:              model.QueueMsgTypeBoardingPass,
+						Locator:           "K7QM2X",
+						FlightID:          "f1",
+						FlightDeparture:   "2020-05-02T00:00:00+0000",
+						FlightOrigin:      "BOG",
+						FlightDestination: "MDE",
+						SeatID:            "1A",
+						UserID:            "someone@some.com",
+						BCBP:              "M1DOE/JOHN            EK7QM2X BOGMDELA 0045 122Y001A0002 100",
+					},
+					"queue",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code after checking in without a boarding pass for flights without route",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"locator":"K7QM2X",
+					"flight_id":"f1",
+					"seat_id":"1A",
+					"passenger_id":"someone@some.com",
+					"checked_in_at":"2020-05-01T12:00:00Z"
+				}`),
+			},
+			mocker: func(m mocks) {
+				withoutRoute := flight
+				withoutRoute.Origin = ""
+				withoutRoute.Destination = ""
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(withoutRoute, nil).Once()
+				m.flightsRepo.On("CheckIn", "f1", "1A", "someone@some.com", now).Return(1, nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code after checking in the first leg of a trip",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"locator":"K7QM2X",
+					"flight_id":"f1",
+					"seat_id":"1A",
+					"passenger_id":"someone@some.com",
+					"checked_in_at":"2020-05-01T12:00:00Z"
+				}`),
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{connection, reservation}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(connectingFlight, nil).Once()
+				m.flightsRepo.On("CheckIn", "f1", "1A", "someone@some.com", now).Return(1, nil).Once()
+				m.enqueuer.On("SendMsg", mock.AnythingOfType("model.QueueMsgBoardingPass"), "queue").Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code after checking in the second leg of a trip whose first leg is checked in",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"locator":"K7QM2X",
+					"flight_id":"f2",
+					"seat_id":"3C",
+					"passenger_id":"someone@some.com",
+					"checked_in_at":"2020-05-01T12:00:00Z"
+				}`),
+			},
+			mocker: func(m mocks) {
+				checkedIn := flight
+				checkedIn.Seats = []model.FlightSeat{
+					{ID: "1A", Letter: "A", Row: 1, Cabin: model.CabinEconomy, PassengerID: "someone@some.com", Locator: "K7QM2X", CheckedInAt: "2020-05-01T10:00:00Z"},
+				}
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation, connection}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(checkedIn, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(connectingFlight, nil).Once()
+				m.flightsRepo.On("CheckIn", "f2", "3C", "someone@some.com", now).Return(1, nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgBoardingPass{
+						Type:              model.QueueMsgTypeBoardingPass,
+						Locator:           "K7QM2X",
+						FlightID:          "f2",
+						FlightDeparture:   "2020-05-02T03:00:00+0000",
+						FlightOrigin:      "MDE",
+						FlightDestination: "CTG",
+						SeatID:            "3C",
+						UserID:            "someone@some.com",
+						BCBP:              "M1SOMEONE             EK7QM2X MDECTGAV      123Y003C0001 100",
+					},
+					"queue",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because the passenger already checked in every leg of the trip",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrAlreadyCheckedIn),
+				),
+			},
+			mocker: func(m mocks) {
+				checkedIn := flight
+				checkedIn.Seats = []model.FlightSeat{
+					{ID: "1A", Letter: "A", Row: 1, Cabin: model.CabinEconomy, PassengerID: "someone@some.com", Locator: "K7QM2X", CheckedInAt: "2020-05-01T10:00:00Z"},
+				}
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation, connection}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(checkedIn, nil).Once()
+				m.flightsRepo.On("Find", "f2").Return(connectingFlight, nil).Once()
+				m.flightsRepo.On("CheckIn", "f2", "3C", "someone@some.com", now).Return(0, repository.ErrAlreadyCheckedIn).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because check-in is not open yet for the delayed flight",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, checkin.ErrCheckInNotOpen),
+				),
+			},
+			mocker: func(m mocks) {
+				delayed := flight
+				delayed.Departure = "2020-05-02T18:00:00+0000"
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(delayed, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because check-in is closed",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, checkin.ErrCheckInClosed),
+				),
+			},
+			mocker: func(m mocks) {
+				departing := flight
+				departing.Departure = "2020-05-01T12:30:00+0000"
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(departing, nil).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because the passenger already checked in",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrAlreadyCheckedIn),
+				),
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation}, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				m.flightsRepo.On("CheckIn", "f1", "1A", "someone@some.com", now).Return(0, repository.ErrAlreadyCheckedIn).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because the locator belongs to another passenger",
+			req: events.APIGatewayProxyRequest{
+				Body: `{"locator": "K7QM2X", "passenger_id": "other@some.com"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoReservationsFound),
+				),
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{reservation}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because the locator does not exist",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoReservationsFound),
+				),
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{}, repository.ErrNoReservationsFound).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because of missing fields",
+			req: events.APIGatewayProxyRequest{
+				Body: `{"locator": "K7QM2X"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 500 status because the reservations could not be found",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("FindByLocator", "K7QM2X").Return([]model.Reservation{}, errors.New("unexpected")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m := mocks{
+				flightsRepo:      &FlightsRepositoryMock{},
+				reservationsRepo: &ReservationsRepositoryMock{},
+				enqueuer:         &EnqueuerMock{},
+			}
+			tt.mocker(m)
+
+			// Act
+			handler := Adapter(m.flightsRepo, m.reservationsRepo, m.enqueuer, "queue", "AV", window, func() time.Time { return now })
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			m.flightsRepo.AssertExpectations(t)
+			m.reservationsRepo.AssertExpectations(t)
+			m.enqueuer.AssertExpectations(t)
+		})
+	}
+
+}
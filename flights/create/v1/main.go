@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/pricing"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Create(m model.Flight) (model.Flight, error)
+}
+
+type AircraftCatalog interface {
+	Find(aircraftType string) (aircraft.Configuration, error)
+}
+
+type AirportCatalog interface {
+	Find(code string) (airport.Airport, error)
+}
+
+type Request struct {
+	ID               string                  `json:"id"`
+	Carrier          string                  `json:"carrier"`
+	FlightNumber     string                  `json:"flight_number"`
+	MarketingFlights []model.MarketingFlight `json:"marketing_flights"`
+	Departure        string                  `json:"departure"`
+	BlockMinutes     int                     `json:"block_minutes"`
+	Origin           string                  `json:"origin"`
+	Destination      string                  `json:"destination"`
+	OriginTimeZone   string                  `json:"origin_time_zone"`
+	AircraftType     string                  `json:"aircraft_type"`
+	Fares            *model.Fares            `json:"fares"`
+	Seats            []RequestSeat           `json:"seats"`
+}
+
+type RequestSeat struct {
+	ID           string `json:"id"`
+	Letter       string `json:"letter"`
+	Row          int    `json:"row"`
+	Position     string `json:"position"`
+	Cabin        string `json:"cabin"`
+	ExitRow      bool   `json:"exit_row"`
+	ExtraLegroom bool   `json:"extra_legroom"`
+	// AisleAfter tells an aisle separates the seat from the next one of its
+	// row, seats from an aircraft type of the catalog know it already
+	AisleAfter bool `json:"aisle_after"`
+}
+
+type Response struct {
+	ID               string                  `json:"id"`
+	Carrier          string                  `json:"carrier"`
+	FlightNumber     string                  `json:"flight_number"`
+	OperatingDate    string                  `json:"operating_date"`
+	MarketingFlights []model.MarketingFlight `json:"marketing_flights"`
+	Departure        string                  `json:"departure"`
+	BlockMinutes     int                     `json:"block_minutes"`
+	Origin           string                  `json:"origin"`
+	Destination      string                  `json:"destination"`
+	OriginTimeZone   string                  `json:"origin_time_zone"`
+	AircraftType     string                  `json:"aircraft_type"`
+	HasFreeSeats     bool                    `json:"has_free_seats"`
+	Seats            []ResponseSeat          `json:"seats"`
+}
+
+type ResponseSeat struct {
+	ID           string `json:"id"`
+	Letter       string `json:"letter"`
+	Row          int    `json:"row"`
+	Position     string `json:"position"`
+	Cabin        string `json:"cabin"`
+	ExitRow      bool   `json:"exit_row"`
+	ExtraLegroom bool   `json:"extra_legroom"`
+}
+
+func Adapter(flightsRepo FlightsRepository, catalog AircraftCatalog, airports AirportCatalog) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		request := Request{}
+		err := json.Unmarshal([]byte(req.Body), &request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Validations
+		if internal.TrimLines(request.ID) == "" ||
+			internal.TrimLines(request.Departure) == "" ||
+			(len(request.Seats) == 0 && internal.TrimLines(request.AircraftType) == "") {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+		departure, err := model.NormalizeDeparture(request.Departure)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid departure")), nil
+		}
+		if request.BlockMinutes < 0 {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid block_minutes")), nil
+		}
+		if request.OriginTimeZone != "" && !model.IsTimeZone(request.OriginTimeZone) {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid origin_time_zone")), nil
+		}
+		if len(request.Seats) > 0 && request.AircraftType != "" {
+			return internal.Error(http.StatusBadRequest, errors.New("seats and aircraft_type are exclusive")), nil
+		}
+		if (request.Origin != "" || request.Destination != "") &&
+			(request.Origin == "" || request.Destination == "" || request.Origin == request.Destination) {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid route")), nil
+		}
+		carrier, flightNumber, marketingFlights, err := flightNumbers(request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+		originTimeZone := request.OriginTimeZone
+		if request.Origin != "" {
+			origin, err := airports.Find(request.Origin)
+			if err == nil {
+				_, err = airports.Find(request.Destination)
+			}
+			if err == airport.ErrUnknownAirport {
+				return internal.Error(http.StatusBadRequest, err), nil
+			}
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+			if originTimeZone == "" {
+				originTimeZone = origin.TimeZone
+			}
+		}
+		if originTimeZone == "" {
+			return internal.Error(http.StatusBadRequest, errors.New("missing origin_time_zone")), nil
+		}
+		for _, s := range request.Seats {
+			if internal.TrimLines(s.ID) == "" ||
+				internal.TrimLines(s.Letter) == "" ||
+				s.Row <= 0 ||
+				!validPosition(s.Position) ||
+				!validCabin(s.Cabin) {
+				return internal.Error(http.StatusBadRequest, errors.New("invalid seat")), nil
+			}
+		}
+
+		if request.Fares != nil {
+			err = pricing.ValidateFares(*request.Fares)
+			if err != nil {
+				return internal.Error(http.StatusBadRequest, err), nil
+			}
+		}
+
+		// Build the flight from the aircraft layout or the given seats
+		flight := model.Flight{
+			ID:               request.ID,
+			Carrier:          carrier,
+			FlightNumber:     flightNumber,
+			MarketingFlights: marketingFlights,
+			Departure:        departure,
+			BlockMinutes:     request.BlockMinutes,
+			Origin:           request.Origin,
+			Destination:      request.Destination,
+			OriginTimeZone:   originTimeZone,
+			AircraftType:     request.AircraftType,
+			Seats:            make([]model.FlightSeat, len(request.Seats)),
+		}
+		if request.Fares != nil {
+			flight.Fares = *request.Fares
+		}
+		for i, s := range request.Seats {
+			flight.Seats[i] = model.FlightSeat{
+				ID:           s.ID,
+				Letter:       s.Letter,
+				Row:          s.Row,
+				Position:     s.Position,
+				Cabin:        s.Cabin,
+				ExitRow:      s.ExitRow,
+				ExtraLegroom: s.ExtraLegroom,
+				AisleAfter:   s.AisleAfter,
+			}
+		}
+		if request.AircraftType != "" {
+			configuration, err := catalog.Find(request.AircraftType)
+			if err == aircraft.ErrUnknownAircraftType {
+				return internal.Error(http.StatusBadRequest, err), nil
+			}
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+			flight.Seats = configuration.Seats()
+		}
+
+		// Create flight
+		flight, err = flightsRepo.Create(flight)
+		if err == repository.ErrDuplicatedSeatID || err == repository.ErrInvalidTimeZone {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+		if err == repository.ErrFlightAlreadyExists || err == repository.ErrFlightNumberTaken {
+			return internal.Error(http.StatusConflict, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Prepare response
+		response := Response{
+			ID:               flight.ID,
+			Carrier:          flight.Carrier,
+			FlightNumber:     flight.FlightNumber,
+			OperatingDate:    flight.OperatingDate,
+			MarketingFlights: make([]model.MarketingFlight, len(flight.MarketingFlights)),
+			Departure:        flight.Departure,
+			BlockMinutes:     flight.BlockMinutes,
+			Origin:           flight.Origin,
+			Destination:      flight.Destination,
+			OriginTimeZone:   flight.OriginTimeZone,
+			AircraftType:     flight.AircraftType,
+			HasFreeSeats:     flight.HasFreeSeats,
+			Seats:            make([]ResponseSeat, len(flight.Seats)),
+		}
+		copy(response.MarketingFlights, flight.MarketingFlights)
+		for i, s := range flight.Seats {
+			response.Seats[i] = ResponseSeat{
+				ID:           s.ID,
+				Letter:       s.Letter,
+				Row:          s.Row,
+				Position:     s.Position,
+				Cabin:        s.Cabin,
+				ExitRow:      s.ExitRow,
+				ExtraLegroom: s.ExtraLegroom,
+			}
+		}
+
+		// Respond
+		responseBytes, _ := json.Marshal(response)
+		return internal.Respond(http.StatusCreated, string(responseBytes)), nil
+	}
+}
+
+// flightNumbers returns the operating flight number and the codeshares of the
+// request without leading zeros, e.g. AV 0123 is AV 123
+func flightNumbers(request Request) (string, string, []model.MarketingFlight, error) {
+	if request.Carrier == "" && request.FlightNumber == "" {
+		if len(request.MarketingFlights) > 0 {
+			return "", "", nil, errors.New("invalid marketing_flights")
+		}
+		return "", "", nil, nil
+	}
+	carrier, flightNumber, ok := model.ParseDesignator(request.Carrier + request.FlightNumber)
+	if !ok || len(request.Carrier) != 2 {
+		return "", "", nil, errors.New("invalid flight_number")
+	}
+
+	designators := map[string]bool{model.Designator(carrier, flightNumber): true}
+	var marketingFlights []model.MarketingFlight
+	for _, m := range request.MarketingFlights {
+		marketingCarrier, marketingNumber, ok := model.ParseDesignator(m.Carrier + m.FlightNumber)
+		designator := model.Designator(marketingCarrier, marketingNumber)
+		if !ok || len(m.Carrier) != 2 || designators[designator] {
+			return "", "", nil, errors.New("invalid marketing_flights")
+		}
+		designators[designator] = true
+		marketingFlights = append(marketingFlights, model.MarketingFlight{Carrier: marketingCarrier, FlightNumber: marketingNumber})
+	}
+	return carrier, flightNumber, marketingFlights, nil
+}
+
+func validPosition(position string) bool {
+	switch position {
+	case "", model.SeatPositionWindow, model.SeatPositionMiddle, model.SeatPositionAisle:
+		return true
+	}
+	return false
+}
+
+func validCabin(cabin string) bool {
+	switch cabin {
+	case "", model.CabinEconomy, model.CabinPremiumEconomy, model.CabinBusiness, model.CabinFirst:
+		return true
+	}
+	return false
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	catalog, err := aircraft.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	airports, err := airport.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	lambda.Start(Adapter(flightsRepo, catalog, airports))
+}
@@ -0,0 +1,620 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Create(flight model.Flight) (model.Flight, error) {
+	ret := m.Called(flight)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+	}
+
+	flight := model.Flight{
+		ID:             "f1",
+		Departure:      "2020-05-01T00:00:00Z",
+		Origin:         "BOG",
+		Destination:    "MDE",
+		OriginTimeZone: "America/Bogota",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+			{
+				ID:     "1B",
+				Letter: "B",
+				Row:    1,
+			},
+		},
+	}
+
+	catalog := aircraft.Catalog{
+		"TINY": {
+			Sections: []aircraft.Section{
+				{
+					Layout:   "A-B",
+					FirstRow: 1,
+					LastRow:  1,
+				},
+			},
+			ExitRows: []int{1},
+		},
+	}
+
+	airports := airport.Catalog{
+		"BOG": {IATACode: "BOG", City: "Bogotá", TimeZone: "America/Bogota"},
+		"MDE": {IATACode: "MDE", City: "Medellín", TimeZone: "America/Bogota"},
+	}
+
+	validBody := `{
+		"id": "f1",
+		"departure": "2020-04-30T19:00:00-05:00",
+		"origin": "BOG",
+		"destination": "MDE",
+		"origin_time_zone": "America/Bogota",
+		"seats": [
+			{"id": "1A", "letter": "A", "row": 1},
+			{"id": "1B", "letter": "B", "row": 1}
+		]
+	}`
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 201 status code after succesfully create a flight",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusCreated,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"id":"f1",
+					"carrier":"",
+					"flight_number":"",
+					"operating_date":"",
+					"marketing_flights":[],
+					"departure":"2020-05-01T00:00:00Z",
+					"block_minutes":0,
+					"origin":"BOG",
+					"destination":"MDE",
+					"origin_time_zone":"America/Bogota",
+					"aircraft_type":"",
+					"has_free_seats":true,
+					"seats":[
+						{"id":"1A","letter":"A","row":1,"position":"","cabin":"","exit_row":false,"extra_legroom":false},
+						{"id":"1B","letter":"B","row":1,"position":"","cabin":"","exit_row":false,"extra_legroom":false}
+					]
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				created := flight
+				created.HasFreeSeats = true
+				m.flightsRepo.On("Create", flight).Return(created, nil).Once()
+			},
+		},
+		{
+			name: "Get a 201 status code after succesfully create a flight from an aircraft type in the time zone of its origin",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00+0000",
+					"origin": "BOG",
+					"destination": "MDE",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusCreated,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"id":"f1",
+					"carrier":"",
+					"flight_number":"",
+					"operating_date":"",
+					"marketing_flights":[],
+					"departure":"2020-05-01T00:00:00Z",
+					"block_minutes":0,
+					"origin":"BOG",
+					"destination":"MDE",
+					"origin_time_zone":"America/Bogota",
+					"aircraft_type":"TINY",
+					"has_free_seats":true,
+					"seats":[
+						{"id":"1A","letter":"A","row":1,"position":"window","cabin":"economy","exit_row":true,"extra_legroom":false},
+						{"id":"1B","letter":"B","row":1,"position":"window","cabin":"economy","exit_row":true,"extra_legroom":false}
+					]
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				withAircraft := flight
+				withAircraft.AircraftType = "TINY"
+				withAircraft.Seats = catalog["TINY"].Seats()
+				created := withAircraft
+				created.HasFreeSeats = true
+				m.flightsRepo.On("Create", withAircraft).Return(created, nil).Once()
+			},
+		},
+		{
+			name: "Get a 201 status code after succesfully create a flight with its flight numbers",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"carrier": "AV",
+					"flight_number": "0123",
+					"marketing_flights": [{"carrier": "ib", "flight_number": "6421"}],
+					"departure": "2020-05-01T00:00:00Z",
+					"block_minutes": 55,
+					"origin": "BOG",
+					"destination": "MDE",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusCreated,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"id":"f1",
+					"carrier":"AV",
+					"flight_number":"123",
+					"operating_date":"2020-04-30",
+					"marketing_flights":[{"carrier":"IB","flight_number":"6421"}],
+					"departure":"2020-05-01T00:00:00Z",
+					"block_minutes":55,
+					"origin":"BOG",
+					"destination":"MDE",
+					"origin_time_zone":"America/Bogota",
+					"aircraft_type":"TINY",
+					"has_free_seats":true,
+					"seats":[
+						{"id":"1A","letter":"A","row":1,"position":"window","cabin":"economy","exit_row":true,"extra_legroom":false},
+						{"id":"1B","letter":"B","row":1,"position":"window","cabin":"economy","exit_row":true,"extra_legroom":false}
+					]
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				withFlightNumbers := flight
+				withFlightNumbers.Carrier = "AV"
+				withFlightNumbers.FlightNumber = "123"
+				withFlightNumbers.MarketingFlights = []model.MarketingFlight{{Carrier: "IB", FlightNumber: "6421"}}
+				withFlightNumbers.BlockMinutes = 55
+				withFlightNumbers.AircraftType = "TINY"
+				withFlightNumbers.Seats = catalog["TINY"].Seats()
+				created := withFlightNumbers
+				created.HasFreeSeats = true
+				created.OperatingDate = "2020-04-30"
+				m.flightsRepo.On("Create", withFlightNumbers).Return(created, nil).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because another flight has the flight number on the date",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"carrier": "AV",
+					"flight_number": "123",
+					"departure": "2020-05-01T00:00:00Z",
+					"origin": "BOG",
+					"destination": "MDE",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrFlightNumberTaken),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				withFlightNumber := flight
+				withFlightNumber.Carrier = "AV"
+				withFlightNumber.FlightNumber = "123"
+				withFlightNumber.AircraftType = "TINY"
+				withFlightNumber.Seats = catalog["TINY"].Seats()
+				m.flightsRepo.On("Create", withFlightNumber).Return(model.Flight{}, repository.ErrFlightNumberTaken).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because of an invalid flight number",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"carrier": "AV",
+					"flight_number": "12345",
+					"departure": "2020-05-01T00:00:00Z",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid flight_number"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because a codeshare repeats the operating flight number",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"carrier": "AV",
+					"flight_number": "123",
+					"marketing_flights": [{"carrier": "AV", "flight_number": "0123"}],
+					"departure": "2020-05-01T00:00:00Z",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid marketing_flights"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because of an invalid departure",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid departure"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because of an unknown time zone",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00Z",
+					"origin_time_zone": "America/Atlantis",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid origin_time_zone"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because the time zone is the one of the machine",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00Z",
+					"origin_time_zone": "Local",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid origin_time_zone"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because there is neither an origin nor a time zone",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00Z",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing origin_time_zone"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because the route has no destination",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00+0000",
+					"origin": "BOG",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid route"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because the origin is not an airport of the catalog",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00+0000",
+					"origin": "Bogota",
+					"destination": "MDE",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, airport.ErrUnknownAirport),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because the destination is not an airport of the catalog",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00+0000",
+					"origin": "BOG",
+					"destination": "XXX",
+					"aircraft_type": "TINY"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, airport.ErrUnknownAirport),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because the aircraft type is unknown",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00+0000",
+					"origin_time_zone": "America/Bogota",
+					"aircraft_type": "B747"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, aircraft.ErrUnknownAircraftType),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because seats are missing",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00+0000"
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because a seat has no row",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+					"id": "f1",
+					"departure": "2020-05-01T00:00:00+0000",
+					"origin_time_zone": "America/Bogota",
+					"seats": [{"id": "1A", "letter": "A"}]
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid seat"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because seat ids are repeated",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrDuplicatedSeatID),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Create", flight).Return(model.Flight{}, repository.ErrDuplicatedSeatID).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because the flight already exists",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrFlightAlreadyExists),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Create", flight).Return(model.Flight{}, repository.ErrFlightAlreadyExists).Once()
+			},
+		},
+		{
+			name: "Get a 500 status because the repository returned an unexpected error",
+			req: events.APIGatewayProxyRequest{
+				Body: validBody,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Create", flight).Return(model.Flight{}, errors.New("unexpected")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo, catalog, airports)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+		})
+	}
+
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Delete(id string) error
+}
+
+func Adapter(flightsRepo FlightsRepository) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		flightID := req.PathParameters["id"]
+		if internal.TrimLines(flightID) == "" {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+
+		// Delete flight, refused while it still has passengers
+		err := flightsRepo.Delete(flightID)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err == repository.ErrFlightHasPassengers || err == repository.ErrStaleFlight {
+			return internal.Error(http.StatusConflict, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		return internal.Respond(http.StatusOK, ""), nil
+	}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	lambda.Start(Adapter(flightsRepo))
+}
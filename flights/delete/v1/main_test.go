@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Delete(id string) error {
+	ret := m.Called(id)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code after succesfully delete a flight",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Delete", "f1").Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because the flight was not found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Delete", "f1").Return(repository.ErrNoFlightsFound).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because the flight still has passengers",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrFlightHasPassengers),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Delete", "f1").Return(repository.ErrFlightHasPassengers).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because a seat was taken while deleting the flight",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrStaleFlight),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Delete", "f1").Return(repository.ErrStaleFlight).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+		})
+	}
+
+}
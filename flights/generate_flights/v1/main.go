@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/schedule"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req Request) (Report, error)
+
+type SchedulesRepository interface {
+	List() ([]model.Schedule, error)
+}
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	Save(m model.Flight) (model.Flight, error)
+}
+
+type AircraftCatalog interface {
+	Find(aircraftType string) (aircraft.Configuration, error)
+}
+
+type AirportCatalog interface {
+	Find(code string) (airport.Airport, error)
+}
+
+// Request can ask for a different number of days than the configured ones
+type Request struct {
+	Days int `json:"days"`
+}
+
+type Report struct {
+	Created  []string `json:"created"`
+	Existing []string `json:"existing"`
+	Failed   []Failed `json:"failed"`
+}
+
+type Failed struct {
+	ScheduleID string `json:"schedule_id"`
+	FlightID   string `json:"flight_id"`
+	Reason     string `json:"reason"`
+}
+
+// Adapter generates the flights of every schedule departing in the next days.
+// Flight ids come from the flight number and the date, flights that exist
+// already are left as they are so it can be run again
+func Adapter(schedulesRepo SchedulesRepository, flightsRepo FlightsRepository, catalog AircraftCatalog, airports AirportCatalog, days int, now func() time.Time) Handler {
+	return func(ctx context.Context, req Request) (Report, error) {
+		horizon := days
+		if req.Days > 0 {
+			horizon = req.Days
+		}
+
+		schedules, err := schedulesRepo.List()
+		if err != nil {
+			return Report{}, err
+		}
+
+		from := now()
+		report := Report{
+			Created:  []string{},
+			Existing: []string{},
+			Failed:   []Failed{},
+		}
+		for _, s := range schedules {
+			departures, err := schedule.Departures(s, from, horizon)
+			if err != nil {
+				report.Failed = append(report.Failed, Failed{ScheduleID: s.ID, Reason: err.Error()})
+				continue
+			}
+			if len(departures) == 0 {
+				continue
+			}
+			_, err = airports.Find(s.Origin)
+			if err == nil {
+				_, err = airports.Find(s.Destination)
+			}
+			if err != nil {
+				report.Failed = append(report.Failed, Failed{ScheduleID: s.ID, Reason: err.Error()})
+				continue
+			}
+			configuration, err := catalog.Find(s.AircraftType)
+			if err != nil {
+				report.Failed = append(report.Failed, Failed{ScheduleID: s.ID, Reason: err.Error()})
+				continue
+			}
+
+			for _, departure := range departures {
+				flight := schedule.Flight(s, departure, configuration.Seats())
+				_, err := flightsRepo.Find(flight.ID)
+				if err == nil {
+					report.Existing = append(report.Existing, flight.ID)
+					continue
+				}
+				if err == repository.ErrNoFlightsFound {
+					_, err = flightsRepo.Save(flight)
+				}
+				// Another run created it in the meantime
+				if err == repository.ErrStaleFlight {
+					report.Existing = append(report.Existing, flight.ID)
+					continue
+				}
+				if err != nil {
+					report.Failed = append(report.Failed, Failed{ScheduleID: s.ID, FlightID: flight.ID, Reason: err.Error()})
+					continue
+				}
+				report.Created = append(report.Created, flight.ID)
+			}
+		}
+
+		return report, nil
+	}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	schedulesTable := os.Getenv("DYNAMODB_SCHEDULES")
+	if internal.TrimLines(schedulesTable) == "" {
+		panic("DYNAMODB_SCHEDULES is empty")
+	}
+	days, err := strconv.Atoi(os.Getenv("SCHEDULE_DAYS"))
+	if err != nil || days <= 0 {
+		panic("SCHEDULE_DAYS is not a number of days")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	schedulesRepo := repository.NewSchedulesRepository(dynamodbClient, schedulesTable)
+	catalog, err := aircraft.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	airports, err := airport.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	lambda.Start(Adapter(schedulesRepo, flightsRepo, catalog, airports, days, time.Now))
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type SchedulesRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *SchedulesRepositoryMock) List() ([]model.Schedule, error) {
+	ret := m.Called()
+	return ret.Get(0).([]model.Schedule), ret.Error(1)
+}
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) Save(f model.Flight) (model.Flight, error) {
+	ret := m.Called(f)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		schedulesRepo *SchedulesRepositoryMock
+		flightsRepo   *FlightsRepositoryMock
+	}
+
+	catalog := aircraft.Catalog{
+		"TINY": {
+			Sections: []aircraft.Section{
+				{
+					Layout:   "A-B",
+					FirstRow: 1,
+					LastRow:  1,
+				},
+			},
+		},
+	}
+	airports := airport.Catalog{
+		"BOG": {IATACode: "BOG", TimeZone: "America/Bogota"},
+		"MDE": {IATACode: "MDE", TimeZone: "America/Bogota"},
+	}
+	// 2020-05-01 is a Friday, 07:00 in Bogota
+	now := func() time.Time {
+		return time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	daily := model.Schedule{
+		ID:            "s1",
+		FlightNumber:  "XX123",
+		Origin:        "BOG",
+		Destination:   "MDE",
+		DaysOfWeek:    []int{1, 3, 5},
+		DepartureTime: "06:30",
+		TimeZone:      "America/Bogota",
+		ValidFrom:     "2020-05-01",
+		ValidTo:       "2020-12-31",
+		AircraftType:  "TINY",
+	}
+	withoutTimeZone := daily
+	withoutTimeZone.ID = "s2"
+	withoutTimeZone.TimeZone = ""
+	unknownAircraft := daily
+	unknownAircraft.ID = "s3"
+	unknownAircraft.FlightNumber = "XX125"
+	unknownAircraft.AircraftType = "B747"
+	unknownAirport := daily
+	unknownAirport.ID = "s4"
+	unknownAirport.FlightNumber = "XX127"
+	unknownAirport.Destination = "XXX"
+
+	flight := func(id string, departure string) model.Flight {
+		return model.Flight{
+			ID:             id,
+			Departure:      departure,
+			Origin:         "BOG",
+			Destination:    "MDE",
+			OriginTimeZone: "America/Bogota",
+			AircraftType:   "TINY",
+			Seats:          catalog["TINY"].Seats(),
+			Carrier:        "XX",
+			FlightNumber:   "123",
+			OperatingDate:  departure[:10],
+		}
+	}
+
+	tests := []struct {
+		name    string
+		req     Request
+		want    Report
+		wantErr error
+		mocker  func(m mocks)
+	}{
+		{
+			name: "Generate the flights of the configured days and leave the existing ones",
+			req:  Request{},
+			want: Report{
+				Created:  []string{"XX123-20200504-BOG"},
+				Existing: []string{"XX123-20200506-BOG", "XX123-20200508-BOG"},
+				Failed: []Failed{
+					{ScheduleID: "s2", Reason: "invalid_schedule: time_zone"},
+					{ScheduleID: "s3", Reason: aircraft.ErrUnknownAircraftType.Error()},
+					{ScheduleID: "s4", Reason: airport.ErrUnknownAirport.Error()},
+				},
+			},
+			mocker: func(m mocks) {
+				m.schedulesRepo.On("List").Return([]model.Schedule{daily, withoutTimeZone, unknownAircraft, unknownAirport}, nil).Once()
+
+				created := flight("XX123-20200504-BOG", "2020-05-04T11:30:00Z")
+				m.flightsRepo.On("Find", created.ID).Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+				m.flightsRepo.On("Save", created).Return(created, nil).Once()
+
+				existing := flight("XX123-20200506-BOG", "2020-05-06T11:30:00Z")
+				m.flightsRepo.On("Find", existing.ID).Return(existing, nil).Once()
+
+				// Created by another run between Find and Save
+				raced := flight("XX123-20200508-BOG", "2020-05-08T11:30:00Z")
+				m.flightsRepo.On("Find", raced.ID).Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+				m.flightsRepo.On("Save", raced).Return(model.Flight{}, repository.ErrStaleFlight).Once()
+			},
+		},
+		{
+			name: "Generate the flights of the requested days",
+			req:  Request{Days: 4},
+			want: Report{
+				Created:  []string{"XX123-20200504-BOG"},
+				Existing: []string{},
+				Failed:   []Failed{},
+			},
+			mocker: func(m mocks) {
+				m.schedulesRepo.On("List").Return([]model.Schedule{daily}, nil).Once()
+
+				created := flight("XX123-20200504-BOG", "2020-05-04T11:30:00Z")
+				m.flightsRepo.On("Find", created.ID).Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+				m.flightsRepo.On("Save", created).Return(created, nil).Once()
+			},
+		},
+		{
+			name: "Report the flights that could not be saved",
+			req:  Request{Days: 4},
+			want: Report{
+				Created:  []string{},
+				Existing: []string{},
+				Failed: []Failed{
+					{ScheduleID: "s1", FlightID: "XX123-20200504-BOG", Reason: "unexpected"},
+				},
+			},
+			mocker: func(m mocks) {
+				m.schedulesRepo.On("List").Return([]model.Schedule{daily}, nil).Once()
+
+				created := flight("XX123-20200504-BOG", "2020-05-04T11:30:00Z")
+				m.flightsRepo.On("Find", created.ID).Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+				m.flightsRepo.On("Save", created).Return(model.Flight{}, errors.New("unexpected")).Once()
+			},
+		},
+		{
+			name:    "Fail because the schedules could not be listed",
+			req:     Request{},
+			wantErr: errors.New("unexpected"),
+			mocker: func(m mocks) {
+				m.schedulesRepo.On("List").Return([]model.Schedule{}, errors.New("unexpected")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m := mocks{
+				schedulesRepo: &SchedulesRepositoryMock{},
+				flightsRepo:   &FlightsRepositoryMock{},
+			}
+			tt.mocker(m)
+
+			// Act
+			handler := Adapter(m.schedulesRepo, m.flightsRepo, catalog, airports, 10, now)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			if tt.wantErr != nil {
+				require.EqualError(t, err, tt.wantErr.Error())
+			} else {
+				require.NoError(t, err)
+				if diff := cmp.Diff(tt.want, got); diff != "" {
+					t.Errorf("Differences found: (-want,+got)\n%s", diff)
+				}
+			}
+			m.schedulesRepo.AssertExpectations(t)
+			m.flightsRepo.AssertExpectations(t)
+		})
+	}
+
+}
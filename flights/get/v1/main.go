@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	ListFlightsByFlightNumber(carrier string, flightNumber string, dateFrom string, dateTo string) ([]model.Flight, error)
+}
+
+type Response struct {
+	ID               string                  `json:"id"`
+	Carrier          string                  `json:"carrier"`
+	FlightNumber     string                  `json:"flight_number"`
+	OperatingDate    string                  `json:"operating_date"`
+	MarketingFlights []model.MarketingFlight `json:"marketing_flights"`
+	Departure        string                  `json:"departure"`
+	BlockMinutes     int                     `json:"block_minutes"`
+	Origin           string                  `json:"origin"`
+	Destination      string                  `json:"destination"`
+	OriginTimeZone   string                  `json:"origin_time_zone"`
+	AircraftType     string                  `json:"aircraft_type"`
+	Status           string                  `json:"status"`
+	HasFreeSeats     bool                    `json:"has_free_seats"`
+	Seats            []ResponseSeat          `json:"seats"`
+}
+
+// ResponseSeat tells if the seat is taken without telling who took it
+type ResponseSeat struct {
+	ID           string `json:"id"`
+	Letter       string `json:"letter"`
+	Row          int    `json:"row"`
+	Taken        bool   `json:"taken"`
+	Position     string `json:"position"`
+	Cabin        string `json:"cabin"`
+	ExitRow      bool   `json:"exit_row"`
+	ExtraLegroom bool   `json:"extra_legroom"`
+}
+
+// Adapter returns the flight of the id or the one operated or marketed with a
+// flight number on a date, e.g. ?flight_number=AV123&date=2020-05-01. Flights
+// with several legs on the date are told apart by their origin
+func Adapter(flightsRepo FlightsRepository) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		flightID := req.PathParameters["id"]
+		designator := req.QueryStringParameters["flight_number"]
+		date := req.QueryStringParameters["date"]
+		origin := strings.ToUpper(req.QueryStringParameters["origin"])
+
+		var flight model.Flight
+		var err error
+		if internal.TrimLines(flightID) != "" {
+			flight, err = flightsRepo.Find(flightID)
+		} else {
+			// Validations
+			if internal.TrimLines(designator) == "" || internal.TrimLines(date) == "" {
+				return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+			}
+			carrier, flightNumber, ok := model.ParseDesignator(designator)
+			if !ok {
+				return internal.Error(http.StatusBadRequest, errors.New("invalid flight_number")), nil
+			}
+			if _, err := time.Parse(model.OperatingDateLayout, date); err != nil {
+				return internal.Error(http.StatusBadRequest, errors.New("invalid date")), nil
+			}
+
+			flight, err = findLeg(flightsRepo, carrier, flightNumber, date, origin)
+		}
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Prepare response
+		response := Response{
+			ID:               flight.ID,
+			Carrier:          flight.Carrier,
+			FlightNumber:     flight.FlightNumber,
+			OperatingDate:    flight.OperatingDate,
+			MarketingFlights: make([]model.MarketingFlight, len(flight.MarketingFlights)),
+			Departure:        flight.Departure,
+			BlockMinutes:     flight.BlockMinutes,
+			Origin:           flight.Origin,
+			Destination:      flight.Destination,
+			OriginTimeZone:   flight.OriginTimeZone,
+			AircraftType:     flight.AircraftType,
+			Status:           flight.CurrentStatus(),
+			HasFreeSeats:     flight.HasFreeSeats,
+			Seats:            make([]ResponseSeat, len(flight.Seats)),
+		}
+		copy(response.MarketingFlights, flight.MarketingFlights)
+		for i, s := range flight.Seats {
+			response.Seats[i] = ResponseSeat{
+				ID:           s.ID,
+				Letter:       s.Letter,
+				Row:          s.Row,
+				Taken:        s.PassengerID != "",
+				Position:     s.Position,
+				Cabin:        s.Cabin,
+				ExitRow:      s.ExitRow,
+				ExtraLegroom: s.ExtraLegroom,
+			}
+		}
+
+		// Respond
+		responseBytes, _ := json.Marshal(response)
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+// findLeg returns the leg of the flight number departing from the origin on
+// the operating date, the first leg when there is no origin
+func findLeg(flightsRepo FlightsRepository, carrier string, flightNumber string, date string, origin string) (model.Flight, error) {
+	legs, err := flightsRepo.ListFlightsByFlightNumber(carrier, flightNumber, date, date)
+	if err != nil {
+		return model.Flight{}, err
+	}
+	for _, leg := range legs {
+		if origin == "" || leg.Origin == origin {
+			return leg, nil
+		}
+	}
+	return model.Flight{}, repository.ErrNoFlightsFound
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	lambda.Start(Adapter(flightsRepo))
+}
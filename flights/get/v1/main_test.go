@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) ListFlightsByFlightNumber(carrier string, flightNumber string, dateFrom string, dateTo string) ([]model.Flight, error) {
+	ret := m.Called(carrier, flightNumber, dateFrom, dateTo)
+	return ret.Get(0).([]model.Flight), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+	}
+
+	firstLeg := model.Flight{
+		ID:               "AV123-20200501",
+		Carrier:          "AV",
+		FlightNumber:     "123",
+		OperatingDate:    "2020-05-01",
+		MarketingFlights: []model.MarketingFlight{{Carrier: "IB", FlightNumber: "6421"}},
+		Departure:        "2020-05-01T11:30:00Z",
+		Origin:           "BOG",
+		Destination:      "MDE",
+		OriginTimeZone:   "America/Bogota",
+		HasFreeSeats:     true,
+		Seats: []model.FlightSeat{
+			{ID: "1A", Letter: "A", Row: 1, PassengerID: "p1"},
+			{ID: "1B", Letter: "B", Row: 1},
+		},
+	}
+	secondLeg := model.Flight{
+		ID:             "AV123-20200501-2",
+		Carrier:        "AV",
+		FlightNumber:   "123",
+		OperatingDate:  "2020-05-01",
+		Departure:      "2020-05-01T14:00:00Z",
+		Origin:         "MDE",
+		Destination:    "CTG",
+		OriginTimeZone: "America/Bogota",
+		Seats:          []model.FlightSeat{},
+	}
+	firstLegBody := `{"id":"AV123-20200501","carrier":"AV","flight_number":"123","operating_date":"2020-05-01",
+		"marketing_flights":[{"carrier":"IB","flight_number":"6421"}],"departure":"2020-05-01T11:30:00Z","block_minutes":0,
+		"origin":"BOG","destination":"MDE","origin_time_zone":"America/Bogota","aircraft_type":"",
+		"status":"scheduled","has_free_seats":true,"seats":[
+			{"id":"1A","letter":"A","row":1,"taken":true,"position":"","cabin":"","exit_row":false,"extra_legroom":false},
+			{"id":"1B","letter":"B","row":1,"taken":false,"position":"","cabin":"","exit_row":false,"extra_legroom":false}
+		]}`
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code with the flight of the id",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "AV123-20200501",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(firstLegBody),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "AV123-20200501").Return(firstLeg, nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code with the first leg of the flight number on the date",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"flight_number": "ib6421",
+					"date":          "2020-05-01",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(firstLegBody),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("ListFlightsByFlightNumber", "IB", "6421", "2020-05-01", "2020-05-01").
+					Return([]model.Flight{firstLeg, secondLeg}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code with the leg of the flight number departing from the origin",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"flight_number": "AV123",
+					"date":          "2020-05-01",
+					"origin":        "mde",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"id":"AV123-20200501-2","carrier":"AV","flight_number":"123",
+					"operating_date":"2020-05-01","marketing_flights":[],"departure":"2020-05-01T14:00:00Z","block_minutes":0,
+					"origin":"MDE","destination":"CTG","origin_time_zone":"America/Bogota","aircraft_type":"",
+					"status":"scheduled","has_free_seats":false,"seats":[]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("ListFlightsByFlightNumber", "AV", "123", "2020-05-01", "2020-05-01").
+					Return([]model.Flight{firstLeg, secondLeg}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status code because no leg of the flight number departs from the origin",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"flight_number": "AV123",
+					"date":          "2020-05-01",
+					"origin":        "CTG",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("ListFlightsByFlightNumber", "AV", "123", "2020-05-01", "2020-05-01").
+					Return([]model.Flight{firstLeg, secondLeg}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status code because the flight does not exist",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+			},
+		},
+		{
+			name: "Get a 400 status code because the date is missing",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"flight_number": "AV123",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status code because the flight number is malformed",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"flight_number": "AV12345",
+					"date":          "2020-05-01",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid flight_number"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status code because the date is malformed",
+			req: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"flight_number": "AV123",
+					"date":          "01-05-2020",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid date"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+		})
+	}
+
+}
@@ -0,0 +1,77 @@
+// Command cli imports the flights of an IATA SSIM schedule file, e.g.
+//
+//	go run ./flights/import_ssim/cli -file summer.ssim -days 180 -flights-table dev-flights
+//
+// It prints a report of the created, existing and failed flights and exits
+// with 1 when a record or a flight failed
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/ssim"
+)
+
+func main() {
+	file := flag.String("file", "", "SSIM file, the standard input when empty")
+	days := flag.Int("days", 365, "days from now to import")
+	flightsTable := flag.String("flights-table", os.Getenv("DYNAMODB_FLIGHTS"), "flights table")
+	reservationsTable := flag.String("reservations-table", os.Getenv("DYNAMODB_RESERVATIONS"), "reservations table")
+	flag.Parse()
+
+	if *flightsTable == "" || *days <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	catalog, err := aircraft.DefaultCatalog()
+	if err != nil {
+		fail(err)
+	}
+	airports, err := airport.DefaultCatalog()
+	if err != nil {
+		fail(err)
+	}
+	session := session.Must(session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	}))
+	flightsRepo := repository.NewFlightsRepository(dynamodb.New(session), *flightsTable, *reservationsTable)
+
+	now := time.Now()
+	report, err := ssim.Import(r, flightsRepo, catalog, airports, now, now.AddDate(0, 0, *days))
+	if err != nil {
+		fail(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,215 @@
+package aircraft
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrUnknownAircraftType = errors.New("unknown_aircraft_type")
+	ErrInvalidLayout       = errors.New("invalid_aircraft_layout")
+	ErrInvalidRows         = errors.New("invalid_aircraft_rows")
+	ErrInvalidCabin        = errors.New("invalid_aircraft_cabin")
+)
+
+// Aisle separates the groups of seats in a layout, "ABC-DEF" is a 3-3 cabin
+const Aisle = "-"
+
+type Catalog map[string]Configuration
+
+type Configuration struct {
+	Name string `yaml:"name"`
+	// IATACode is the aircraft code used by schedule files, e.g. 320
+	IATACode         string    `yaml:"iata_code"`
+	Sections         []Section `yaml:"sections"`
+	SkipRows         []int     `yaml:"skip_rows"`
+	ExitRows         []int     `yaml:"exit_rows"`
+	ExtraLegroomRows []int     `yaml:"extra_legroom_rows"`
+}
+
+type Section struct {
+	Cabin    string `yaml:"cabin"`
+	Layout   string `yaml:"layout"`
+	FirstRow int    `yaml:"first_row"`
+	LastRow  int    `yaml:"last_row"`
+}
+
+type catalogFile struct {
+	Aircraft Catalog `yaml:"aircraft"`
+}
+
+func (c Catalog) Find(aircraftType string) (Configuration, error) {
+	configuration, ok := c[aircraftType]
+	if !ok {
+		return Configuration{}, ErrUnknownAircraftType
+	}
+	return configuration, nil
+}
+
+// FindByIATACode returns the aircraft type and the configuration of the
+// aircraft with the IATA code, e.g. A320 for 320
+func (c Catalog) FindByIATACode(code string) (string, Configuration, error) {
+	for aircraftType, configuration := range c {
+		if configuration.IATACode != "" && configuration.IATACode == code {
+			return aircraftType, configuration, nil
+		}
+	}
+	return "", Configuration{}, ErrUnknownAircraftType
+}
+
+// Seats generates the seat map of the configuration, seat ids are the row
+// followed by the letter, e.g. 12C
+func (c Configuration) Seats() []model.FlightSeat {
+	skipRows := rowsSet(c.SkipRows)
+	exitRows := rowsSet(c.ExitRows)
+	extraLegroomRows := rowsSet(c.ExtraLegroomRows)
+
+	seats := []model.FlightSeat{}
+	for _, section := range c.Sections {
+		cabin := section.Cabin
+		if cabin == "" {
+			cabin = model.CabinEconomy
+		}
+		positions := section.positions()
+		aisles := section.aisles()
+		letters := strings.Replace(section.Layout, Aisle, "", -1)
+		for row := section.FirstRow; row <= section.LastRow; row++ {
+			if skipRows[row] {
+				continue
+			}
+			for _, l := range letters {
+				seats = append(seats, model.FlightSeat{
+					ID:           fmt.Sprintf("%d%c", row, l),
+					Letter:       string(l),
+					Row:          row,
+					Position:     positions[l],
+					Cabin:        cabin,
+					ExitRow:      exitRows[row],
+					ExtraLegroom: extraLegroomRows[row],
+					AisleAfter:   aisles[l],
+				})
+			}
+		}
+	}
+	return seats
+}
+
+// positions tells for every letter of the layout if it is a window, aisle or
+// middle seat, seats at both ends of the layout are windows
+func (s Section) positions() map[rune]string {
+	positions := map[rune]string{}
+	groups := strings.Split(s.Layout, Aisle)
+	for i, group := range groups {
+		letters := []rune(group)
+		for j, l := range letters {
+			switch {
+			case (i == 0 && j == 0) || (i == len(groups)-1 && j == len(letters)-1):
+				positions[l] = model.SeatPositionWindow
+			case j == 0 || j == len(letters)-1:
+				positions[l] = model.SeatPositionAisle
+			default:
+				positions[l] = model.SeatPositionMiddle
+			}
+		}
+	}
+	return positions
+}
+
+// aisles tells the letters of the layout followed by an aisle, the last one of
+// every group but the last
+func (s Section) aisles() map[rune]bool {
+	aisles := map[rune]bool{}
+	groups := strings.Split(s.Layout, Aisle)
+	for _, group := range groups[:len(groups)-1] {
+		letters := []rune(group)
+		if len(letters) > 0 {
+			aisles[letters[len(letters)-1]] = true
+		}
+	}
+	return aisles
+}
+
+func rowsSet(rows []int) map[int]bool {
+	set := map[int]bool{}
+	for _, r := range rows {
+		set[r] = true
+	}
+	return set
+}
+
+func (c Configuration) validate() error {
+	if len(c.Sections) == 0 {
+		return ErrInvalidRows
+	}
+
+	lastRow := 0
+	for _, section := range c.Sections {
+		switch section.Cabin {
+		case "", model.CabinEconomy, model.CabinPremiumEconomy, model.CabinBusiness, model.CabinFirst:
+		default:
+			return ErrInvalidCabin
+		}
+		if section.FirstRow <= lastRow || section.LastRow < section.FirstRow {
+			return ErrInvalidRows
+		}
+		lastRow = section.LastRow
+
+		letters := map[rune]bool{}
+		groups := strings.Split(section.Layout, Aisle)
+		for _, group := range groups {
+			if group == "" {
+				return ErrInvalidLayout
+			}
+			for _, l := range group {
+				if l < 'A' || l > 'Z' || letters[l] {
+					return ErrInvalidLayout
+				}
+				letters[l] = true
+			}
+		}
+	}
+	return nil
+}
+
+func LoadCatalog(r io.Reader) (Catalog, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	file := catalogFile{}
+	err = yaml.UnmarshalStrict(content, &file)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	for aircraftType, configuration := range file.Aircraft {
+		err := configuration.validate()
+		if err != nil {
+			return Catalog{}, fmt.Errorf("%v: %w", aircraftType, err)
+		}
+	}
+
+	return file.Aircraft, nil
+}
+
+func LoadCatalogFile(path string) (Catalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+	defer f.Close()
+
+	return LoadCatalog(f)
+}
+
+func DefaultCatalog() (Catalog, error) {
+	return LoadCatalog(strings.NewReader(defaultCatalog))
+}
@@ -0,0 +1,161 @@
+package aircraft
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{
+			name: "Load a valid catalog",
+			content: `
+aircraft:
+  A320:
+    name: Airbus A320
+    sections:
+      - layout: ABC-DEF
+        first_row: 1
+        last_row: 30
+    skip_rows: [13]
+    exit_rows: [11, 12]
+`,
+		},
+		{
+			name: "Fail because a layout repeats a letter",
+			content: `
+aircraft:
+  A320:
+    sections:
+      - layout: ABC-CDE
+        first_row: 1
+        last_row: 30
+`,
+			wantErr: ErrInvalidLayout,
+		},
+		{
+			name: "Fail because a layout has two aisles together",
+			content: `
+aircraft:
+  A320:
+    sections:
+      - layout: ABC--DEF
+        first_row: 1
+        last_row: 30
+`,
+			wantErr: ErrInvalidLayout,
+		},
+		{
+			name: "Fail because the cabin is unknown",
+			content: `
+aircraft:
+  A320:
+    sections:
+      - cabin: lounge
+        layout: ABC-DEF
+        first_row: 1
+        last_row: 30
+`,
+			wantErr: ErrInvalidCabin,
+		},
+		{
+			name: "Fail because sections overlap",
+			content: `
+aircraft:
+  A320:
+    sections:
+      - layout: AC-DF
+        first_row: 1
+        last_row: 4
+      - layout: ABC-DEF
+        first_row: 4
+        last_row: 30
+`,
+			wantErr: ErrInvalidRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCatalog(strings.NewReader(tt.content))
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, errors.Is(err, tt.wantErr), "unexpected error %v", err)
+		})
+	}
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	catalog, err := DefaultCatalog()
+	require.NoError(t, err)
+
+	for aircraftType, configuration := range catalog {
+		seatIDs := map[string]bool{}
+		for _, s := range configuration.Seats() {
+			require.False(t, seatIDs[s.ID], "%v has the seat %v repeated", aircraftType, s.ID)
+			seatIDs[s.ID] = true
+		}
+	}
+
+	_, err = catalog.Find("B747")
+	require.Equal(t, ErrUnknownAircraftType, err)
+
+	aircraftType, _, err := catalog.FindByIATACode("320")
+	require.NoError(t, err)
+	require.Equal(t, "A320", aircraftType)
+	_, _, err = catalog.FindByIATACode("744")
+	require.Equal(t, ErrUnknownAircraftType, err)
+}
+
+func TestConfiguration_Seats(t *testing.T) {
+	configuration := Configuration{
+		Sections: []Section{
+			{
+				Cabin:    model.CabinBusiness,
+				Layout:   "A-C",
+				FirstRow: 1,
+				LastRow:  1,
+			},
+			{
+				Layout:   "AB-CDE-FG",
+				FirstRow: 12,
+				LastRow:  14,
+			},
+		},
+		SkipRows:         []int{13},
+		ExitRows:         []int{12},
+		ExtraLegroomRows: []int{12},
+	}
+
+	seats := configuration.Seats()
+
+	require.Len(t, seats, 16)
+	require.Equal(t, model.FlightSeat{
+		ID:       "1C",
+		Letter:   "C",
+		Row:      1,
+		Position: model.SeatPositionWindow,
+		Cabin:    model.CabinBusiness,
+	}, seats[1])
+	require.Equal(t, []model.FlightSeat{
+		{ID: "12A", Letter: "A", Row: 12, Position: model.SeatPositionWindow, Cabin: model.CabinEconomy, ExitRow: true, ExtraLegroom: true},
+		{ID: "12B", Letter: "B", Row: 12, Position: model.SeatPositionAisle, Cabin: model.CabinEconomy, ExitRow: true, ExtraLegroom: true, AisleAfter: true},
+		{ID: "12C", Letter: "C", Row: 12, Position: model.SeatPositionAisle, Cabin: model.CabinEconomy, ExitRow: true, ExtraLegroom: true},
+		{ID: "12D", Letter: "D", Row: 12, Position: model.SeatPositionMiddle, Cabin: model.CabinEconomy, ExitRow: true, ExtraLegroom: true},
+		{ID: "12E", Letter: "E", Row: 12, Position: model.SeatPositionAisle, Cabin: model.CabinEconomy, ExitRow: true, ExtraLegroom: true, AisleAfter: true},
+		{ID: "12F", Letter: "F", Row: 12, Position: model.SeatPositionAisle, Cabin: model.CabinEconomy, ExitRow: true, ExtraLegroom: true},
+		{ID: "12G", Letter: "G", Row: 12, Position: model.SeatPositionWindow, Cabin: model.CabinEconomy, ExitRow: true, ExtraLegroom: true},
+	}, seats[2:9])
+	require.Equal(t, "14A", seats[9].ID)
+	require.False(t, seats[9].ExitRow)
+}
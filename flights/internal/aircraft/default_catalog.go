@@ -0,0 +1,48 @@
+package aircraft
+
+// defaultCatalog is compiled into the lambdas so they can generate seat maps
+// without reading files at runtime
+const defaultCatalog = `
+aircraft:
+  A319:
+    name: Airbus A319
+    iata_code: "319"
+    sections:
+      - cabin: economy
+        layout: ABC-DEF
+        first_row: 1
+        last_row: 25
+    skip_rows: [13]
+    exit_rows: [10]
+    extra_legroom_rows: [1, 10]
+  A320:
+    name: Airbus A320
+    iata_code: "320"
+    sections:
+      - cabin: business
+        layout: AC-DF
+        first_row: 1
+        last_row: 3
+      - cabin: economy
+        layout: ABC-DEF
+        first_row: 4
+        last_row: 31
+    skip_rows: [13]
+    exit_rows: [11, 12]
+    extra_legroom_rows: [4, 11, 12]
+  B787:
+    name: Boeing 787-8 Dreamliner
+    iata_code: "788"
+    sections:
+      - cabin: business
+        layout: A-DG-K
+        first_row: 1
+        last_row: 7
+      - cabin: economy
+        layout: AC-DEFG-HK
+        first_row: 10
+        last_row: 36
+    skip_rows: [13]
+    exit_rows: [10, 24]
+    extra_legroom_rows: [10, 24]
+`
@@ -0,0 +1,217 @@
+package airport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrUnknownAirport = errors.New("unknown_airport")
+	ErrInvalidAirport = errors.New("invalid_airport")
+)
+
+// Catalog has the airports by IATA code, e.g. BOG
+type Catalog map[string]Airport
+
+type Airport struct {
+	IATACode  string  `yaml:"-"`
+	ICAOCode  string  `yaml:"icao_code"`
+	Name      string  `yaml:"name"`
+	City      string  `yaml:"city"`
+	Country   string  `yaml:"country"`
+	TimeZone  string  `yaml:"time_zone"`
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+}
+
+type catalogFile struct {
+	Airports Catalog `yaml:"airports"`
+}
+
+func (c Catalog) Find(code string) (Airport, error) {
+	airport, ok := c[code]
+	if !ok {
+		return Airport{}, ErrUnknownAirport
+	}
+	return airport, nil
+}
+
+// FindByICAOCode returns the airport with the ICAO code, e.g. SKBO for BOG
+func (c Catalog) FindByICAOCode(code string) (Airport, error) {
+	for _, airport := range c {
+		if airport.ICAOCode == code {
+			return airport, nil
+		}
+	}
+	return Airport{}, ErrUnknownAirport
+}
+
+// Search returns the airports matching what a user is typing, the exact codes
+// come first, then the codes, cities and names starting with the query. Case
+// and accents are ignored so "medellin" finds Medellín
+func (c Catalog) Search(query string, limit int) []Airport {
+	query = fold(strings.TrimSpace(query))
+	if query == "" || limit <= 0 {
+		return []Airport{}
+	}
+
+	type match struct {
+		airport Airport
+		rank    int
+	}
+	matches := []match{}
+	for _, airport := range c {
+		rank := airport.rank(query)
+		if rank < 0 {
+			continue
+		}
+		matches = append(matches, match{airport, rank})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return matches[i].airport.IATACode < matches[j].airport.IATACode
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	airports := make([]Airport, len(matches))
+	for i, m := range matches {
+		airports[i] = m.airport
+	}
+	return airports
+}
+
+// rank tells how well the airport matches the folded query, lower is better
+// and -1 is no match
+func (a Airport) rank(query string) int {
+	iata := fold(a.IATACode)
+	icao := fold(a.ICAOCode)
+	city := fold(a.City)
+	switch {
+	case iata == query:
+		return 0
+	case icao == query:
+		return 1
+	case strings.HasPrefix(iata, query):
+		return 2
+	case strings.HasPrefix(city, query):
+		return 3
+	case hasWordPrefix(city, query) || hasWordPrefix(fold(a.Name), query):
+		return 4
+	}
+	return -1
+}
+
+// Location returns the time zone of the airport
+func (a Airport) Location() (*time.Location, error) {
+	return time.LoadLocation(a.TimeZone)
+}
+
+// Place is how the airport is shown to passengers, e.g. Bogotá (BOG)
+func (a Airport) Place() string {
+	return fmt.Sprintf("%v (%v)", a.City, a.IATACode)
+}
+
+func hasWordPrefix(s string, prefix string) bool {
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '/'
+	}) {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// fold lower cases the text and removes the accents
+func fold(s string) string {
+	return accents.Replace(strings.ToLower(s))
+}
+
+func (a Airport) validate() error {
+	if !isCode(a.IATACode, 3) {
+		return fmt.Errorf("%w: iata_code", ErrInvalidAirport)
+	}
+	if !isCode(a.ICAOCode, 4) {
+		return fmt.Errorf("%w: icao_code", ErrInvalidAirport)
+	}
+	if strings.TrimSpace(a.Name) == "" {
+		return fmt.Errorf("%w: name", ErrInvalidAirport)
+	}
+	if strings.TrimSpace(a.City) == "" {
+		return fmt.Errorf("%w: city", ErrInvalidAirport)
+	}
+	if !isCode(a.Country, 2) {
+		return fmt.Errorf("%w: country", ErrInvalidAirport)
+	}
+	if a.TimeZone == "" {
+		return fmt.Errorf("%w: time_zone", ErrInvalidAirport)
+	}
+	if _, err := a.Location(); err != nil {
+		return fmt.Errorf("%w: time_zone", ErrInvalidAirport)
+	}
+	if a.Latitude < -90 || a.Latitude > 90 || a.Longitude < -180 || a.Longitude > 180 {
+		return fmt.Errorf("%w: coordinates", ErrInvalidAirport)
+	}
+	return nil
+}
+
+// isCode tells if the code has the length and only upper case letters
+func isCode(code string, length int) bool {
+	if len(code) != length {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func LoadCatalog(r io.Reader) (Catalog, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	file := catalogFile{}
+	err = yaml.UnmarshalStrict(content, &file)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	for code, airport := range file.Airports {
+		airport.IATACode = code
+		err := airport.validate()
+		if err != nil {
+			return Catalog{}, fmt.Errorf("%v: %w", code, err)
+		}
+		file.Airports[code] = airport
+	}
+
+	return file.Airports, nil
+}
+
+func DefaultCatalog() (Catalog, error) {
+	return LoadCatalog(strings.NewReader(defaultCatalog))
+}
@@ -0,0 +1,199 @@
+package airport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{
+			name: "Load a valid catalog",
+			content: `
+airports:
+  BOG:
+    icao_code: SKBO
+    name: El Dorado International Airport
+    city: Bogotá
+    country: CO
+    time_zone: America/Bogota
+    latitude: 4.7016
+    longitude: -74.1469
+`,
+		},
+		{
+			name: "Fail because the IATA code is not three letters",
+			content: `
+airports:
+  BOGO:
+    icao_code: SKBO
+    name: El Dorado International Airport
+    city: Bogotá
+    country: CO
+    time_zone: America/Bogota
+`,
+			wantErr: ErrInvalidAirport,
+		},
+		{
+			name: "Fail because the city is missing",
+			content: `
+airports:
+  BOG:
+    icao_code: SKBO
+    name: El Dorado International Airport
+    country: CO
+    time_zone: America/Bogota
+`,
+			wantErr: ErrInvalidAirport,
+		},
+		{
+			name: "Fail because the time zone is unknown",
+			content: `
+airports:
+  BOG:
+    icao_code: SKBO
+    name: El Dorado International Airport
+    city: Bogotá
+    country: CO
+    time_zone: America/Atlantis
+`,
+			wantErr: ErrInvalidAirport,
+		},
+		{
+			name: "Fail because the coordinates are out of range",
+			content: `
+airports:
+  BOG:
+    icao_code: SKBO
+    name: El Dorado International Airport
+    city: Bogotá
+    country: CO
+    time_zone: America/Bogota
+    latitude: 94.7016
+`,
+			wantErr: ErrInvalidAirport,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCatalog(strings.NewReader(tt.content))
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, errors.Is(err, tt.wantErr), "unexpected error %v", err)
+		})
+	}
+}
+
+func TestDefaultCatalog(t *testing.T) {
+	catalog, err := DefaultCatalog()
+	require.NoError(t, err)
+
+	icaoCodes := map[string]bool{}
+	for code, airport := range catalog {
+		require.Equal(t, code, airport.IATACode)
+		require.False(t, icaoCodes[airport.ICAOCode], "%v has the ICAO code %v repeated", code, airport.ICAOCode)
+		icaoCodes[airport.ICAOCode] = true
+	}
+
+	bog, err := catalog.Find("BOG")
+	require.NoError(t, err)
+	require.Equal(t, "Bogotá (BOG)", bog.Place())
+	location, err := bog.Location()
+	require.NoError(t, err)
+	require.Equal(t, "America/Bogota", location.String())
+
+	_, err = catalog.Find("XXX")
+	require.Equal(t, ErrUnknownAirport, err)
+
+	mde, err := catalog.FindByICAOCode("SKRG")
+	require.NoError(t, err)
+	require.Equal(t, "MDE", mde.IATACode)
+	_, err = catalog.FindByICAOCode("XXXX")
+	require.Equal(t, ErrUnknownAirport, err)
+}
+
+func TestCatalog_Search(t *testing.T) {
+	catalog, err := DefaultCatalog()
+	require.NoError(t, err)
+
+	codes := func(airports []Airport) []string {
+		found := []string{}
+		for _, a := range airports {
+			found = append(found, a.IATACode)
+		}
+		return found
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "The exact IATA code comes first",
+			query: "cLo",
+			limit: 10,
+			want:  []string{"CLO"},
+		},
+		{
+			name:  "Find an airport by its ICAO code",
+			query: "skbo",
+			limit: 10,
+			want:  []string{"BOG"},
+		},
+		{
+			name:  "Codes starting with the query come before names",
+			query: "mi",
+			limit: 10,
+			want:  []string{"MIA", "EZE"},
+		},
+		{
+			name:  "Cities starting with the query come before names",
+			query: "ca",
+			limit: 10,
+			want:  []string{"CCS", "CLO", "CTG", "CUN", "CUC", "PUJ"},
+		},
+		{
+			name:  "Accents are ignored",
+			query: "medellin",
+			limit: 10,
+			want:  []string{"EOH", "MDE"},
+		},
+		{
+			name:  "Find an airport by a word of its name",
+			query: "dorado",
+			limit: 10,
+			want:  []string{"BOG"},
+		},
+		{
+			name:  "Cap the results",
+			query: "san",
+			limit: 2,
+			want:  []string{"ADZ", "SAL"},
+		},
+		{
+			name:  "Nothing is found for an empty query",
+			query: " ",
+			limit: 10,
+			want:  []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := catalog.Search(tt.query, tt.limit)
+			require.Equal(t, tt.want, codes(got))
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package airport
+
+// defaultCatalog is compiled into the lambdas so they can validate routes and
+// show city names without reading files at runtime
+const defaultCatalog = `
+airports:
+  BOG:
+    icao_code: SKBO
+    name: El Dorado International Airport
+    city: Bogotá
+    country: CO
+    time_zone: America/Bogota
+    latitude: 4.7016
+    longitude: -74.1469
+  MDE:
+    icao_code: SKRG
+    name: José María Córdova International Airport
+    city: Medellín
+    country: CO
+    time_zone: America/Bogota
+    latitude: 6.1645
+    longitude: -75.4231
+  EOH:
+    icao_code: SKMD
+    name: Olaya Herrera Airport
+    city: Medellín
+    country: CO
+    time_zone: America/Bogota
+    latitude: 6.2205
+    longitude: -75.5906
+  CLO:
+    icao_code: SKCL
+    name: Alfonso Bonilla Aragón International Airport
+    city: Cali
+    country: CO
+    time_zone: America/Bogota
+    latitude: 3.5432
+    longitude: -76.3816
+  CTG:
+    icao_code: SKCG
+    name: Rafael Núñez International Airport
+    city: Cartagena
+    country: CO
+    time_zone: America/Bogota
+    latitude: 10.4424
+    longitude: -75.5130
+  BAQ:
+    icao_code: SKBQ
+    name: Ernesto Cortissoz International Airport
+    city: Barranquilla
+    country: CO
+    time_zone: America/Bogota
+    latitude: 10.8896
+    longitude: -74.7808
+  SMR:
+    icao_code: SKSM
+    name: Simón Bolívar International Airport
+    city: Santa Marta
+    country: CO
+    time_zone: America/Bogota
+    latitude: 11.1196
+    longitude: -74.2306
+  ADZ:
+    icao_code: SKSP
+    name: Gustavo Rojas Pinilla International Airport
+    city: San Andrés
+    country: CO
+    time_zone: America/Bogota
+    latitude: 12.5836
+    longitude: -81.7112
+  BGA:
+    icao_code: SKBG
+    name: Palonegro International Airport
+    city: Bucaramanga
+    country: CO
+    time_zone: America/Bogota
+    latitude: 7.1265
+    longitude: -73.1848
+  PEI:
+    icao_code: SKPE
+    name: Matecaña International Airport
+    city: Pereira
+    country: CO
+    time_zone: America/Bogota
+    latitude: 4.8127
+    longitude: -75.7395
+  CUC:
+    icao_code: SKCC
+    name: Camilo Daza International Airport
+    city: Cúcuta
+    country: CO
+    time_zone: America/Bogota
+    latitude: 7.9276
+    longitude: -72.5115
+  LET:
+    icao_code: SKLT
+    name: Alfredo Vásquez Cobo International Airport
+    city: Leticia
+    country: CO
+    time_zone: America/Bogota
+    latitude: -4.1935
+    longitude: -69.9432
+  PTY:
+    icao_code: MPTO
+    name: Tocumen International Airport
+    city: Panama City
+    country: PA
+    time_zone: America/Panama
+    latitude: 9.0714
+    longitude: -79.3835
+  UIO:
+    icao_code: SEQM
+    name: Mariscal Sucre International Airport
+    city: Quito
+    country: EC
+    time_zone: America/Guayaquil
+    latitude: -0.1292
+    longitude: -78.3575
+  GYE:
+    icao_code: SEGU
+    name: José Joaquín de Olmedo International Airport
+    city: Guayaquil
+    country: EC
+    time_zone: America/Guayaquil
+    latitude: -2.1574
+    longitude: -79.8836
+  LIM:
+    icao_code: SPJC
+    name: Jorge Chávez International Airport
+    city: Lima
+    country: PE
+    time_zone: America/Lima
+    latitude: -12.0219
+    longitude: -77.1143
+  CCS:
+    icao_code: SVMI
+    name: Simón Bolívar International Airport
+    city: Caracas
+    country: VE
+    time_zone: America/Caracas
+    latitude: 10.6031
+    longitude: -66.9906
+  SCL:
+    icao_code: SCEL
+    name: Arturo Merino Benítez International Airport
+    city: Santiago
+    country: CL
+    time_zone: America/Santiago
+    latitude: -33.3930
+    longitude: -70.7858
+  EZE:
+    icao_code: SAEZ
+    name: Ministro Pistarini International Airport
+    city: Buenos Aires
+    country: AR
+    time_zone: America/Argentina/Buenos_Aires
+    latitude: -34.8222
+    longitude: -58.5358
+  GRU:
+    icao_code: SBGR
+    name: São Paulo/Guarulhos International Airport
+    city: São Paulo
+    country: BR
+    time_zone: America/Sao_Paulo
+    latitude: -23.4356
+    longitude: -46.4731
+  GIG:
+    icao_code: SBGL
+    name: Rio de Janeiro/Galeão International Airport
+    city: Rio de Janeiro
+    country: BR
+    time_zone: America/Sao_Paulo
+    latitude: -22.8090
+    longitude: -43.2506
+  MEX:
+    icao_code: MMMX
+    name: Mexico City International Airport
+    city: Mexico City
+    country: MX
+    time_zone: America/Mexico_City
+    latitude: 19.4363
+    longitude: -99.0721
+  CUN:
+    icao_code: MMUN
+    name: Cancún International Airport
+    city: Cancún
+    country: MX
+    time_zone: America/Cancun
+    latitude: 21.0365
+    longitude: -86.8771
+  SJO:
+    icao_code: MROC
+    name: Juan Santamaría International Airport
+    city: San José
+    country: CR
+    time_zone: America/Costa_Rica
+    latitude: 9.9939
+    longitude: -84.2088
+  SAL:
+    icao_code: MSLP
+    name: El Salvador International Airport
+    city: San Salvador
+    country: SV
+    time_zone: America/El_Salvador
+    latitude: 13.4409
+    longitude: -89.0557
+  HAV:
+    icao_code: MUHA
+    name: José Martí International Airport
+    city: Havana
+    country: CU
+    time_zone: America/Havana
+    latitude: 22.9892
+    longitude: -82.4091
+  PUJ:
+    icao_code: MDPC
+    name: Punta Cana International Airport
+    city: Punta Cana
+    country: DO
+    time_zone: America/Santo_Domingo
+    latitude: 18.5674
+    longitude: -68.3634
+  MIA:
+    icao_code: KMIA
+    name: Miami International Airport
+    city: Miami
+    country: US
+    time_zone: America/New_York
+    latitude: 25.7959
+    longitude: -80.2870
+  FLL:
+    icao_code: KFLL
+    name: Fort Lauderdale-Hollywood International Airport
+    city: Fort Lauderdale
+    country: US
+    time_zone: America/New_York
+    latitude: 26.0742
+    longitude: -80.1506
+  MCO:
+    icao_code: KMCO
+    name: Orlando International Airport
+    city: Orlando
+    country: US
+    time_zone: America/New_York
+    latitude: 28.4312
+    longitude: -81.3081
+  JFK:
+    icao_code: KJFK
+    name: John F. Kennedy International Airport
+    city: New York
+    country: US
+    time_zone: America/New_York
+    latitude: 40.6413
+    longitude: -73.7781
+  ATL:
+    icao_code: KATL
+    name: Hartsfield-Jackson Atlanta International Airport
+    city: Atlanta
+    country: US
+    time_zone: America/New_York
+    latitude: 33.6407
+    longitude: -84.4277
+  IAH:
+    icao_code: KIAH
+    name: George Bush Intercontinental Airport
+    city: Houston
+    country: US
+    time_zone: America/Chicago
+    latitude: 29.9902
+    longitude: -95.3368
+  ORD:
+    icao_code: KORD
+    name: "O'Hare International Airport"
+    city: Chicago
+    country: US
+    time_zone: America/Chicago
+    latitude: 41.9742
+    longitude: -87.9073
+  LAX:
+    icao_code: KLAX
+    name: Los Angeles International Airport
+    city: Los Angeles
+    country: US
+    time_zone: America/Los_Angeles
+    latitude: 33.9416
+    longitude: -118.4085
+  YYZ:
+    icao_code: CYYZ
+    name: Toronto Pearson International Airport
+    city: Toronto
+    country: CA
+    time_zone: America/Toronto
+    latitude: 43.6777
+    longitude: -79.6248
+  MAD:
+    icao_code: LEMD
+    name: Adolfo Suárez Madrid-Barajas Airport
+    city: Madrid
+    country: ES
+    time_zone: Europe/Madrid
+    latitude: 40.4983
+    longitude: -3.5676
+  BCN:
+    icao_code: LEBL
+    name: Josep Tarradellas Barcelona-El Prat Airport
+    city: Barcelona
+    country: ES
+    time_zone: Europe/Madrid
+    latitude: 41.2974
+    longitude: 2.0833
+  LHR:
+    icao_code: EGLL
+    name: Heathrow Airport
+    city: London
+    country: GB
+    time_zone: Europe/London
+    latitude: 51.4700
+    longitude: -0.4543
+  CDG:
+    icao_code: LFPG
+    name: Charles de Gaulle Airport
+    city: Paris
+    country: FR
+    time_zone: Europe/Paris
+    latitude: 49.0097
+    longitude: 2.5479
+  AMS:
+    icao_code: EHAM
+    name: Amsterdam Airport Schiphol
+    city: Amsterdam
+    country: NL
+    time_zone: Europe/Amsterdam
+    latitude: 52.3105
+    longitude: 4.7683
+  FRA:
+    icao_code: EDDF
+    name: Frankfurt Airport
+    city: Frankfurt
+    country: DE
+    time_zone: Europe/Berlin
+    latitude: 50.0379
+    longitude: 8.5622
+`
@@ -0,0 +1,40 @@
+package airport
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// earthRadius is the mean radius of the earth in kilometers
+	earthRadius = 6371.0
+	// cruiseSpeed is the average ground speed of a jet in km/h
+	cruiseSpeed = 800.0
+	// groundTime is the taxi out and taxi in of a flight
+	groundTime = 30 * time.Minute
+)
+
+// Distance is the great circle distance in kilometers between two airports
+func Distance(from Airport, to Airport) float64 {
+	lat1, lat2 := radians(from.Latitude), radians(to.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(to.Longitude - from.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// EstimateBlockTime is the time from the departure to the arrival of a flight
+// between two airports flying straight at cruise speed, rounded up to five
+// minutes. It is used for flights without a scheduled block time
+func EstimateBlockTime(from Airport, to Airport) time.Duration {
+	flying := time.Duration(Distance(from, to) / cruiseSpeed * float64(time.Hour))
+	block := groundTime + flying
+	if rest := block % (5 * time.Minute); rest != 0 {
+		block += 5*time.Minute - rest
+	}
+	return block
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,61 @@
+package airport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateBlockTime(t *testing.T) {
+	catalog, err := DefaultCatalog()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		from         string
+		to           string
+		wantDistance float64
+		want         time.Duration
+	}{
+		{
+			name:         "A domestic flight",
+			from:         "BOG",
+			to:           "MDE",
+			wantDistance: 215,
+			want:         50 * time.Minute,
+		},
+		{
+			name:         "An international flight",
+			from:         "BOG",
+			to:           "MIA",
+			wantDistance: 2430,
+			want:         3*time.Hour + 35*time.Minute,
+		},
+		{
+			name:         "The same distance both ways",
+			from:         "MIA",
+			to:           "BOG",
+			wantDistance: 2430,
+			want:         3*time.Hour + 35*time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			from, err := catalog.Find(tt.from)
+			require.NoError(t, err)
+			to, err := catalog.Find(tt.to)
+			require.NoError(t, err)
+
+			// Act
+			distance := Distance(from, to)
+			got := EstimateBlockTime(from, to)
+
+			// Assert
+			require.InDelta(t, tt.wantDistance, distance, 10)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
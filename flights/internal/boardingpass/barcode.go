@@ -0,0 +1,58 @@
+package boardingpass
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/pdf417"
+	"github.com/boombuler/barcode/qr"
+)
+
+const (
+	BarcodeQR     = "qr"
+	BarcodePDF417 = "pdf417"
+)
+
+var (
+	ErrUnknownBarcode = errors.New("unknown_barcode")
+)
+
+// moduleSize is how many pixels a module of the barcode takes
+const moduleSize = 4
+
+// IsBarcode tells if the barcode can be rendered
+func IsBarcode(format string) bool {
+	return format == BarcodeQR || format == BarcodePDF417
+}
+
+// Render draws the boarding pass as a PNG image of a QR or a PDF417 code
+func Render(bcbp string, format string) ([]byte, error) {
+	var code barcode.Barcode
+	var err error
+	switch format {
+	case BarcodeQR:
+		code, err = qr.Encode(bcbp, qr.M, qr.Auto)
+	case BarcodePDF417:
+		code, err = pdf417.Encode(bcbp, 2)
+	default:
+		return nil, ErrUnknownBarcode
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := code.Bounds()
+	code, err = barcode.Scale(code, bounds.Dx()*moduleSize, bounds.Dy()*moduleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.Buffer{}
+	err = png.Encode(&buf, code)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,104 @@
+package boardingpass
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+var (
+	ErrIncompletePass = errors.New("incomplete_boarding_pass")
+)
+
+// compartments are the IATA compartment codes of the cabins
+var compartments = map[string]string{
+	model.CabinFirst:          "F",
+	model.CabinBusiness:       "J",
+	model.CabinPremiumEconomy: "W",
+	model.CabinEconomy:        "Y",
+	"":                        "Y",
+}
+
+// Pass is what a boarding pass needs to know about a checked in passenger
+type Pass struct {
+	PassengerName string
+	Locator       string
+	Origin        string
+	Destination   string
+	Carrier       string
+	FlightNumber  string
+	Departure     time.Time
+	Seat          model.FlightSeat
+	Sequence      int
+}
+
+// Encode builds the IATA Bar Coded Boarding Pass (Resolution 792) of a single
+// leg with only the mandatory items, 60 characters long
+func Encode(p Pass) (string, error) {
+	if p.PassengerName == "" || p.Locator == "" || p.Origin == "" ||
+		p.Destination == "" || p.Carrier == "" || p.Seat.Row == 0 {
+		return "", ErrIncompletePass
+	}
+
+	b := strings.Builder{}
+	b.WriteString("M1")
+	b.WriteString(field(passengerName(p.PassengerName), 20))
+	b.WriteString("E")
+	b.WriteString(field(p.Locator, 7))
+	b.WriteString(field(p.Origin, 3))
+	b.WriteString(field(p.Destination, 3))
+	b.WriteString(field(p.Carrier, 3))
+	b.WriteString(field(flightNumber(p.FlightNumber), 5))
+	b.WriteString(fmt.Sprintf("%03d", p.Departure.YearDay()))
+	b.WriteString(compartments[p.Seat.Cabin])
+	b.WriteString(field(fmt.Sprintf("%03d%s", p.Seat.Row, p.Seat.Letter), 4))
+	b.WriteString(field(fmt.Sprintf("%04d", p.Sequence), 5))
+	// Passenger checked in, without conditional items
+	b.WriteString("1")
+	b.WriteString("00")
+	return b.String(), nil
+}
+
+// passengerName keeps only the characters a boarding pass allows, names are
+// written as LAST/FIRST
+func passengerName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || r == '/' || r == ' ' {
+			return r
+		}
+		return -1
+	}, strings.ToUpper(name))
+}
+
+// flightNumber pads the number with leading zeros keeping the suffix, e.g.
+// 123 is 0123 and 45A is 0045A
+func flightNumber(number string) string {
+	if number == "" {
+		return ""
+	}
+	suffix := ""
+	last := number[len(number)-1]
+	if last < '0' || last > '9' {
+		suffix = string(last)
+		number = number[:len(number)-1]
+	}
+	return strings.Repeat("0", 4-min(len(number), 4)) + number + suffix
+}
+
+// field pads the value with spaces or truncates it to the size of the field
+func field(value string, size int) string {
+	if len(value) > size {
+		return value[:size]
+	}
+	return value + strings.Repeat(" ", size-len(value))
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
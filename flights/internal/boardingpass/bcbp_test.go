@@ -0,0 +1,95 @@
+package boardingpass
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	departure := time.Date(2020, 5, 2, 9, 5, 0, 0, time.FixedZone("", -5*60*60))
+
+	tests := []struct {
+		name    string
+		pass    Pass
+		want    string
+		wantErr error
+	}{
+		{
+			name: "Encode the mandatory items of one leg",
+			pass: Pass{
+				PassengerName: "Doe/John",
+				Locator:       "K7QM2X",
+				Origin:        "BOG",
+				Destination:   "MDE",
+				Carrier:       "AV",
+				FlightNumber:  "123",
+				Departure:     departure,
+				Seat:          model.FlightSeat{ID: "12A", Row: 12, Letter: "A", Cabin: model.CabinEconomy},
+				Sequence:      7,
+			},
+			want: "M1DOE/JOHN            EK7QM2X BOGMDEAV 0123 123Y012A0007 100",
+		},
+		{
+			name: "Encode a business seat truncating long names and keeping the flight number suffix",
+			pass: Pass{
+				PassengerName: "Rodriguez-Santamaria/Maria Jose",
+				Locator:       "AAAAAA",
+				Origin:        "BOG",
+				Destination:   "MIA",
+				Carrier:       "AV",
+				FlightNumber:  "45A",
+				Departure:     departure,
+				Seat:          model.FlightSeat{ID: "2C", Row: 2, Letter: "C", Cabin: model.CabinBusiness},
+				Sequence:      12,
+			},
+			want: "M1RODRIGUEZSANTAMARIA/EAAAAAA BOGMIAAV 0045A123J002C0012 100",
+		},
+		{
+			name: "Fail because the flight has no route",
+			pass: Pass{
+				PassengerName: "Doe/John",
+				Locator:       "K7QM2X",
+				Carrier:       "AV",
+				Departure:     departure,
+				Seat:          model.FlightSeat{ID: "12A", Row: 12, Letter: "A"},
+			},
+			wantErr: ErrIncompletePass,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(tt.pass)
+
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.want, got)
+			if err == nil {
+				require.Len(t, got, 60)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	bcbp := "M1DOE/JOHN            EK7QM2X BOGMDEAV 0123 123Y012A0007 100"
+
+	for _, format := range []string{BarcodeQR, BarcodePDF417} {
+		t.Run(format, func(t *testing.T) {
+			got, err := Render(bcbp, format)
+			require.NoError(t, err)
+
+			img, err := png.Decode(bytes.NewReader(got))
+			require.NoError(t, err)
+			require.NotZero(t, img.Bounds().Dx())
+			require.NotZero(t, img.Bounds().Dy())
+		})
+	}
+
+	_, err := Render(bcbp, "aztec")
+	require.Equal(t, ErrUnknownBarcode, err)
+}
@@ -0,0 +1,54 @@
+package checkin
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCheckInNotOpen = errors.New("check_in_not_open")
+	ErrCheckInClosed  = errors.New("check_in_closed")
+	ErrInvalidWindow  = errors.New("invalid_check_in_window")
+)
+
+// Window is when passengers can check in, from Opens to Closes before the
+// departure, e.g. from 24h to 1h before
+type Window struct {
+	Opens  time.Duration
+	Closes time.Duration
+}
+
+// ParseWindow reads a window from durations like "24h" and "1h"
+func ParseWindow(opens string, closes string) (Window, error) {
+	opensBefore, err := time.ParseDuration(opens)
+	if err != nil {
+		return Window{}, err
+	}
+	closesBefore, err := time.ParseDuration(closes)
+	if err != nil {
+		return Window{}, err
+	}
+	if closesBefore < 0 || opensBefore <= closesBefore {
+		return Window{}, ErrInvalidWindow
+	}
+	return Window{
+		Opens:  opensBefore,
+		Closes: closesBefore,
+	}, nil
+}
+
+// Check tells if a passenger can check in now
+func (w Window) Check(departure time.Time, now time.Time) error {
+	if now.Before(departure.Add(-w.Opens)) {
+		return ErrCheckInNotOpen
+	}
+	if w.IsClosed(departure, now) {
+		return ErrCheckInClosed
+	}
+	return nil
+}
+
+// IsClosed tells if check-in already closed, seats can not be changed anymore
+func (w Window) IsClosed(departure time.Time, now time.Time) bool {
+	return !now.Before(departure.Add(-w.Closes))
+}
@@ -0,0 +1,98 @@
+package checkin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow_Check(t *testing.T) {
+	departure := time.Date(2020, 5, 2, 10, 0, 0, 0, time.UTC)
+	window := Window{
+		Opens:  24 * time.Hour,
+		Closes: time.Hour,
+	}
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:    "Fail because check-in is not open yet",
+			now:     time.Date(2020, 5, 1, 9, 59, 59, 0, time.UTC),
+			wantErr: ErrCheckInNotOpen,
+		},
+		{
+			name: "Check in as soon as it opens",
+			now:  time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Check in right before it closes",
+			now:  time.Date(2020, 5, 2, 8, 59, 59, 0, time.UTC),
+		},
+		{
+			name:    "Fail because check-in is closed",
+			now:     time.Date(2020, 5, 2, 9, 0, 0, 0, time.UTC),
+			wantErr: ErrCheckInClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := window.Check(departure, tt.now)
+
+			// Assert
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		opens   string
+		closes  string
+		want    Window
+		wantErr bool
+	}{
+		{
+			name:   "Parse a window from 24 to 1 hours before departure",
+			opens:  "24h",
+			closes: "1h",
+			want: Window{
+				Opens:  24 * time.Hour,
+				Closes: time.Hour,
+			},
+		},
+		{
+			name:    "Fail because it closes before it opens",
+			opens:   "1h",
+			closes:  "24h",
+			wantErr: true,
+		},
+		{
+			name:    "Fail because of an invalid duration",
+			opens:   "a day",
+			closes:  "1h",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := ParseWindow(tt.opens, tt.closes)
+
+			// Assert
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package itinerary joins flights into trips with connections
+package itinerary
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+var (
+	ErrInvalidItinerary   = errors.New("invalid_itinerary")
+	ErrConnectionTooShort = errors.New("connection_too_short")
+)
+
+// MaxLegs is the most flights an itinerary can have
+const MaxLegs = 4
+
+// Connection is a trip with one stop, Departure is the one of the first leg
+// and Arrival the one of the last
+type Connection struct {
+	Legs      []model.Flight
+	Departure time.Time
+	Arrival   time.Time
+}
+
+type AirportCatalog interface {
+	Find(code string) (airport.Airport, error)
+}
+
+// Arrival returns when the flight arrives, its departure plus the block time.
+// Flights without one get the estimated block time between their airports
+func Arrival(flight model.Flight, airports AirportCatalog) (time.Time, error) {
+	departure, err := model.ParseDeparture(flight.Departure)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if flight.BlockMinutes > 0 {
+		return departure.Add(time.Duration(flight.BlockMinutes) * time.Minute), nil
+	}
+
+	origin, err := airports.Find(flight.Origin)
+	if err != nil {
+		return time.Time{}, err
+	}
+	destination, err := airports.Find(flight.Destination)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return departure.Add(airport.EstimateBlockTime(origin, destination)), nil
+}
+
+// Validate checks the legs make one trip in order, every leg departs from the
+// airport the previous one arrives at at least the minimum connection time
+// after it. The error tells the first invalid leg, starting at 1
+func Validate(legs []model.Flight, airports AirportCatalog, minConnection time.Duration) error {
+	if len(legs) < 2 || len(legs) > MaxLegs {
+		return fmt.Errorf("%w: legs", ErrInvalidItinerary)
+	}
+
+	flightIDs := map[string]bool{}
+	for i, leg := range legs {
+		if flightIDs[leg.ID] || leg.Origin == "" || leg.Destination == "" {
+			return fmt.Errorf("%w: leg %d", ErrInvalidItinerary, i+1)
+		}
+		flightIDs[leg.ID] = true
+		if i == 0 {
+			continue
+		}
+
+		previous := legs[i-1]
+		if leg.Origin != previous.Destination {
+			return fmt.Errorf("%w: leg %d", ErrInvalidItinerary, i+1)
+		}
+		arrival, err := Arrival(previous, airports)
+		if err != nil {
+			return err
+		}
+		departure, err := model.ParseDeparture(leg.Departure)
+		if err != nil {
+			return err
+		}
+		if departure.Sub(arrival) < minConnection {
+			return fmt.Errorf("%w: leg %d", ErrConnectionTooShort, i+1)
+		}
+	}
+	return nil
+}
+
+// Connections pairs the flights of first with the ones of second departing
+// from the airport they arrive at between minConnection and maxConnection
+// after their arrival. Round trips and flights whose arrival is unknown are
+// left out, the shortest trips come first and then the earliest
+func Connections(first []model.Flight, second []model.Flight, airports AirportCatalog, minConnection time.Duration, maxConnection time.Duration) []Connection {
+	connections := []Connection{}
+	for _, inbound := range first {
+		departure, err := model.ParseDeparture(inbound.Departure)
+		if err != nil {
+			continue
+		}
+		arrival, err := Arrival(inbound, airports)
+		if err != nil {
+			continue
+		}
+
+		for _, outbound := range second {
+			if outbound.ID == inbound.ID ||
+				outbound.Origin != inbound.Destination ||
+				outbound.Destination == inbound.Origin {
+				continue
+			}
+			outboundDeparture, err := model.ParseDeparture(outbound.Departure)
+			if err != nil {
+				continue
+			}
+			connection := outboundDeparture.Sub(arrival)
+			if connection < minConnection || connection > maxConnection {
+				continue
+			}
+			outboundArrival, err := Arrival(outbound, airports)
+			if err != nil {
+				continue
+			}
+
+			connections = append(connections, Connection{
+				Legs:      []model.Flight{inbound, outbound},
+				Departure: departure,
+				Arrival:   outboundArrival,
+			})
+		}
+	}
+
+	sort.SliceStable(connections, func(i, j int) bool {
+		if connections[i].TravelTime() != connections[j].TravelTime() {
+			return connections[i].TravelTime() < connections[j].TravelTime()
+		}
+		return connections[i].Departure.Before(connections[j].Departure)
+	})
+	return connections
+}
+
+// TravelTime is the time from the departure of the first leg to the arrival of
+// the last one, connections included
+func (c Connection) TravelTime() time.Duration {
+	return c.Arrival.Sub(c.Departure)
+}
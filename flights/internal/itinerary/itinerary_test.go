@@ -0,0 +1,106 @@
+package itinerary
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	airports, err := airport.DefaultCatalog()
+	require.NoError(t, err)
+
+	// BOG-MDE is estimated in 50 minutes
+	toMedellin := model.Flight{ID: "f1", Departure: "2020-05-01T11:30:00Z", Origin: "BOG", Destination: "MDE"}
+	toCartagena := model.Flight{ID: "f2", Departure: "2020-05-01T13:30:00Z", Origin: "MDE", Destination: "CTG", BlockMinutes: 70}
+	toMiami := model.Flight{ID: "f3", Departure: "2020-05-01T15:30:00Z", Origin: "CTG", Destination: "MIA", BlockMinutes: 155}
+
+	tests := []struct {
+		name          string
+		legs          []model.Flight
+		minConnection time.Duration
+		wantErr       error
+	}{
+		{
+			name:          "Legs connecting with the minimum connection time",
+			legs:          []model.Flight{toMedellin, toCartagena, toMiami},
+			minConnection: 50 * time.Minute,
+		},
+		{
+			name:          "Fail because a leg departs before the minimum connection time",
+			legs:          []model.Flight{toMedellin, toCartagena, toMiami},
+			minConnection: time.Hour,
+			wantErr:       fmt.Errorf("%w: leg 3", ErrConnectionTooShort),
+		},
+		{
+			name:          "Fail because of a direct flight",
+			legs:          []model.Flight{toMedellin},
+			minConnection: time.Hour,
+			wantErr:       fmt.Errorf("%w: legs", ErrInvalidItinerary),
+		},
+		{
+			name:          "Fail because a leg departs from another airport",
+			legs:          []model.Flight{toMedellin, toMiami},
+			minConnection: time.Hour,
+			wantErr:       fmt.Errorf("%w: leg 2", ErrInvalidItinerary),
+		},
+		{
+			name:          "Fail because a flight is repeated",
+			legs:          []model.Flight{toMedellin, toMedellin},
+			minConnection: time.Hour,
+			wantErr:       fmt.Errorf("%w: leg 2", ErrInvalidItinerary),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := Validate(tt.legs, airports, tt.minConnection)
+
+			// Assert
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr.Error())
+		})
+	}
+}
+
+func TestConnections(t *testing.T) {
+	airports, err := airport.DefaultCatalog()
+	require.NoError(t, err)
+
+	toMedellin := model.Flight{ID: "a1", Departure: "2020-05-01T11:30:00Z", Origin: "BOG", Destination: "MDE", BlockMinutes: 50}
+	toCali := model.Flight{ID: "a2", Departure: "2020-05-01T10:00:00Z", Origin: "BOG", Destination: "CLO", BlockMinutes: 55}
+	earlyToMedellin := model.Flight{ID: "a3", Departure: "2020-05-01T06:00:00Z", Origin: "BOG", Destination: "MDE", BlockMinutes: 50}
+	first := []model.Flight{toMedellin, toCali, earlyToMedellin}
+
+	fromMedellin := model.Flight{ID: "b1", Departure: "2020-05-01T14:00:00Z", Origin: "MDE", Destination: "CTG", BlockMinutes: 70}
+	tightFromCali := model.Flight{ID: "b2", Departure: "2020-05-01T11:20:00Z", Origin: "CLO", Destination: "CTG", BlockMinutes: 90}
+	fromCali := model.Flight{ID: "b3", Departure: "2020-05-01T12:00:00Z", Origin: "CLO", Destination: "CTG", BlockMinutes: 90}
+	backToBogota := model.Flight{ID: "b4", Departure: "2020-05-01T14:00:00Z", Origin: "MDE", Destination: "BOG", BlockMinutes: 50}
+	earlyFromMedellin := model.Flight{ID: "b5", Departure: "2020-05-01T09:00:00Z", Origin: "MDE", Destination: "CTG", BlockMinutes: 70}
+	second := []model.Flight{fromMedellin, tightFromCali, fromCali, backToBogota, earlyFromMedellin}
+
+	// Act
+	connections := Connections(first, second, airports, 45*time.Minute, 6*time.Hour)
+
+	// Assert
+	legs := [][]string{}
+	travelTimes := []time.Duration{}
+	for _, c := range connections {
+		legs = append(legs, []string{c.Legs[0].ID, c.Legs[1].ID})
+		travelTimes = append(travelTimes, c.TravelTime())
+	}
+	require.Equal(t, [][]string{{"a2", "b3"}, {"a1", "b1"}, {"a3", "b5"}}, legs)
+	require.Equal(t, []time.Duration{
+		3*time.Hour + 30*time.Minute,
+		3*time.Hour + 40*time.Minute,
+		4*time.Hour + 10*time.Minute,
+	}, travelTimes)
+}
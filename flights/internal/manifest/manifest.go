@@ -0,0 +1,142 @@
+package manifest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown_format")
+)
+
+var csvHeader = []string{
+	"row",
+	"letter",
+	"seat_id",
+	"cabin",
+	"passenger_id",
+	"locator",
+	"checked_in",
+	"checked_in_at",
+	"special_requests",
+}
+
+type Manifest struct {
+	FlightID    string      `json:"flight_id"`
+	Departure   string      `json:"departure"`
+	Origin      string      `json:"origin"`
+	Destination string      `json:"destination"`
+	Status      string      `json:"status"`
+	Passengers  []Passenger `json:"passengers"`
+}
+
+type Passenger struct {
+	SeatID          string   `json:"seat_id"`
+	Row             int      `json:"row"`
+	Letter          string   `json:"letter"`
+	Cabin           string   `json:"cabin"`
+	PassengerID     string   `json:"passenger_id"`
+	Locator         string   `json:"locator"`
+	CheckedIn       bool     `json:"checked_in"`
+	CheckedInAt     string   `json:"checked_in_at"`
+	SpecialRequests []string `json:"special_requests"`
+}
+
+// Build lists the passengers with a confirmed seat sorted by row and letter,
+// held seats are left out
+func Build(flight model.Flight) Manifest {
+	passengers := []Passenger{}
+	for _, s := range flight.Seats {
+		if s.PassengerID == "" || s.HeldUntil != "" {
+			continue
+		}
+		specialRequests := s.SpecialRequests
+		if specialRequests == nil {
+			specialRequests = []string{}
+		}
+		passengers = append(passengers, Passenger{
+			SeatID:          s.ID,
+			Row:             s.Row,
+			Letter:          s.Letter,
+			Cabin:           s.Cabin,
+			PassengerID:     s.PassengerID,
+			Locator:         s.Locator,
+			CheckedIn:       s.CheckedInAt != "",
+			CheckedInAt:     s.CheckedInAt,
+			SpecialRequests: specialRequests,
+		})
+	}
+	sort.Slice(passengers, func(i, j int) bool {
+		if passengers[i].Row != passengers[j].Row {
+			return passengers[i].Row < passengers[j].Row
+		}
+		return passengers[i].Letter < passengers[j].Letter
+	})
+
+	return Manifest{
+		FlightID:    flight.ID,
+		Departure:   flight.Departure,
+		Origin:      flight.Origin,
+		Destination: flight.Destination,
+		Status:      flight.CurrentStatus(),
+		Passengers:  passengers,
+	}
+}
+
+// IsFormat tells if a manifest can be exported in the format
+func IsFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON
+}
+
+// ContentType is the media type of the format
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+// Write exports the manifest as CSV, one line per passenger, or as JSON
+func Write(w io.Writer, m Manifest, format string) error {
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(m)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		err := writer.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+		for _, p := range m.Passengers {
+			err = writer.Write([]string{
+				strconv.Itoa(p.Row),
+				p.Letter,
+				p.SeatID,
+				p.Cabin,
+				p.PassengerID,
+				p.Locator,
+				strconv.FormatBool(p.CheckedIn),
+				p.CheckedInAt,
+				strings.Join(p.SpecialRequests, " "),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return ErrUnknownFormat
+}
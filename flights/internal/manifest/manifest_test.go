@@ -0,0 +1,112 @@
+package manifest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	flight := model.Flight{
+		ID:          "f1",
+		Departure:   "2020-05-01T00:00:00+0000",
+		Origin:      "BOG",
+		Destination: "MDE",
+		Seats: []model.FlightSeat{
+			{ID: "10B", Row: 10, Letter: "B", Cabin: model.CabinEconomy, PassengerID: "p3", Locator: "CCCCCC"},
+			{ID: "2A", Row: 2, Letter: "A", Cabin: model.CabinBusiness, PassengerID: "p1", Locator: "AAAAAA",
+				CheckedInAt: "2020-04-30T10:00:00Z", SpecialRequests: []string{"WCHR", "VGML"}},
+			{ID: "10A", Row: 10, Letter: "A", Cabin: model.CabinEconomy, PassengerID: "p2", Locator: "BBBBBB"},
+			{ID: "10C", Row: 10, Letter: "C", Cabin: model.CabinEconomy},
+			{ID: "11A", Row: 11, Letter: "A", Cabin: model.CabinEconomy, PassengerID: "p4", HeldUntil: "2020-04-30T10:10:00Z"},
+		},
+	}
+
+	got := Build(flight)
+
+	want := Manifest{
+		FlightID:    "f1",
+		Departure:   "2020-05-01T00:00:00+0000",
+		Origin:      "BOG",
+		Destination: "MDE",
+		Status:      model.FlightStatusScheduled,
+		Passengers: []Passenger{
+			{SeatID: "2A", Row: 2, Letter: "A", Cabin: model.CabinBusiness, PassengerID: "p1", Locator: "AAAAAA",
+				CheckedIn: true, CheckedInAt: "2020-04-30T10:00:00Z", SpecialRequests: []string{"WCHR", "VGML"}},
+			{SeatID: "10A", Row: 10, Letter: "A", Cabin: model.CabinEconomy, PassengerID: "p2", Locator: "BBBBBB",
+				SpecialRequests: []string{}},
+			{SeatID: "10B", Row: 10, Letter: "B", Cabin: model.CabinEconomy, PassengerID: "p3", Locator: "CCCCCC",
+				SpecialRequests: []string{}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Differences found: (-want,+got)\n%s", diff)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	m := Manifest{
+		FlightID:    "f1",
+		Departure:   "2020-05-01T00:00:00+0000",
+		Origin:      "BOG",
+		Destination: "MDE",
+		Status:      model.FlightStatusScheduled,
+		Passengers: []Passenger{
+			{SeatID: "2A", Row: 2, Letter: "A", Cabin: model.CabinBusiness, PassengerID: "p1", Locator: "AAAAAA",
+				CheckedIn: true, CheckedInAt: "2020-04-30T10:00:00Z", SpecialRequests: []string{"WCHR", "VGML"}},
+			{SeatID: "10A", Row: 10, Letter: "A", Cabin: model.CabinEconomy, PassengerID: "p2", Locator: "BBBBBB",
+				SpecialRequests: []string{}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "Export as CSV",
+			format: FormatCSV,
+			want: "row,letter,seat_id,cabin,passenger_id,locator,checked_in,checked_in_at,special_requests\n" +
+				"2,A,2A,business,p1,AAAAAA,true,2020-04-30T10:00:00Z,WCHR VGML\n" +
+				"10,A,10A,economy,p2,BBBBBB,false,,\n",
+		},
+		{
+			name:   "Export as JSON",
+			format: FormatJSON,
+			want: internal.TrimLines(`{
+				"flight_id":"f1",
+				"departure":"2020-05-01T00:00:00+0000",
+				"origin":"BOG",
+				"destination":"MDE",
+				"status":"scheduled",
+				"passengers":[
+					{"seat_id":"2A","row":2,"letter":"A","cabin":"business","passenger_id":"p1","locator":"AAAAAA",
+					"checked_in":true,"checked_in_at":"2020-04-30T10:00:00Z","special_requests":["WCHR","VGML"]},
+					{"seat_id":"10A","row":10,"letter":"A","cabin":"economy","passenger_id":"p2","locator":"BBBBBB",
+					"checked_in":false,"checked_in_at":"","special_requests":[]}
+				]
+			}`) + "\n",
+		},
+		{
+			name:    "Fail on an unknown format",
+			format:  "xml",
+			wantErr: ErrUnknownFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			err := Write(&buf, m, tt.format)
+
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// DepartureLayout is how departures are stored, RFC 3339 in UTC so they sort
+// like the times they represent
+const DepartureLayout = time.RFC3339
+
+// legacyDepartureLayout is how departures were stored before, with a numeric
+// offset like 2020-05-01T00:00:00+0000
+const legacyDepartureLayout = "2006-01-02T15:04:05-0700"
+
+// ParseDeparture reads a departure in RFC 3339 or in the legacy layout
+func ParseDeparture(departure string) (time.Time, error) {
+	t, err := time.Parse(DepartureLayout, departure)
+	if err != nil {
+		t, err = time.Parse(legacyDepartureLayout, departure)
+	}
+	return t, err
+}
+
+// FormatDeparture writes a departure the way it is stored
+func FormatDeparture(departure time.Time) string {
+	return departure.UTC().Format(DepartureLayout)
+}
+
+// NormalizeDeparture rewrites a departure with any offset the way it is
+// stored, e.g. 2020-05-01T07:00:00-05:00 is 2020-05-01T12:00:00Z
+func NormalizeDeparture(departure string) (string, error) {
+	t, err := ParseDeparture(departure)
+	if err != nil {
+		return "", err
+	}
+	return FormatDeparture(t), nil
+}
@@ -1,15 +1,76 @@
 package model
 
+import "time"
+
+const (
+	SeatPositionWindow = "window"
+	SeatPositionMiddle = "middle"
+	SeatPositionAisle  = "aisle"
+)
+
+const (
+	CabinEconomy        = "economy"
+	CabinPremiumEconomy = "premium_economy"
+	CabinBusiness       = "business"
+	CabinFirst          = "first"
+)
+
 type Flight struct {
-	ID           string       `json:"id"`
-	Departure    string       `json:"departure"`
-	HasFreeSeats bool         `json:"has_free_seats"`
-	Seats        []FlightSeat `json:"seats"`
+	ID            string               `json:"id"`
+	Departure     string               `json:"departure"`
+	Origin        string               `json:"origin"`
+	Destination   string               `json:"destination"`
+	AircraftType  string               `json:"aircraft_type"`
+	HasFreeSeats  bool                 `json:"has_free_seats"`
+	Fares         Fares                `json:"fares"`
+	Seats         []FlightSeat         `json:"seats"`
+	Status        string               `json:"status"`
+	StatusHistory []FlightStatusChange `json:"status_history"`
+	Version       int                  `json:"version"`
+	// OriginTimeZone is the IANA time zone of the origin, e.g. America/Bogota
+	OriginTimeZone string `json:"origin_time_zone"`
+	// Carrier and FlightNumber are the operating flight, e.g. AV and 123
+	Carrier      string `json:"carrier"`
+	FlightNumber string `json:"flight_number"`
+	// OperatingDate is the local date of the scheduled departure at the
+	// origin, delays do not change it
+	OperatingDate string `json:"operating_date"`
+	// MarketingFlights are the codeshare flight numbers sold for this flight
+	MarketingFlights []MarketingFlight `json:"marketing_flights"`
+	// BlockMinutes is the scheduled time from the departure to the arrival, a
+	// delay moves both. Flights without it have an estimated one
+	BlockMinutes int `json:"block_minutes"`
+	// CheckInSequence is the check-in sequence number last given, boarding
+	// passes number the passengers in the order they checked in
+	CheckInSequence int `json:"check_in_sequence"`
 }
 
 type FlightSeat struct {
-	ID          string `json:"id"`
-	Letter      string `json:"letter"`
-	PassengerID string `json:"passenger_id"`
-	Row         int    `json:"row"`
+	ID           string `json:"id"`
+	Letter       string `json:"letter"`
+	PassengerID  string `json:"passenger_id"`
+	Row          int    `json:"row"`
+	Position     string `json:"position"`
+	Cabin        string `json:"cabin"`
+	ExitRow      bool   `json:"exit_row"`
+	ExtraLegroom bool   `json:"extra_legroom"`
+	Price        Price  `json:"price"`
+	PaymentID    string `json:"payment_id"`
+	HeldUntil    string `json:"held_until"`
+	Locator      string `json:"locator"`
+	CheckedInAt  string `json:"checked_in_at"`
+	// SpecialRequests are the IATA SSR codes of the passenger, e.g. WCHR
+	SpecialRequests []string `json:"special_requests"`
+	// AisleAfter tells an aisle separates the seat from the next one of its
+	// row, e.g. 1A in an A-DG-K layout
+	AisleAfter bool `json:"aisle_after"`
+}
+
+// IsFree tells if the seat can be taken at the given time, a seat held until
+// before then is free again
+func (s FlightSeat) IsFree(now time.Time) bool {
+	if s.PassengerID == "" {
+		return true
+	}
+	return s.HeldUntil != "" && s.HeldUntil < now.UTC().Format(time.RFC3339)
 }
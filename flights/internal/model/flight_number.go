@@ -0,0 +1,100 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	// The Lambda runtime has no time zone database, the binaries embed it
+	_ "time/tzdata"
+)
+
+// OperatingDateLayout is the layout of the operating date of a flight
+const OperatingDateLayout = "2006-01-02"
+
+// MarketingFlight is a flight number another carrier sells a flight with
+type MarketingFlight struct {
+	Carrier      string `json:"carrier"`
+	FlightNumber string `json:"flight_number"`
+}
+
+// Designator is the carrier followed by the flight number, e.g. AV123
+func Designator(carrier string, flightNumber string) string {
+	return carrier + flightNumber
+}
+
+// ParseDesignator splits a designator like AV123, AV 0123 or AV45A in the
+// carrier and the flight number without leading zeros. Carriers are two
+// letters or digits, not both digits, and numbers have up to four digits and
+// an optional suffix letter
+func ParseDesignator(designator string) (string, string, bool) {
+	designator = strings.ToUpper(strings.Replace(designator, " ", "", -1))
+	if len(designator) < 3 {
+		return "", "", false
+	}
+	carrier, flightNumber := designator[:2], designator[2:]
+	return carrier, strings.TrimLeft(flightNumber, "0"), ValidCarrier(carrier) && ValidFlightNumber(flightNumber)
+}
+
+// ValidCarrier tells if the code is an IATA airline designator, e.g. AV or 2K
+func ValidCarrier(carrier string) bool {
+	if len(carrier) != 2 || (isDigit(carrier[0]) && isDigit(carrier[1])) {
+		return false
+	}
+	for i := 0; i < len(carrier); i++ {
+		if !isDigit(carrier[i]) && (carrier[i] < 'A' || carrier[i] > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidFlightNumber tells if the number has one to four digits, not all zeros,
+// and an optional suffix letter, e.g. 123 or 45A
+func ValidFlightNumber(flightNumber string) bool {
+	digits := flightNumber
+	if len(digits) > 0 && digits[len(digits)-1] >= 'A' && digits[len(digits)-1] <= 'Z' {
+		digits = digits[:len(digits)-1]
+	}
+	if len(digits) == 0 || len(digits) > 4 || strings.Trim(digits, "0") == "" {
+		return false
+	}
+	for i := 0; i < len(digits); i++ {
+		if !isDigit(digits[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// IsTimeZone tells if the name is a known IANA time zone. The empty name and
+// Local are not, they are UTC and the zone of the machine running the code
+func IsTimeZone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// Location returns the time zone of the origin, flights without one are in
+// UTC. The time zone is checked when the flight is written
+func (f Flight) Location() *time.Location {
+	location, err := time.LoadLocation(f.OriginTimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// LocalDate returns the date of the departure at the origin, e.g. 2020-05-01
+func (f Flight) LocalDate() (string, error) {
+	departure, err := ParseDeparture(f.Departure)
+	if err != nil {
+		return "", err
+	}
+	return departure.In(f.Location()).Format(OperatingDateLayout), nil
+}
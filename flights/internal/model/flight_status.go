@@ -0,0 +1,57 @@
+package model
+
+const (
+	FlightStatusScheduled = "scheduled"
+	FlightStatusDelayed   = "delayed"
+	FlightStatusBoarding  = "boarding"
+	FlightStatusDeparted  = "departed"
+	FlightStatusCancelled = "cancelled"
+)
+
+type FlightStatusChange struct {
+	Status    string `json:"status"`
+	Departure string `json:"departure"`
+	Reason    string `json:"reason"`
+	ChangedAt string `json:"changed_at"`
+}
+
+var flightStatusTransitions = map[string][]string{
+	FlightStatusScheduled: {FlightStatusDelayed, FlightStatusBoarding, FlightStatusCancelled},
+	FlightStatusDelayed:   {FlightStatusDelayed, FlightStatusBoarding, FlightStatusCancelled},
+	FlightStatusBoarding:  {FlightStatusDelayed, FlightStatusDeparted, FlightStatusCancelled},
+	FlightStatusDeparted:  {},
+	FlightStatusCancelled: {},
+}
+
+// CurrentStatus returns the status of the flight, flights created before
+// statuses existed are scheduled
+func (f Flight) CurrentStatus() string {
+	if f.Status == "" {
+		return FlightStatusScheduled
+	}
+	return f.Status
+}
+
+// IsBookable tells if seats of the flight can still be held or reserved
+func (f Flight) IsBookable() bool {
+	status := f.CurrentStatus()
+	return status != FlightStatusDeparted && status != FlightStatusCancelled
+}
+
+// CanChangeStatus tells if a flight can go from one status to the other
+func CanChangeStatus(from string, to string) bool {
+	if from == "" {
+		from = FlightStatusScheduled
+	}
+	for _, s := range flightStatusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+func IsFlightStatus(status string) bool {
+	_, ok := flightStatusTransitions[status]
+	return ok
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	SurchargeExitRow      = "exit_row"
+	SurchargeExtraLegroom = "extra_legroom"
+)
+
+// Price amounts are in the minor unit of the currency, e.g. cents
+type Price struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// Fares of a flight, Cabins has the base fare of every cabin and Surcharges
+// the extra charged for a seat position (window, middle, aisle), exit row or
+// extra legroom
+type Fares struct {
+	Currency   string           `json:"currency"`
+	Cabins     map[string]int64 `json:"cabins"`
+	Surcharges map[string]int64 `json:"surcharges"`
+}
+
+// minorUnits has the ISO 4217 currencies whose minor unit is not the
+// hundredth, the amounts of the rest have 2 decimals
+var minorUnits = map[string]int{
+	"BIF": 0,
+	"CLP": 0,
+	"DJF": 0,
+	"GNF": 0,
+	"ISK": 0,
+	"JPY": 0,
+	"KMF": 0,
+	"KRW": 0,
+	"PYG": 0,
+	"RWF": 0,
+	"UGX": 0,
+	"UYI": 0,
+	"VND": 0,
+	"VUV": 0,
+	"XAF": 0,
+	"XOF": 0,
+	"XPF": 0,
+	"BHD": 3,
+	"IQD": 3,
+	"JOD": 3,
+	"KWD": 3,
+	"LYD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"CLF": 4,
+	"UYW": 4,
+}
+
+// decimals is the number of decimals of the minor unit of the currency
+func decimals(currency string) int {
+	if decimals, ok := minorUnits[currency]; ok {
+		return decimals
+	}
+	return 2
+}
+
+func (p Price) String() string {
+	digits := decimals(p.Currency)
+	if digits == 0 {
+		return fmt.Sprintf("%v %d", p.Currency, p.Amount)
+	}
+	unit := int64(math.Pow10(digits))
+	return fmt.Sprintf("%v %d.%0*d", p.Currency, p.Amount/unit, digits, p.Amount%unit)
+}
@@ -0,0 +1,21 @@
+package model
+
+const (
+	QueueMsgTypeReservedSeat   = "reserved_seat"
+	QueueMsgTypeSeatChanged    = "seat_changed"
+	QueueMsgTypeWaitlistOffer  = "waitlist_offer"
+	QueueMsgTypeFlightStatus   = "flight_status_changed"
+	QueueMsgTypeReaccommodated = "reaccommodated"
+	QueueMsgTypeBoardingPass   = "boarding_pass"
+	QueueMsgTypeItinerary      = "itinerary_reserved"
+
+	QueueMsgTypeSeatReleased         = "seat_released"
+	QueueMsgTypeWaitlistOfferExpired = "waitlist_offer_expired"
+	QueueMsgTypeSeatHoldExpired      = "seat_hold_expired"
+)
+
+// QueueMsg has the fields every queue message shares, notification messages
+// without a type are reserved seat messages
+type QueueMsg struct {
+	Type string `json:"type"`
+}
@@ -0,0 +1,13 @@
+package model
+
+type QueueMsgBoardingPass struct {
+	Type              string `json:"type"`
+	Locator           string `json:"locator"`
+	FlightID          string `json:"flight_id"`
+	FlightDeparture   string `json:"flight_departure"`
+	FlightOrigin      string `json:"flight_origin"`
+	FlightDestination string `json:"flight_destination"`
+	SeatID            string `json:"seat_id"`
+	UserID            string `json:"user_id"`
+	BCBP              string `json:"bcbp"`
+}
@@ -0,0 +1,12 @@
+package model
+
+type QueueMsgFlightStatus struct {
+	Type              string `json:"type"`
+	FlightID          string `json:"flight_id"`
+	FlightDeparture   string `json:"flight_departure"`
+	FlightOrigin      string `json:"flight_origin"`
+	FlightDestination string `json:"flight_destination"`
+	Status            string `json:"status"`
+	Reason            string `json:"reason"`
+	UserID            string `json:"user_id"`
+}
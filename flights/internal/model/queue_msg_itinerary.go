@@ -0,0 +1,21 @@
+package model
+
+// QueueMsgItinerary confirms the seats reserved on every leg of a trip, Price
+// is the total paid for all of them
+type QueueMsgItinerary struct {
+	Type            string            `json:"type"`
+	Locator         string            `json:"locator"`
+	UserID          string            `json:"user_id"`
+	Legs            []QueueMsgLegSeat `json:"legs"`
+	Price           Price             `json:"price"`
+	SpecialRequests []string          `json:"special_requests"`
+}
+
+type QueueMsgLegSeat struct {
+	FlightID          string `json:"flight_id"`
+	FlightDeparture   string `json:"flight_departure"`
+	FlightOrigin      string `json:"flight_origin"`
+	FlightDestination string `json:"flight_destination"`
+	SeatLetter        string `json:"seat_letter"`
+	SeatRow           int    `json:"seat_row"`
+}
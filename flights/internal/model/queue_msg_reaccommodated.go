@@ -0,0 +1,13 @@
+package model
+
+type QueueMsgReaccommodated struct {
+	Type              string `json:"type"`
+	Locator           string `json:"locator"`
+	FromFlightID      string `json:"from_flight_id"`
+	FlightID          string `json:"flight_id"`
+	FlightDeparture   string `json:"flight_departure"`
+	FlightOrigin      string `json:"flight_origin"`
+	FlightDestination string `json:"flight_destination"`
+	SeatID            string `json:"seat_id"`
+	UserID            string `json:"user_id"`
+}
@@ -1,9 +1,15 @@
 package model
 
 type QueueMsgReservedSeat struct {
-	FlightID        string `json:"flight_id"`
-	FlightDeparture string `json:"flight_departure"`
-	SeatLetter      string `json:"seat_letter"`
-	SeatRow         int    `json:"seat_row"`
-	UserID          string `json:"user_id"`
+	Type              string   `json:"type"`
+	Locator           string   `json:"locator"`
+	FlightID          string   `json:"flight_id"`
+	FlightDeparture   string   `json:"flight_departure"`
+	FlightOrigin      string   `json:"flight_origin"`
+	FlightDestination string   `json:"flight_destination"`
+	SeatLetter        string   `json:"seat_letter"`
+	SeatRow           int      `json:"seat_row"`
+	UserID            string   `json:"user_id"`
+	Price             Price    `json:"price"`
+	SpecialRequests   []string `json:"special_requests"`
 }
@@ -0,0 +1,14 @@
+package model
+
+type QueueMsgSeatChanged struct {
+	Type              string `json:"type"`
+	Locator           string `json:"locator"`
+	FlightID          string `json:"flight_id"`
+	FlightDeparture   string `json:"flight_departure"`
+	FlightOrigin      string `json:"flight_origin"`
+	FlightDestination string `json:"flight_destination"`
+	FromSeatID        string `json:"from_seat_id"`
+	ToSeatLetter      string `json:"to_seat_letter"`
+	ToSeatRow         int    `json:"to_seat_row"`
+	UserID            string `json:"user_id"`
+}
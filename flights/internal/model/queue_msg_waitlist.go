@@ -0,0 +1,38 @@
+package model
+
+// QueueMsgSeatReleased tells the waitlist a seat of the flight is free again
+type QueueMsgSeatReleased struct {
+	Type     string `json:"type"`
+	FlightID string `json:"flight_id"`
+	SeatID   string `json:"seat_id"`
+}
+
+// QueueMsgWaitlistOfferExpired is sent delayed when a seat is offered, if the
+// passenger did not confirm by then the seat goes to the next one
+type QueueMsgWaitlistOfferExpired struct {
+	Type        string `json:"type"`
+	FlightID    string `json:"flight_id"`
+	JoinedAt    string `json:"joined_at"`
+	SeatID      string `json:"seat_id"`
+	PassengerID string `json:"passenger_id"`
+}
+
+// QueueMsgSeatHoldExpired is sent delayed when a seat is held for a payment, a
+// hold nobody confirmed or released by then frees the seat for the waitlist
+type QueueMsgSeatHoldExpired struct {
+	Type        string `json:"type"`
+	FlightID    string `json:"flight_id"`
+	SeatID      string `json:"seat_id"`
+	PassengerID string `json:"passenger_id"`
+}
+
+type QueueMsgWaitlistOffer struct {
+	Type              string `json:"type"`
+	FlightID          string `json:"flight_id"`
+	FlightDeparture   string `json:"flight_departure"`
+	FlightOrigin      string `json:"flight_origin"`
+	FlightDestination string `json:"flight_destination"`
+	SeatID            string `json:"seat_id"`
+	UserID            string `json:"user_id"`
+	ExpiresAt         string `json:"expires_at"`
+}
@@ -0,0 +1,14 @@
+package model
+
+type Reservation struct {
+	Locator     string `json:"locator"`
+	FlightID    string `json:"flight_id"`
+	SeatID      string `json:"seat_id"`
+	PassengerID string `json:"passenger_id"`
+	Departure   string `json:"departure"`
+	Price       Price  `json:"price"`
+	PaymentID   string `json:"payment_id"`
+	CreatedAt   string `json:"created_at"`
+	// SpecialRequests are the IATA SSR codes of the passenger, e.g. WCHR
+	SpecialRequests []string `json:"special_requests"`
+}
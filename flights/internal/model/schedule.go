@@ -0,0 +1,21 @@
+package model
+
+// ScheduleDateLayout is the layout of the validity dates of a schedule
+const ScheduleDateLayout = "2006-01-02"
+
+// Schedule is a flight repeated on some days of the week during its validity.
+// The departure time and the days of the week are in the time zone of the
+// origin
+type Schedule struct {
+	ID           string `json:"id"`
+	FlightNumber string `json:"flight_number"`
+	Origin       string `json:"origin"`
+	Destination  string `json:"destination"`
+	// DaysOfWeek go from 1 (Monday) to 7 (Sunday)
+	DaysOfWeek    []int  `json:"days_of_week"`
+	DepartureTime string `json:"departure_time"`
+	TimeZone      string `json:"time_zone"`
+	ValidFrom     string `json:"valid_from"`
+	ValidTo       string `json:"valid_to"`
+	AircraftType  string `json:"aircraft_type"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// SeatMove is a passenger moved from one seat to another of the same flight
+type SeatMove struct {
+	PassengerID string `json:"passenger_id"`
+	Locator     string `json:"locator"`
+	FromSeatID  string `json:"from_seat_id"`
+	ToSeatID    string `json:"to_seat_id"`
+}
@@ -0,0 +1,15 @@
+package model
+
+const (
+	WaitlistStatusWaiting = "waiting"
+	WaitlistStatusOffered = "offered"
+)
+
+type WaitlistEntry struct {
+	FlightID       string `json:"flight_id"`
+	JoinedAt       string `json:"joined_at"`
+	PassengerID    string `json:"passenger_id"`
+	Status         string `json:"status"`
+	OfferedSeatID  string `json:"offered_seat_id"`
+	OfferExpiresAt string `json:"offer_expires_at"`
+}
@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"errors"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/ssr"
+)
+
+var (
+	ErrExitRowNotConfirmed = errors.New("exit_row_eligibility_not_confirmed")
+	ErrCabinNotAllowed     = errors.New("cabin_not_allowed")
+	ErrExitRowNotAllowed   = errors.New("special_request_not_allowed_in_exit_row")
+)
+
+type Passenger struct {
+	Cabin            string
+	ExitRowConfirmed bool
+	SpecialRequests  []string
+}
+
+// CheckSeat tells if the passenger is allowed to take the seat, passengers
+// without a cabin are economy passengers
+func CheckSeat(seat model.FlightSeat, passenger Passenger) error {
+	seatCabin := seat.Cabin
+	if seatCabin == "" {
+		seatCabin = model.CabinEconomy
+	}
+	passengerCabin := passenger.Cabin
+	if passengerCabin == "" {
+		passengerCabin = model.CabinEconomy
+	}
+	if seatCabin != passengerCabin {
+		return ErrCabinNotAllowed
+	}
+
+	if seat.ExitRow && !ssr.AllowedInExitRow(passenger.SpecialRequests) {
+		return ErrExitRowNotAllowed
+	}
+	if seat.ExitRow && !passenger.ExitRowConfirmed {
+		return ErrExitRowNotConfirmed
+	}
+
+	return nil
+}
+
+// EntitledCabin is the cabin a new booking of the seat pays for, the fare of
+// the seat cabin is charged and flights without fares only sell economy
+func EntitledCabin(fares model.Fares, seat model.FlightSeat) string {
+	if len(fares.Cabins) == 0 || seat.Cabin == "" {
+		return model.CabinEconomy
+	}
+	return seat.Cabin
+}
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSeat(t *testing.T) {
+	tests := []struct {
+		name      string
+		seat      model.FlightSeat
+		passenger Passenger
+		want      error
+	}{
+		{
+			name:      "Allow an economy passenger in a seat without cabin",
+			seat:      model.FlightSeat{ID: "1A"},
+			passenger: Passenger{},
+		},
+		{
+			name:      "Allow a business passenger in a business seat",
+			seat:      model.FlightSeat{ID: "1A", Cabin: model.CabinBusiness},
+			passenger: Passenger{Cabin: model.CabinBusiness},
+		},
+		{
+			name:      "Reject an economy passenger in a business seat",
+			seat:      model.FlightSeat{ID: "1A", Cabin: model.CabinBusiness},
+			passenger: Passenger{},
+			want:      ErrCabinNotAllowed,
+		},
+		{
+			name:      "Reject a business passenger in an economy seat",
+			seat:      model.FlightSeat{ID: "20A", Cabin: model.CabinEconomy},
+			passenger: Passenger{Cabin: model.CabinBusiness},
+			want:      ErrCabinNotAllowed,
+		},
+		{
+			name:      "Reject an exit row seat without the eligibility confirmation",
+			seat:      model.FlightSeat{ID: "11A", ExitRow: true},
+			passenger: Passenger{},
+			want:      ErrExitRowNotConfirmed,
+		},
+		{
+			name:      "Allow an exit row seat with the eligibility confirmation",
+			seat:      model.FlightSeat{ID: "11A", ExitRow: true},
+			passenger: Passenger{ExitRowConfirmed: true},
+		},
+		{
+			name:      "Reject an unaccompanied minor in an exit row seat",
+			seat:      model.FlightSeat{ID: "11A", ExitRow: true},
+			passenger: Passenger{ExitRowConfirmed: true, SpecialRequests: []string{"UMNR"}},
+			want:      ErrExitRowNotAllowed,
+		},
+		{
+			name:      "Allow a special meal in an exit row seat",
+			seat:      model.FlightSeat{ID: "11A", ExitRow: true},
+			passenger: Passenger{ExitRowConfirmed: true, SpecialRequests: []string{"VGML"}},
+		},
+		{
+			name:      "Allow an unaccompanied minor outside the exit rows",
+			seat:      model.FlightSeat{ID: "12A"},
+			passenger: Passenger{SpecialRequests: []string{"UMNR"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, CheckSeat(tt.seat, tt.passenger))
+		})
+	}
+}
+
+func TestEntitledCabin(t *testing.T) {
+	fares := model.Fares{
+		Currency: "USD",
+		Cabins: map[string]int64{
+			model.CabinEconomy:  10000,
+			model.CabinBusiness: 50000,
+		},
+	}
+
+	require.Equal(t, model.CabinBusiness, EntitledCabin(fares, model.FlightSeat{ID: "1A", Cabin: model.CabinBusiness}))
+	require.Equal(t, model.CabinEconomy, EntitledCabin(fares, model.FlightSeat{ID: "20A"}))
+	require.Equal(t, model.CabinEconomy, EntitledCabin(model.Fares{}, model.FlightSeat{ID: "1A", Cabin: model.CabinBusiness}))
+}
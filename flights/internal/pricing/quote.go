@@ -0,0 +1,80 @@
+package pricing
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+var (
+	ErrNoFareForCabin = errors.New("no_fare_for_cabin")
+	ErrInvalidFares   = errors.New("invalid_fares")
+)
+
+type Quote struct {
+	Cabin         string
+	BaseFare      int64
+	SeatSurcharge int64
+	Total         int64
+	Currency      string
+}
+
+// QuoteSeat prices a seat as the base fare of its cabin plus the surcharges of
+// its attributes, flights without fares are not priced and quote zero
+func QuoteSeat(fares model.Fares, seat model.FlightSeat) (Quote, error) {
+	cabin := seat.Cabin
+	if cabin == "" {
+		cabin = model.CabinEconomy
+	}
+	if len(fares.Cabins) == 0 {
+		return Quote{Cabin: cabin}, nil
+	}
+
+	baseFare, ok := fares.Cabins[cabin]
+	if !ok {
+		return Quote{}, ErrNoFareForCabin
+	}
+
+	surcharge := fares.Surcharges[seat.Position]
+	if seat.ExitRow {
+		surcharge += fares.Surcharges[model.SurchargeExitRow]
+	}
+	if seat.ExtraLegroom {
+		surcharge += fares.Surcharges[model.SurchargeExtraLegroom]
+	}
+
+	return Quote{
+		Cabin:         cabin,
+		BaseFare:      baseFare,
+		SeatSurcharge: surcharge,
+		Total:         baseFare + surcharge,
+		Currency:      fares.Currency,
+	}, nil
+}
+
+// ValidateFares checks the currency is an ISO 4217 code and no amount is
+// negative
+func ValidateFares(fares model.Fares) error {
+	if len(fares.Currency) != 3 || strings.ToUpper(fares.Currency) != fares.Currency {
+		return ErrInvalidFares
+	}
+	if len(fares.Cabins) == 0 {
+		return ErrInvalidFares
+	}
+	for _, amounts := range []map[string]int64{fares.Cabins, fares.Surcharges} {
+		for _, amount := range amounts {
+			if amount < 0 {
+				return ErrInvalidFares
+			}
+		}
+	}
+	return nil
+}
+
+func (q Quote) Price() model.Price {
+	return model.Price{
+		Amount:   q.Total,
+		Currency: q.Currency,
+	}
+}
@@ -0,0 +1,149 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteSeat(t *testing.T) {
+	fares := model.Fares{
+		Currency: "USD",
+		Cabins: map[string]int64{
+			model.CabinEconomy:  10000,
+			model.CabinBusiness: 50000,
+		},
+		Surcharges: map[string]int64{
+			model.SeatPositionWindow:    1500,
+			model.SeatPositionAisle:     1000,
+			model.SurchargeExitRow:      2000,
+			model.SurchargeExtraLegroom: 2500,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		fares   model.Fares
+		seat    model.FlightSeat
+		want    Quote
+		wantErr error
+	}{
+		{
+			name:  "Quote a middle seat without surcharges",
+			fares: fares,
+			seat:  model.FlightSeat{ID: "20B", Position: model.SeatPositionMiddle, Cabin: model.CabinEconomy},
+			want: Quote{
+				Cabin:    model.CabinEconomy,
+				BaseFare: 10000,
+				Total:    10000,
+				Currency: "USD",
+			},
+		},
+		{
+			name:  "Quote an exit row window seat adding every surcharge",
+			fares: fares,
+			seat: model.FlightSeat{
+				ID:           "11A",
+				Position:     model.SeatPositionWindow,
+				Cabin:        model.CabinEconomy,
+				ExitRow:      true,
+				ExtraLegroom: true,
+			},
+			want: Quote{
+				Cabin:         model.CabinEconomy,
+				BaseFare:      10000,
+				SeatSurcharge: 6000,
+				Total:         16000,
+				Currency:      "USD",
+			},
+		},
+		{
+			name:  "Quote a business seat",
+			fares: fares,
+			seat:  model.FlightSeat{ID: "1C", Position: model.SeatPositionAisle, Cabin: model.CabinBusiness},
+			want: Quote{
+				Cabin:         model.CabinBusiness,
+				BaseFare:      50000,
+				SeatSurcharge: 1000,
+				Total:         51000,
+				Currency:      "USD",
+			},
+		},
+		{
+			name:    "Fail because the cabin has no fare",
+			fares:   fares,
+			seat:    model.FlightSeat{ID: "1A", Cabin: model.CabinFirst},
+			wantErr: ErrNoFareForCabin,
+		},
+		{
+			name: "Quote zero for flights without fares",
+			seat: model.FlightSeat{ID: "1A", Position: model.SeatPositionWindow},
+			want: Quote{
+				Cabin: model.CabinEconomy,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QuoteSeat(tt.fares, tt.seat)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateFares(t *testing.T) {
+	tests := []struct {
+		name  string
+		fares model.Fares
+		want  error
+	}{
+		{
+			name: "Accept fares with a currency and cabins",
+			fares: model.Fares{
+				Currency: "COP",
+				Cabins: map[string]int64{
+					model.CabinEconomy: 25000000,
+				},
+			},
+		},
+		{
+			name: "Reject a lowercase currency",
+			fares: model.Fares{
+				Currency: "cop",
+				Cabins: map[string]int64{
+					model.CabinEconomy: 25000000,
+				},
+			},
+			want: ErrInvalidFares,
+		},
+		{
+			name: "Reject fares without cabins",
+			fares: model.Fares{
+				Currency: "USD",
+			},
+			want: ErrInvalidFares,
+		},
+		{
+			name: "Reject negative surcharges",
+			fares: model.Fares{
+				Currency: "USD",
+				Cabins: map[string]int64{
+					model.CabinEconomy: 10000,
+				},
+				Surcharges: map[string]int64{
+					model.SeatPositionMiddle: -500,
+				},
+			},
+			want: ErrInvalidFares,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ValidateFares(tt.fares))
+		})
+	}
+}
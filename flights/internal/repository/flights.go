@@ -3,76 +3,479 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
 )
 
 var (
-	ErrNoFlightsFound      = errors.New("no_flights_found")
-	ErrNoSeatFoundInFlight = errors.New("no_seats_found_in_the_given_flight")
-	ErrSeatNotAvailable    = errors.New("seat_not_available")
+	ErrNoFlightsFound       = errors.New("no_flights_found")
+	ErrNoSeatFoundInFlight  = errors.New("no_seats_found_in_the_given_flight")
+	ErrSeatNotAvailable     = errors.New("seat_not_available")
+	ErrFlightAlreadyExists  = errors.New("flight_already_exists")
+	ErrDuplicatedSeatID     = errors.New("duplicated_seat_id")
+	ErrFlightHasPassengers  = errors.New("flight_has_passengers")
+	ErrStaleFlight          = errors.New("stale_flight")
+	ErrPassengerSeatRemoved = errors.New("seat_with_passenger_removed")
+	ErrSeatNotHeld          = errors.New("seat_not_held")
+	ErrLocatorTaken         = errors.New("locator_taken")
+	ErrPassengerNotInSeat   = errors.New("passenger_not_in_seat")
+	ErrFlightClosed         = errors.New("flight_closed")
+	ErrInvalidStatusChange  = errors.New("invalid_status_change")
+	ErrAlreadyCheckedIn     = errors.New("already_checked_in")
+	ErrFlightNumberTaken    = errors.New("flight_number_taken")
+	ErrSeatHoldActive       = errors.New("seat_hold_active")
+	ErrTooManySeatMoves     = errors.New("too_many_seat_moves")
+	ErrReservationNotInSeat = errors.New("reservation_not_in_seat")
+	ErrInvalidTimeZone      = errors.New("invalid_origin_time_zone")
 )
 
+// flightNumberPrefix starts the ids of the items that give a flight number of
+// an operating date to a single leg, they live in the flights table and are
+// written in the same transaction as the flight
+const flightNumberPrefix = "flight_number#"
+
+// maxTransactItems is the most items a DynamoDB transaction can write
+const maxTransactItems = 25
+
+// maxStaleAttempts bounds the retries of a seat write whose flight was changed
+// by another write between reading and writing it, the free seats of the
+// flight are counted on what was read
+const maxStaleAttempts = 3
+
+// errFlightChanged tells a seat write to read the flight again
+var errFlightChanged = errors.New("flight_changed")
+
 type FlightsRepository struct {
-	client *dynamodb.DynamoDB
-	table  string
+	client            *dynamodb.DynamoDB
+	table             string
+	reservationsTable string
 }
 
 func (r *FlightsRepository) Save(m model.Flight) (model.Flight, error) {
-	hasFreeSeats := 0
-	if m.HasFreeSeats {
-		hasFreeSeats = 1
+	err := r.validateSeats(m.Seats)
+	if err != nil {
+		return model.Flight{}, err
+	}
+	err = r.validateTimeZone(m)
+	if err != nil {
+		return model.Flight{}, err
 	}
 
-	seats := make([]*dynamodb.AttributeValue, len(m.Seats))
+	stored, err := r.Find(m.ID)
+	if err != nil && err != ErrNoFlightsFound {
+		return model.Flight{}, err
+	}
+	exists := err == nil
+
+	// A version given by the caller must match the stored one
+	if m.Version != 0 && m.Version != stored.Version {
+		return model.Flight{}, ErrStaleFlight
+	}
+
+	// Keep the passengers already assigned to the stored seats
+	assignments := map[string]model.FlightSeat{}
+	for _, s := range stored.Seats {
+		if s.PassengerID != "" {
+			assignments[s.ID] = s
+		}
+	}
+	seats := make([]model.FlightSeat, len(m.Seats))
 	for i, s := range m.Seats {
-		seats[i] = &dynamodb.AttributeValue{
-			M: map[string]*dynamodb.AttributeValue{
-				"id": {
-					S: aws.String(s.ID),
-				},
-				"letter": {
-					S: aws.String(s.Letter),
+		if assigned, ok := assignments[s.ID]; ok {
+			s = r.copyAssignment(s, assigned)
+			delete(assignments, s.ID)
+		}
+		seats[i] = s
+	}
+	if len(assignments) > 0 {
+		return model.Flight{}, ErrPassengerSeatRemoved
+	}
+	m.Seats = seats
+	m.Status = stored.Status
+	m.StatusHistory = stored.StatusHistory
+	m.CheckInSequence = stored.CheckInSequence
+	m.HasFreeSeats = m.IsBookable() && r.hasFreeSeats(seats, time.Now())
+	if stored.OperatingDate != "" {
+		m.OperatingDate = stored.OperatingDate
+	}
+	err = r.setOperatingDate(&m)
+	if err != nil {
+		return model.Flight{}, err
+	}
+
+	conditionExpression := aws.String("attribute_not_exists(id)")
+	var expressionAttributeValues map[string]*dynamodb.AttributeValue
+	if exists && stored.Version == 0 {
+		conditionExpression = aws.String("attribute_not_exists(version)")
+	}
+	if exists && stored.Version != 0 {
+		conditionExpression = aws.String("version = :version")
+		expressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":version": {
+				N: aws.String(strconv.Itoa(stored.Version)),
+			},
+		}
+	}
+	m.Version = stored.Version + 1
+
+	err = r.putFlight(&dynamodb.Put{
+		TableName:                 aws.String(r.table),
+		Item:                      r.dehydrate(m),
+		ConditionExpression:       conditionExpression,
+		ExpressionAttributeValues: expressionAttributeValues,
+	}, r.flightNumberItems(m, stored))
+	if isTransactionCanceled(err) {
+		// The cancellation reasons are not exposed, if the flight did not
+		// change it was a flight number taken by another flight
+		current, findErr := r.Find(m.ID)
+		if (findErr == ErrNoFlightsFound && !exists) || (findErr == nil && exists && current.Version == stored.Version) {
+			return model.Flight{}, ErrFlightNumberTaken
+		}
+		return model.Flight{}, ErrStaleFlight
+	}
+	if isConditionalCheckFailed(err) {
+		return model.Flight{}, ErrStaleFlight
+	}
+	if err != nil {
+		return model.Flight{}, err
+	}
+
+	return m, nil
+}
+
+// SwapAircraft replaces the seat map of the flight, the passengers must be
+// already assigned to the new seats. The flight must not have changed since it
+// was read and the reservation records of the moved passengers are updated in
+// the same transaction, so a swap moves at most 24 reservations
+func (r *FlightsRepository) SwapAircraft(m model.Flight, moves []model.SeatMove) (model.Flight, error) {
+	if !m.IsBookable() {
+		return model.Flight{}, ErrFlightClosed
+	}
+	err := r.validateSeats(m.Seats)
+	if err != nil {
+		return model.Flight{}, err
+	}
+	m.HasFreeSeats = r.hasFreeSeats(m.Seats, time.Now())
+
+	// Seats reserved before the reservations table existed have no locator
+	items := []*dynamodb.TransactWriteItem{}
+	for _, move := range moves {
+		if move.Locator == "" {
+			continue
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Update: &dynamodb.Update{
+				TableName: aws.String(r.reservationsTable),
+				Key: map[string]*dynamodb.AttributeValue{
+					"locator": {
+						S: aws.String(move.Locator),
+					},
+					"flight_id": {
+						S: aws.String(m.ID),
+					},
 				},
-				"row": {
-					N: aws.String(strconv.Itoa(s.Row)),
+				ConditionExpression: aws.String("seat_id = :fromSeatID"),
+				UpdateExpression:    aws.String("set seat_id = :toSeatID"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":fromSeatID": {
+						S: aws.String(move.FromSeatID),
+					},
+					":toSeatID": {
+						S: aws.String(move.ToSeatID),
+					},
 				},
-				"passenger_id": {
-					S: aws.String("-"),
+			},
+		})
+	}
+	if len(items) > maxTransactItems-1 {
+		return model.Flight{}, ErrTooManySeatMoves
+	}
+
+	stored := m
+	expressionAttributeValues := map[string]*dynamodb.AttributeValue{}
+	conditionExpression := "attribute_exists(id) AND " + versionCondition(stored, expressionAttributeValues)
+	if len(expressionAttributeValues) == 0 {
+		expressionAttributeValues = nil
+	}
+	m.Version++
+
+	_, err = r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: append([]*dynamodb.TransactWriteItem{
+			{
+				Put: &dynamodb.Put{
+					TableName:                 aws.String(r.table),
+					Item:                      r.dehydrate(m),
+					ConditionExpression:       aws.String(conditionExpression),
+					ExpressionAttributeValues: expressionAttributeValues,
 				},
 			},
+		}, items...),
+	})
+	if isTransactionCanceled(err) {
+		// The cancellation reasons are not exposed, if the flight did not
+		// change a reservation record is not on the seat the flight has
+		current, findErr := r.Find(m.ID)
+		if findErr == nil && current.Version == stored.Version {
+			return model.Flight{}, ErrReservationNotInSeat
+		}
+		return model.Flight{}, ErrStaleFlight
+	}
+	if err != nil {
+		return model.Flight{}, err
+	}
+
+	return m, nil
+}
+
+func (r *FlightsRepository) Create(m model.Flight) (model.Flight, error) {
+	err := r.validateSeats(m.Seats)
+	if err != nil {
+		return model.Flight{}, err
+	}
+	err = r.validateTimeZone(m)
+	if err != nil {
+		return model.Flight{}, err
+	}
+	m.HasFreeSeats = m.IsBookable() && r.hasFreeSeats(m.Seats, time.Now())
+	m.Version = 1
+	err = r.setOperatingDate(&m)
+	if err != nil {
+		return model.Flight{}, err
+	}
+
+	err = r.putFlight(&dynamodb.Put{
+		TableName:           aws.String(r.table),
+		Item:                r.dehydrate(m),
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}, r.flightNumberItems(m, model.Flight{}))
+	if isTransactionCanceled(err) {
+		// The cancellation reasons are not exposed, if the flight does not
+		// exist it was a flight number taken by another flight
+		if _, findErr := r.Find(m.ID); findErr == ErrNoFlightsFound {
+			return model.Flight{}, ErrFlightNumberTaken
 		}
+		return model.Flight{}, ErrFlightAlreadyExists
+	}
+	if isConditionalCheckFailed(err) {
+		return model.Flight{}, ErrFlightAlreadyExists
+	}
+	if err != nil {
+		return model.Flight{}, err
 	}
 
-	_, err := r.client.PutItem(&dynamodb.PutItemInput{
+	return m, nil
+}
+
+// UpdateDeparture moves the departure of the flight and of its reservations,
+// the flight must not have changed since it was read
+func (r *FlightsRepository) UpdateDeparture(id string, departure string) error {
+	flight, err := r.Find(id)
+	if err != nil {
+		return err
+	}
+
+	expressionAttributeValues := map[string]*dynamodb.AttributeValue{
+		":departure": {
+			S: aws.String(departure),
+		},
+		":zero": {
+			N: aws.String("0"),
+		},
+		":one": {
+			N: aws.String("1"),
+		},
+	}
+	err = r.updateWithReservations(&dynamodb.Update{
 		TableName: aws.String(r.table),
-		Item: map[string]*dynamodb.AttributeValue{
+		Key: map[string]*dynamodb.AttributeValue{
 			"id": {
-				S: aws.String(m.ID),
+				S: aws.String(id),
 			},
-			"departure": {
-				S: aws.String(m.Departure),
+		},
+		ConditionExpression:       aws.String(versionCondition(flight, expressionAttributeValues)),
+		UpdateExpression:          aws.String("set departure = :departure, version = if_not_exists(version, :zero) + :one"),
+		ExpressionAttributeValues: expressionAttributeValues,
+	}, r.reservationDepartures(flight, departure))
+	if isTransactionCanceled(err) {
+		return ErrStaleFlight
+	}
+
+	return err
+}
+
+func (r *FlightsRepository) UpdateFares(id string, fares model.Fares) error {
+	_, err := r.client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
 			},
-			"has_free_seats": {
-				N: aws.String(strconv.Itoa(hasFreeSeats)),
+		},
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		UpdateExpression:    aws.String("set fares = :fares, version = if_not_exists(version, :zero) + :one"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":fares": r.dehydrateFares(fares),
+			":zero": {
+				N: aws.String("0"),
 			},
-			"seats": {
-				L: seats,
+			":one": {
+				N: aws.String("1"),
 			},
 		},
 	})
+	if isConditionalCheckFailed(err) {
+		return ErrNoFlightsFound
+	}
 
+	return err
+}
+
+// UpdateStatus moves the flight to a new status and keeps the change in its
+// history, a delay sets the new departure and a cancellation closes the flight
+func (r *FlightsRepository) UpdateStatus(id string, change model.FlightStatusChange) (model.Flight, error) {
+	flight, err := r.Find(id)
 	if err != nil {
 		return model.Flight{}, err
 	}
+	if !model.CanChangeStatus(flight.Status, change.Status) {
+		return model.Flight{}, ErrInvalidStatusChange
+	}
+	if change.Departure == "" {
+		change.Departure = flight.Departure
+	}
 
-	return m, nil
+	updateExpression := "set #status = :status, status_history = list_append(if_not_exists(status_history, :empty), :changes), " +
+		"departure = :departure, version = if_not_exists(version, :zero) + :one"
+	expressionAttributeValues := map[string]*dynamodb.AttributeValue{
+		":status": {
+			S: aws.String(change.Status),
+		},
+		":empty": {
+			L: []*dynamodb.AttributeValue{},
+		},
+		":changes": {
+			L: []*dynamodb.AttributeValue{
+				r.dehydrateStatusChange(change),
+			},
+		},
+		":departure": {
+			S: aws.String(change.Departure),
+		},
+		":zero": {
+			N: aws.String("0"),
+		},
+		":one": {
+			N: aws.String("1"),
+		},
+	}
+	if change.Status == model.FlightStatusCancelled {
+		updateExpression += ", has_free_seats = :zero"
+	}
+
+	// Another change could have been made meanwhile, the version keeps the
+	// reservations whose departure is moved the ones of the flight
+	conditionExpression := "attribute_not_exists(#status)"
+	if flight.Status != "" {
+		conditionExpression = "#status = :currentStatus"
+		expressionAttributeValues[":currentStatus"] = &dynamodb.AttributeValue{
+			S: aws.String(flight.Status),
+		}
+	}
+	conditionExpression += " AND " + versionCondition(flight, expressionAttributeValues)
+	reservations := []*dynamodb.TransactWriteItem{}
+	if change.Status == model.FlightStatusDelayed {
+		reservations = r.reservationDepartures(flight, change.Departure)
+	}
+
+	err = r.updateWithReservations(&dynamodb.Update{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		ConditionExpression: aws.String(conditionExpression),
+		UpdateExpression:    aws.String(updateExpression),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: expressionAttributeValues,
+	}, reservations)
+	if isTransactionCanceled(err) {
+		return model.Flight{}, ErrStaleFlight
+	}
+	if err != nil {
+		return model.Flight{}, err
+	}
+
+	flight.Status = change.Status
+	flight.StatusHistory = append(flight.StatusHistory, change)
+	flight.Departure = change.Departure
+	if change.Status == model.FlightStatusCancelled {
+		flight.HasFreeSeats = false
+	}
+	flight.Version++
+	return flight, nil
+}
+
+func (r *FlightsRepository) Delete(id string) error {
+	flight, err := r.Find(id)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range flight.Seats {
+		if s.PassengerID != "" {
+			return ErrFlightHasPassengers
+		}
+	}
+
+	// A seat could have been taken since the flight was read
+	expressionAttributeValues := map[string]*dynamodb.AttributeValue{}
+	deleteFlight := &dynamodb.Delete{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		ConditionExpression: aws.String("attribute_exists(id) AND " + versionCondition(flight, expressionAttributeValues)),
+	}
+	if len(expressionAttributeValues) > 0 {
+		deleteFlight.ExpressionAttributeValues = expressionAttributeValues
+	}
+	flightNumbers := r.flightNumberItems(model.Flight{ID: flight.ID}, flight)
+	if len(flightNumbers) == 0 {
+		_, err = r.client.DeleteItem(&dynamodb.DeleteItemInput{
+			TableName:                 deleteFlight.TableName,
+			Key:                       deleteFlight.Key,
+			ConditionExpression:       deleteFlight.ConditionExpression,
+			ExpressionAttributeValues: deleteFlight.ExpressionAttributeValues,
+		})
+	} else {
+		_, err = r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+			TransactItems: append([]*dynamodb.TransactWriteItem{{Delete: deleteFlight}}, flightNumbers...),
+		})
+	}
+	if isConditionalCheckFailed(err) || isTransactionCanceled(err) {
+		return ErrStaleFlight
+	}
+
+	return err
 }
 
 func (r *FlightsRepository) Find(id string) (model.Flight, error) {
+	// Flight numbers share the table but they are not flights
+	if strings.HasPrefix(id, flightNumberPrefix) {
+		return model.Flight{}, ErrNoFlightsFound
+	}
 	out, err := r.client.Query(&dynamodb.QueryInput{
 		TableName: aws.String(r.table),
 		KeyConditions: map[string]*dynamodb.Condition{
@@ -102,7 +505,7 @@ func (r *FlightsRepository) Find(id string) (model.Flight, error) {
 }
 
 func (r *FlightsRepository) ListFlightsByDeparture(dateFrom string, dateTo string) ([]model.Flight, error) {
-	out, err := r.client.Query(&dynamodb.QueryInput{
+	items, err := queryAll(r.client, &dynamodb.QueryInput{
 		TableName:              aws.String(r.table),
 		IndexName:              aws.String("by_has_free_seats_and_departure"),
 		KeyConditionExpression: aws.String("has_free_seats = :one AND departure BETWEEN :dateFrom AND :dateTo"),
@@ -122,92 +525,1526 @@ func (r *FlightsRepository) ListFlightsByDeparture(dateFrom string, dateTo strin
 		return []model.Flight{}, err
 	}
 
-	if len(out.Items) == 0 {
+	if len(items) == 0 {
 		return []model.Flight{}, ErrNoFlightsFound
 	}
 
-	return r.hydrate(out.Items)
+	return r.hydrate(items)
 }
 
-func (r *FlightsRepository) ReserveSeat(flightID string, seatID string, passengerID string) error {
-	flight, err := r.Find(flightID)
+// ListFlightsByRoute returns the flights from the origin to the destination
+// departing in the given range, the earliest first. Unlike
+// ListFlightsByDeparture full flights are included
+func (r *FlightsRepository) ListFlightsByRoute(origin string, destination string, dateFrom string, dateTo string) ([]model.Flight, error) {
+	items, err := queryAll(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.table),
+		IndexName:              aws.String("by_route_and_departure"),
+		KeyConditionExpression: aws.String("route = :route AND departure BETWEEN :dateFrom AND :dateTo"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":route": {
+				S: aws.String(route(origin, destination)),
+			},
+			":dateFrom": {
+				S: aws.String(dateFrom),
+			},
+			":dateTo": {
+				S: aws.String(dateTo),
+			},
+		},
+		ScanIndexForward: aws.Bool(true),
+	})
 	if err != nil {
-		return err
+		return []model.Flight{}, err
 	}
 
-	foundSeat := model.FlightSeat{}
-	foundSeatIndex := 0
-	remainingSeats := 0
-	for i, s := range flight.Seats {
-		if foundSeat == (model.FlightSeat{}) && s.ID == seatID {
-			foundSeat = s
-			foundSeatIndex = i
+	if len(items) == 0 {
+		return []model.Flight{}, ErrNoFlightsFound
+	}
+
+	return r.hydrate(items)
+}
+
+// ListFlightsByFlightNumber returns the legs operated or marketed with the
+// flight number whose operating date is in the given range, the earliest
+// departure first
+func (r *FlightsRepository) ListFlightsByFlightNumber(carrier string, flightNumber string, dateFrom string, dateTo string) ([]model.Flight, error) {
+	items, err := queryAll(r.client, &dynamodb.QueryInput{
+		TableName:              aws.String(r.table),
+		IndexName:              aws.String("by_designator_and_operating_date"),
+		KeyConditionExpression: aws.String("designator = :designator AND operating_date BETWEEN :dateFrom AND :dateTo"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":designator": {
+				S: aws.String(model.Designator(carrier, flightNumber)),
+			},
+			":dateFrom": {
+				S: aws.String(dateFrom),
+			},
+			":dateTo": {
+				S: aws.String(dateTo),
+			},
+		},
+	})
+	if err != nil {
+		return []model.Flight{}, err
+	}
+
+	flights := []model.Flight{}
+	for _, item := range items {
+		v, ok := item["flight_id"]
+		if !ok {
+			continue
 		}
-		if s.PassengerID == "" {
-			remainingSeats++
+		flight, err := r.Find(*v.S)
+		// Deleted after the query
+		if err == ErrNoFlightsFound {
+			continue
 		}
+		if err != nil {
+			return []model.Flight{}, err
+		}
+		flights = append(flights, flight)
+	}
+	if len(flights) == 0 {
+		return []model.Flight{}, ErrNoFlightsFound
 	}
 
-	if foundSeat == (model.FlightSeat{}) {
-		return ErrNoSeatFoundInFlight
+	sort.SliceStable(flights, func(i, j int) bool {
+		departureI, _ := model.ParseDeparture(flights[i].Departure)
+		departureJ, _ := model.ParseDeparture(flights[j].Departure)
+		return departureI.Before(departureJ)
+	})
+	return flights, nil
+}
+
+// HoldSeat assigns a free seat to the passenger until the given time, after
+// that the seat can be taken by anybody else unless the hold is confirmed with
+// ReserveSeat. A passenger holding the seat already gets the hold extended
+func (r *FlightsRepository) HoldSeat(reservation model.Reservation, until time.Time) error {
+	return retryStale(func() error {
+		return r.holdSeat(reservation, until)
+	})
+}
+
+func (r *FlightsRepository) holdSeat(reservation model.Reservation, until time.Time) error {
+	flight, err := r.Find(reservation.FlightID)
+	if err != nil {
+		return err
+	}
+	if !flight.IsBookable() {
+		return ErrFlightClosed
 	}
 
-	if foundSeat.PassengerID != "" {
+	now := time.Now()
+	seatIndex, freeSeats := r.findSeat(flight, reservation.SeatID, now)
+	if seatIndex == -1 {
+		return ErrNoSeatFoundInFlight
+	}
+	seat := flight.Seats[seatIndex]
+	heldByPassenger := seat.HeldUntil != "" && seat.PassengerID == reservation.PassengerID
+	if !r.isSeatFree(seat, now) && !heldByPassenger {
 		return ErrSeatNotAvailable
 	}
+	if !r.isSeatFree(seat, now) {
+		freeSeats++
+	}
 
-	updateExpression := aws.String(
-		fmt.Sprintf("set seats[%v].passenger_id = :passengerID", foundSeatIndex),
+	updateExpression := fmt.Sprintf(
+		"set seats[%[1]v].passenger_id = :passengerID, seats[%[1]v].held_until = :heldUntil, "+
+			"has_free_seats = :hasFreeSeats, version = if_not_exists(version, :zero) + :one",
+		seatIndex,
 	)
-	expressionAttributeValues := map[string]*dynamodb.AttributeValue{
-		":passengerID": {
-			S: aws.String(passengerID),
-		},
-		":true": {
-			N: aws.String("1"),
-		},
-	}
-	if remainingSeats == 1 {
-		updateExpression = aws.String(
-			fmt.Sprintf("set has_free_seats = :zero, seats[%v].passenger_id = :passengerID", foundSeatIndex),
-		)
-		expressionAttributeValues[":zero"] = &dynamodb.AttributeValue{
-			N: aws.String("0"),
-		}
+	expressionAttributeValues := r.seatConditionValues(reservation, now)
+	expressionAttributeValues[":heldUntil"] = &dynamodb.AttributeValue{
+		S: aws.String(until.UTC().Format(time.RFC3339)),
 	}
+	expressionAttributeValues[":hasFreeSeats"] = r.hasFreeSeatsValue(freeSeats - 1)
 
-	_, err = r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
-		TransactItems: []*dynamodb.TransactWriteItem{
-			{
-				Update: &dynamodb.Update{
-					TableName: aws.String(r.table),
-					Key: map[string]*dynamodb.AttributeValue{
-						"id": {
-							S: aws.String(flightID),
-						},
-					},
-					ConditionExpression:       aws.String("has_free_seats = :true"),
-					UpdateExpression:          updateExpression,
-					ExpressionAttributeValues: expressionAttributeValues,
-				},
+	_, err = r.client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(reservation.FlightID),
 			},
 		},
+		ConditionExpression: aws.String(fmt.Sprintf(
+			"seats[%[1]v].id = :seatID AND (seats[%[1]v].passenger_id = :dash OR seats[%[1]v].held_until < :now "+
+				"OR (seats[%[1]v].passenger_id = :passengerID AND attribute_exists(seats[%[1]v].held_until))) AND %[2]v",
+			seatIndex,
+			versionCondition(flight, expressionAttributeValues),
+		)),
+		UpdateExpression:          aws.String(updateExpression),
+		ExpressionAttributeValues: expressionAttributeValues,
 	})
+	if isConditionalCheckFailed(err) {
+		if r.canStillReserve(reservation) {
+			return errFlightChanged
+		}
+		return ErrSeatNotAvailable
+	}
 
 	return err
 }
 
-func (r *FlightsRepository) hydrate(items []map[string]*dynamodb.AttributeValue) ([]model.Flight, error) {
+// ReleaseSeat frees a seat held by the passenger, confirmed seats are not
+// released
+func (r *FlightsRepository) ReleaseSeat(flightID string, seatID string, passengerID string) error {
+	return retryStale(func() error {
+		return r.releaseSeat(flightID, seatID, passengerID, time.Time{})
+	})
+}
 
-	flights := make([]model.Flight, len(items))
-	for i, item := range items {
+// ReleaseExpiredSeat frees a seat held by the passenger only once the hold is
+// over, a hold the passenger extended meanwhile is kept
+func (r *FlightsRepository) ReleaseExpiredSeat(flightID string, seatID string, passengerID string, now time.Time) error {
+	return retryStale(func() error {
+		return r.releaseSeat(flightID, seatID, passengerID, now)
+	})
+}
 
-		if v, ok := item["id"]; ok {
-			flights[i].ID = *v.S
+// releaseSeat frees the held seat, when now is set the hold must have expired
+// by then
+func (r *FlightsRepository) releaseSeat(flightID string, seatID string, passengerID string, now time.Time) error {
+	flight, err := r.Find(flightID)
+	if err != nil {
+		return err
+	}
+
+	seatIndex, _ := r.findSeat(flight, seatID, time.Now())
+	if seatIndex == -1 {
+		return ErrNoSeatFoundInFlight
+	}
+
+	// A released seat does not reopen a flight that can not be booked
+	hasFreeSeats := 0
+	if flight.IsBookable() {
+		hasFreeSeats = 1
+	}
+
+	expressionAttributeValues := map[string]*dynamodb.AttributeValue{
+		":seatID": {
+			S: aws.String(seatID),
+		},
+		":passengerID": {
+			S: aws.String(passengerID),
+		},
+		":dash": {
+			S: aws.String("-"),
+		},
+		":hasFreeSeats": {
+			N: aws.String(strconv.Itoa(hasFreeSeats)),
+		},
+		":zero": {
+			N: aws.String("0"),
+		},
+		":one": {
+			N: aws.String("1"),
+		},
+	}
+	heldCondition := fmt.Sprintf("attribute_exists(seats[%v].held_until)", seatIndex)
+	if !now.IsZero() {
+		heldCondition = fmt.Sprintf("seats[%v].held_until < :now", seatIndex)
+		expressionAttributeValues[":now"] = &dynamodb.AttributeValue{
+			S: aws.String(now.UTC().Format(time.RFC3339)),
+		}
+	}
+	_, err = r.client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(flightID),
+			},
+		},
+		ConditionExpression: aws.String(fmt.Sprintf(
+			"seats[%[1]v].id = :seatID AND seats[%[1]v].passenger_id = :passengerID AND %[2]v AND %[3]v",
+			seatIndex,
+			heldCondition,
+			versionCondition(flight, expressionAttributeValues),
+		)),
+		UpdateExpression: aws.String(fmt.Sprintf(
+			"set seats[%[1]v].passenger_id = :dash, has_free_seats = :hasFreeSeats, version = if_not_exists(version, :zero) + :one "+
+				"remove seats[%[1]v].held_until",
+			seatIndex,
+		)),
+		ExpressionAttributeValues: expressionAttributeValues,
+	})
+	if isConditionalCheckFailed(err) {
+		seat, held := r.heldSeat(flightID, seatID, passengerID)
+		if !held {
+			return ErrSeatNotHeld
+		}
+		if !now.IsZero() && seat.HeldUntil >= now.UTC().Format(time.RFC3339) {
+			return ErrSeatHoldActive
+		}
+		return errFlightChanged
+	}
+
+	return err
+}
+
+// heldSeat reads the seat again and tells if the passenger still holds it
+func (r *FlightsRepository) heldSeat(flightID string, seatID string, passengerID string) (model.FlightSeat, bool) {
+	flight, err := r.Find(flightID)
+	if err != nil {
+		return model.FlightSeat{}, false
+	}
+	seatIndex, _ := r.findSeat(flight, seatID, time.Now())
+	if seatIndex == -1 {
+		return model.FlightSeat{}, false
+	}
+	seat := flight.Seats[seatIndex]
+	return seat, seat.PassengerID == passengerID && seat.HeldUntil != ""
+}
+
+// ReserveSeat confirms a seat for the passenger, the seat must be free or held
+// by the same passenger. The reservation record is written in the same
+// transaction and a record locator is generated when it has none
+func (r *FlightsRepository) ReserveSeat(reservation model.Reservation) (model.Reservation, error) {
+	reserved, err := r.ReserveItinerary([]model.Reservation{reservation})
+	if err != nil {
+		return model.Reservation{}, err
+	}
+	return reserved[0], nil
+}
+
+// ReserveItinerary confirms a seat on every leg of a trip for the passenger in
+// a single transaction, either every seat is reserved or none. The legs share
+// the record locator of the first one, it is generated when it has none
+func (r *FlightsRepository) ReserveItinerary(reservations []model.Reservation) ([]model.Reservation, error) {
+	if len(reservations) == 0 {
+		return []model.Reservation{}, ErrNoSeatFoundInFlight
+	}
+	reservations = append([]model.Reservation{}, reservations...)
+	generateLocator := reservations[0].Locator == ""
+	for attempt := 1; ; attempt++ {
+		if generateLocator {
+			locator, err := newLocator()
+			if err != nil {
+				return []model.Reservation{}, err
+			}
+			reservations[0].Locator = locator
+		}
+		for i := range reservations {
+			reservations[i].Locator = reservations[0].Locator
+		}
+
+		reserved := []model.Reservation{}
+		err := retryStale(func() error {
+			var err error
+			reserved, err = r.reserveSeats(reservations)
+			return err
+		})
+		if err == ErrLocatorTaken && generateLocator && attempt < maxLocatorAttempts {
+			continue
+		}
+		return reserved, err
+	}
+}
+
+func (r *FlightsRepository) reserveSeats(reservations []model.Reservation) ([]model.Reservation, error) {
+	now := time.Now()
+	// The locator is claimed for the passenger, a locator of somebody else
+	// cancels the transaction
+	items := []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				TableName:           aws.String(r.reservationsTable),
+				Item:                dehydrateLocatorClaim(reservations[0].Locator, reservations[0].PassengerID),
+				ConditionExpression: aws.String("attribute_not_exists(locator) OR claimed_by = :passengerID"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":passengerID": {
+						S: aws.String(reservations[0].PassengerID),
+					},
+				},
+			},
+		},
+	}
+	reserved := make([]model.Reservation, len(reservations))
+	versions := make([]int, len(reservations))
+	for i, reservation := range reservations {
+		update, reservation, version, err := r.seatReservation(reservation, now)
+		if err != nil {
+			return []model.Reservation{}, err
+		}
+		versions[i] = version
+		items = append(items,
+			&dynamodb.TransactWriteItem{
+				Update: update,
+			},
+			&dynamodb.TransactWriteItem{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(r.reservationsTable),
+					Item:                dehydrateReservation(reservation),
+					ConditionExpression: aws.String("attribute_not_exists(locator)"),
+				},
+			},
+		)
+		reserved[i] = reservation
+	}
+
+	_, err := r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if isTransactionCanceled(err) {
+		// The cancellation reasons are not exposed, if the seats can still be
+		// taken it was either a flight changed meanwhile or the locator that
+		// already existed
+		for _, reservation := range reservations {
+			if !r.canStillReserve(reservation) {
+				return []model.Reservation{}, ErrSeatNotAvailable
+			}
+		}
+		for i, reservation := range reservations {
+			flight, err := r.Find(reservation.FlightID)
+			if err != nil || flight.Version != versions[i] {
+				return []model.Reservation{}, errFlightChanged
+			}
+		}
+		return []model.Reservation{}, ErrLocatorTaken
+	}
+	if err != nil {
+		return []model.Reservation{}, err
+	}
+
+	return reserved, nil
+}
+
+// seatReservation returns the update giving the seat to the passenger along
+// with the version of the flight it was read at, the seat must be free or held
+// by the same passenger
+func (r *FlightsRepository) seatReservation(reservation model.Reservation, now time.Time) (*dynamodb.Update, model.Reservation, int, error) {
+	flight, err := r.Find(reservation.FlightID)
+	if err != nil {
+		return nil, model.Reservation{}, 0, err
+	}
+	if !flight.IsBookable() {
+		return nil, model.Reservation{}, 0, ErrFlightClosed
+	}
+
+	seatIndex, freeSeats := r.findSeat(flight, reservation.SeatID, now)
+	if seatIndex == -1 {
+		return nil, model.Reservation{}, 0, ErrNoSeatFoundInFlight
+	}
+	seat := flight.Seats[seatIndex]
+	heldByPassenger := seat.HeldUntil != "" && seat.PassengerID == reservation.PassengerID
+	if !r.isSeatFree(seat, now) && !heldByPassenger {
+		return nil, model.Reservation{}, 0, ErrSeatNotAvailable
+	}
+	if !heldByPassenger {
+		freeSeats--
+	}
+
+	reservation.Departure = flight.Departure
+	reservation.CreatedAt = now.UTC().Format(time.RFC3339)
+
+	updateExpression := fmt.Sprintf(
+		"set seats[%[1]v].passenger_id = :passengerID, seats[%[1]v].locator = :locator, "+
+			"has_free_seats = :hasFreeSeats, version = if_not_exists(version, :zero) + :one",
+		seatIndex,
+	)
+	expressionAttributeValues := r.seatConditionValues(reservation, now)
+	expressionAttributeValues[":hasFreeSeats"] = r.hasFreeSeatsValue(freeSeats)
+	expressionAttributeValues[":locator"] = &dynamodb.AttributeValue{
+		S: aws.String(reservation.Locator),
+	}
+	if reservation.Price.Currency != "" {
+		updateExpression += fmt.Sprintf(", seats[%v].price = :price", seatIndex)
+		expressionAttributeValues[":price"] = dehydratePrice(reservation.Price)
+	}
+	if reservation.PaymentID != "" {
+		updateExpression += fmt.Sprintf(", seats[%v].payment_id = :paymentID", seatIndex)
+		expressionAttributeValues[":paymentID"] = &dynamodb.AttributeValue{
+			S: aws.String(reservation.PaymentID),
+		}
+	}
+	if len(reservation.SpecialRequests) > 0 {
+		updateExpression += fmt.Sprintf(", seats[%v].special_requests = :specialRequests", seatIndex)
+		expressionAttributeValues[":specialRequests"] = dehydrateStrings(reservation.SpecialRequests)
+	}
+	updateExpression += fmt.Sprintf(" remove seats[%v].held_until", seatIndex)
+
+	return &dynamodb.Update{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(reservation.FlightID),
+			},
+		},
+		ConditionExpression: aws.String(fmt.Sprintf(
+			"seats[%[1]v].id = :seatID AND (seats[%[1]v].passenger_id = :dash OR seats[%[1]v].held_until < :now "+
+				"OR (seats[%[1]v].passenger_id = :passengerID AND attribute_exists(seats[%[1]v].held_until))) AND %[2]v",
+			seatIndex,
+			versionCondition(flight, expressionAttributeValues),
+		)),
+		UpdateExpression:          aws.String(updateExpression),
+		ExpressionAttributeValues: expressionAttributeValues,
+	}, reservation, flight.Version, nil
+}
+
+func (r *FlightsRepository) canStillReserve(reservation model.Reservation) bool {
+	flight, err := r.Find(reservation.FlightID)
+	if err != nil {
+		return false
+	}
+	now := time.Now()
+	seatIndex, _ := r.findSeat(flight, reservation.SeatID, now)
+	if seatIndex == -1 {
+		return false
+	}
+	seat := flight.Seats[seatIndex]
+	heldByPassenger := seat.HeldUntil != "" && seat.PassengerID == reservation.PassengerID
+	return r.isSeatFree(seat, now) || heldByPassenger
+}
+
+// ChangeSeat moves the passenger from one seat of the flight to another in a
+// single transaction, the new seat keeps the price, payment and locator of the
+// old one and the reservation record is updated too
+func (r *FlightsRepository) ChangeSeat(flightID string, fromSeatID string, toSeatID string, passengerID string) (model.Reservation, error) {
+	flight, err := r.Find(flightID)
+	if err != nil {
+		return model.Reservation{}, err
+	}
+	if !flight.IsBookable() {
+		return model.Reservation{}, ErrFlightClosed
+	}
+
+	now := time.Now()
+	fromIndex, _ := r.findSeat(flight, fromSeatID, now)
+	toIndex, _ := r.findSeat(flight, toSeatID, now)
+	if fromIndex == -1 || toIndex == -1 {
+		return model.Reservation{}, ErrNoSeatFoundInFlight
+	}
+	from := flight.Seats[fromIndex]
+	if from.PassengerID != passengerID || from.HeldUntil != "" {
+		return model.Reservation{}, ErrPassengerNotInSeat
+	}
+	if fromIndex == toIndex || !r.isSeatFree(flight.Seats[toIndex], now) {
+		return model.Reservation{}, ErrSeatNotAvailable
+	}
+
+	updateExpression := fmt.Sprintf(
+		"set seats[%[2]v].passenger_id = :passengerID, seats[%[1]v].passenger_id = :dash, "+
+			"version = if_not_exists(version, :zero) + :one",
+		fromIndex,
+		toIndex,
+	)
+	removeExpression := fmt.Sprintf(
+		" remove seats[%[1]v].price, seats[%[1]v].payment_id, seats[%[1]v].locator, seats[%[1]v].checked_in_at, "+
+			"seats[%[1]v].special_requests, seats[%[2]v].held_until",
+		fromIndex,
+		toIndex,
+	)
+	expressionAttributeValues := map[string]*dynamodb.AttributeValue{
+		":fromSeatID": {
+			S: aws.String(fromSeatID),
+		},
+		":toSeatID": {
+			S: aws.String(toSeatID),
+		},
+		":passengerID": {
+			S: aws.String(passengerID),
+		},
+		":dash": {
+			S: aws.String("-"),
+		},
+		":now": {
+			S: aws.String(now.UTC().Format(time.RFC3339)),
+		},
+		":zero": {
+			N: aws.String("0"),
+		},
+		":one": {
+			N: aws.String("1"),
+		},
+	}
+	if from.Price.Currency != "" {
+		updateExpression += fmt.Sprintf(", seats[%v].price = :price", toIndex)
+		expressionAttributeValues[":price"] = dehydratePrice(from.Price)
+	}
+	if from.PaymentID != "" {
+		updateExpression += fmt.Sprintf(", seats[%v].payment_id = :paymentID", toIndex)
+		expressionAttributeValues[":paymentID"] = &dynamodb.AttributeValue{
+			S: aws.String(from.PaymentID),
+		}
+	}
+	if from.Locator != "" {
+		updateExpression += fmt.Sprintf(", seats[%v].locator = :locator", toIndex)
+		expressionAttributeValues[":locator"] = &dynamodb.AttributeValue{
+			S: aws.String(from.Locator),
+		}
+	}
+	if from.CheckedInAt != "" {
+		updateExpression += fmt.Sprintf(", seats[%v].checked_in_at = :checkedInAt", toIndex)
+		expressionAttributeValues[":checkedInAt"] = &dynamodb.AttributeValue{
+			S: aws.String(from.CheckedInAt),
+		}
+	}
+	if len(from.SpecialRequests) > 0 {
+		updateExpression += fmt.Sprintf(", seats[%v].special_requests = :specialRequests", toIndex)
+		expressionAttributeValues[":specialRequests"] = dehydrateStrings(from.SpecialRequests)
+	}
+
+	transactItems := []*dynamodb.TransactWriteItem{
+		{
+			Update: &dynamodb.Update{
+				TableName: aws.String(r.table),
+				Key: map[string]*dynamodb.AttributeValue{
+					"id": {
+						S: aws.String(flightID),
+					},
+				},
+				ConditionExpression: aws.String(fmt.Sprintf(
+					"seats[%[1]v].id = :fromSeatID AND seats[%[1]v].passenger_id = :passengerID "+
+						"AND attribute_not_exists(seats[%[1]v].held_until) "+
+						"AND seats[%[2]v].id = :toSeatID AND (seats[%[2]v].passenger_id = :dash OR seats[%[2]v].held_until < :now)",
+					fromIndex,
+					toIndex,
+				)),
+				UpdateExpression:          aws.String(updateExpression + removeExpression),
+				ExpressionAttributeValues: expressionAttributeValues,
+			},
+		},
+	}
+	// Seats reserved before the reservations table existed have no locator
+	if from.Locator != "" {
+		transactItems = append(transactItems, &dynamodb.TransactWriteItem{
+			Update: &dynamodb.Update{
+				TableName: aws.String(r.reservationsTable),
+				Key: map[string]*dynamodb.AttributeValue{
+					"locator": {
+						S: aws.String(from.Locator),
+					},
+					"flight_id": {
+						S: aws.String(flightID),
+					},
+				},
+				ConditionExpression: aws.String("seat_id = :fromSeatID AND passenger_id = :passengerID"),
+				UpdateExpression:    aws.String("set seat_id = :toSeatID"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":fromSeatID": {
+						S: aws.String(fromSeatID),
+					},
+					":toSeatID": {
+						S: aws.String(toSeatID),
+					},
+					":passengerID": {
+						S: aws.String(passengerID),
+					},
+				},
+			},
+		})
+	}
+
+	_, err = r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	})
+	if isTransactionCanceled(err) {
+		return model.Reservation{}, ErrSeatNotAvailable
+	}
+	if err != nil {
+		return model.Reservation{}, err
+	}
+
+	return model.Reservation{
+		Locator:     from.Locator,
+		FlightID:    flightID,
+		SeatID:      toSeatID,
+		PassengerID: passengerID,
+		Departure:   flight.Departure,
+		Price:       from.Price,
+		PaymentID:   from.PaymentID,
+
+		SpecialRequests: from.SpecialRequests,
+	}, nil
+}
+
+// MoveReservation moves the passenger of a confirmed seat to a free seat of
+// another flight in a single transaction, the new seat keeps the price, payment
+// and locator of the old one and the reservation record follows it. The old
+// flight can be closed, the new one must be bookable. The old flight has free
+// seats again unless it is closed
+func (r *FlightsRepository) MoveReservation(fromFlightID string, fromSeatID string, toFlightID string, toSeatID string, passengerID string) (model.Reservation, error) {
+	fromFlight, err := r.Find(fromFlightID)
+	if err != nil {
+		return model.Reservation{}, err
+	}
+	toFlight, err := r.Find(toFlightID)
+	if err != nil {
+		return model.Reservation{}, err
+	}
+	if !toFlight.IsBookable() {
+		return model.Reservation{}, ErrFlightClosed
+	}
+
+	now := time.Now()
+	fromIndex, _ := r.findSeat(fromFlight, fromSeatID, now)
+	toIndex, freeSeats := r.findSeat(toFlight, toSeatID, now)
+	if fromIndex == -1 || toIndex == -1 {
+		return model.Reservation{}, ErrNoSeatFoundInFlight
+	}
+	from := fromFlight.Seats[fromIndex]
+	if from.PassengerID != passengerID || from.HeldUntil != "" {
+		return model.Reservation{}, ErrPassengerNotInSeat
+	}
+	if fromFlightID == toFlightID || !r.isSeatFree(toFlight.Seats[toIndex], now) {
+		return model.Reservation{}, ErrSeatNotAvailable
+	}
+
+	reservation := model.Reservation{
+		Locator:     from.Locator,
+		FlightID:    toFlightID,
+		SeatID:      toSeatID,
+		PassengerID: passengerID,
+		Departure:   toFlight.Departure,
+		Price:       from.Price,
+		PaymentID:   from.PaymentID,
+		CreatedAt:   now.UTC().Format(time.RFC3339),
+
+		SpecialRequests: from.SpecialRequests,
+	}
+
+	// Take the new seat
+	toUpdateExpression := fmt.Sprintf(
+		"set seats[%[1]v].passenger_id = :passengerID, has_free_seats = :hasFreeSeats, "+
+			"version = if_not_exists(version, :zero) + :one",
+		toIndex,
+	)
+	toExpressionAttributeValues := r.seatConditionValues(model.Reservation{SeatID: toSeatID, PassengerID: passengerID}, now)
+	toExpressionAttributeValues[":hasFreeSeats"] = r.hasFreeSeatsValue(freeSeats - 1)
+	if from.Price.Currency != "" {
+		toUpdateExpression += fmt.Sprintf(", seats[%v].price = :price", toIndex)
+		toExpressionAttributeValues[":price"] = dehydratePrice(from.Price)
+	}
+	if from.PaymentID != "" {
+		toUpdateExpression += fmt.Sprintf(", seats[%v].payment_id = :paymentID", toIndex)
+		toExpressionAttributeValues[":paymentID"] = &dynamodb.AttributeValue{
+			S: aws.String(from.PaymentID),
+		}
+	}
+	if from.Locator != "" {
+		toUpdateExpression += fmt.Sprintf(", seats[%v].locator = :locator", toIndex)
+		toExpressionAttributeValues[":locator"] = &dynamodb.AttributeValue{
+			S: aws.String(from.Locator),
+		}
+	}
+	if len(from.SpecialRequests) > 0 {
+		toUpdateExpression += fmt.Sprintf(", seats[%v].special_requests = :specialRequests", toIndex)
+		toExpressionAttributeValues[":specialRequests"] = dehydrateStrings(from.SpecialRequests)
+	}
+	toUpdateExpression += fmt.Sprintf(" remove seats[%v].held_until", toIndex)
+
+	// The old seat is free again, it does not reopen a flight that can not
+	// be booked
+	fromHasFreeSeats := 0
+	if fromFlight.IsBookable() {
+		fromHasFreeSeats = 1
+	}
+
+	transactItems := []*dynamodb.TransactWriteItem{
+		{
+			Update: &dynamodb.Update{
+				TableName: aws.String(r.table),
+				Key: map[string]*dynamodb.AttributeValue{
+					"id": {
+						S: aws.String(toFlightID),
+					},
+				},
+				ConditionExpression: aws.String(fmt.Sprintf(
+					"seats[%[1]v].id = :seatID AND (seats[%[1]v].passenger_id = :dash OR seats[%[1]v].held_until < :now)",
+					toIndex,
+				)),
+				UpdateExpression:          aws.String(toUpdateExpression),
+				ExpressionAttributeValues: toExpressionAttributeValues,
+			},
+		},
+		{
+			// Give the old seat back
+			Update: &dynamodb.Update{
+				TableName: aws.String(r.table),
+				Key: map[string]*dynamodb.AttributeValue{
+					"id": {
+						S: aws.String(fromFlightID),
+					},
+				},
+				ConditionExpression: aws.String(fmt.Sprintf(
+					"seats[%[1]v].id = :seatID AND seats[%[1]v].passenger_id = :passengerID "+
+						"AND attribute_not_exists(seats[%[1]v].held_until)",
+					fromIndex,
+				)),
+				UpdateExpression: aws.String(fmt.Sprintf(
+					"set seats[%[1]v].passenger_id = :dash, has_free_seats = :hasFreeSeats, "+
+						"version = if_not_exists(version, :zero) + :one "+
+						"remove seats[%[1]v].price, seats[%[1]v].payment_id, seats[%[1]v].locator, seats[%[1]v].checked_in_at, "+
+						"seats[%[1]v].special_requests",
+					fromIndex,
+				)),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":seatID": {
+						S: aws.String(fromSeatID),
+					},
+					":passengerID": {
+						S: aws.String(passengerID),
+					},
+					":dash": {
+						S: aws.String("-"),
+					},
+					":hasFreeSeats": {
+						N: aws.String(strconv.Itoa(fromHasFreeSeats)),
+					},
+					":zero": {
+						N: aws.String("0"),
+					},
+					":one": {
+						N: aws.String("1"),
+					},
+				},
+			},
+		},
+	}
+	// The reservation record is keyed by flight, it is replaced by a new one.
+	// The locator stays the one the passenger already has, it was claimed
+	// for them when the seat was reserved
+	if from.Locator != "" {
+		transactItems = append(transactItems,
+			&dynamodb.TransactWriteItem{
+				Delete: &dynamodb.Delete{
+					TableName: aws.String(r.reservationsTable),
+					Key: map[string]*dynamodb.AttributeValue{
+						"locator": {
+							S: aws.String(from.Locator),
+						},
+						"flight_id": {
+							S: aws.String(fromFlightID),
+						},
+					},
+					ConditionExpression: aws.String("passenger_id = :passengerID"),
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":passengerID": {
+							S: aws.String(passengerID),
+						},
+					},
+				},
+			},
+			&dynamodb.TransactWriteItem{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(r.reservationsTable),
+					Item:                dehydrateReservation(reservation),
+					ConditionExpression: aws.String("attribute_not_exists(locator)"),
+				},
+			},
+		)
+	}
+
+	_, err = r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: transactItems,
+	})
+	if isTransactionCanceled(err) {
+		return model.Reservation{}, ErrSeatNotAvailable
+	}
+	if err != nil {
+		return model.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+// CheckIn records the check-in time on the confirmed seat of the passenger and
+// returns its check-in sequence number, taken from a counter of the flight in
+// the same write so no two passengers get the same one
+func (r *FlightsRepository) CheckIn(flightID string, seatID string, passengerID string, at time.Time) (int, error) {
+	flight, err := r.Find(flightID)
+	if err != nil {
+		return 0, err
+	}
+	if !flight.IsBookable() {
+		return 0, ErrFlightClosed
+	}
+
+	seatIndex, _ := r.findSeat(flight, seatID, at)
+	if seatIndex == -1 {
+		return 0, ErrNoSeatFoundInFlight
+	}
+	seat := flight.Seats[seatIndex]
+	if seat.PassengerID != passengerID || seat.HeldUntil != "" {
+		return 0, ErrPassengerNotInSeat
+	}
+	if seat.CheckedInAt != "" {
+		return 0, ErrAlreadyCheckedIn
+	}
+
+	// Flights checked in before the counter existed start after the
+	// passengers already checked in
+	checkedIn := 0
+	for _, s := range flight.Seats {
+		if s.CheckedInAt != "" {
+			checkedIn++
+		}
+	}
+
+	out, err := r.client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(flightID),
+			},
+		},
+		ConditionExpression: aws.String(fmt.Sprintf(
+			"seats[%[1]v].id = :seatID AND seats[%[1]v].passenger_id = :passengerID "+
+				"AND attribute_not_exists(seats[%[1]v].held_until) AND attribute_not_exists(seats[%[1]v].checked_in_at)",
+			seatIndex,
+		)),
+		UpdateExpression: aws.String(fmt.Sprintf(
+			"set seats[%[1]v].checked_in_at = :checkedInAt, version = if_not_exists(version, :zero) + :one, "+
+				"checkin_sequence = if_not_exists(checkin_sequence, :checkedIn) + :one",
+			seatIndex,
+		)),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":seatID": {
+				S: aws.String(seatID),
+			},
+			":passengerID": {
+				S: aws.String(passengerID),
+			},
+			":checkedInAt": {
+				S: aws.String(at.UTC().Format(time.RFC3339)),
+			},
+			":checkedIn": {
+				N: aws.String(strconv.Itoa(checkedIn)),
+			},
+			":zero": {
+				N: aws.String("0"),
+			},
+			":one": {
+				N: aws.String("1"),
+			},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	// The passenger could have changed seat or checked in meanwhile
+	if isConditionalCheckFailed(err) {
+		return 0, ErrPassengerNotInSeat
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(*out.Attributes["checkin_sequence"].N)
+}
+
+// reservationDepartures returns the updates moving the reservations of the
+// flight to the departure, seats reserved before the reservations table
+// existed have no locator
+func (r *FlightsRepository) reservationDepartures(flight model.Flight, departure string) []*dynamodb.TransactWriteItem {
+	items := []*dynamodb.TransactWriteItem{}
+	for _, s := range flight.Seats {
+		if s.Locator == "" || s.PassengerID == "" {
+			continue
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Update: &dynamodb.Update{
+				TableName: aws.String(r.reservationsTable),
+				Key: map[string]*dynamodb.AttributeValue{
+					"locator": {
+						S: aws.String(s.Locator),
+					},
+					"flight_id": {
+						S: aws.String(flight.ID),
+					},
+				},
+				ConditionExpression: aws.String("attribute_exists(locator)"),
+				UpdateExpression:    aws.String("set departure = :departure"),
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":departure": {
+						S: aws.String(departure),
+					},
+				},
+			},
+		})
+	}
+	return items
+}
+
+// updateWithReservations writes the update of the flight and the ones of its
+// reservations in a single transaction. The reservations that do not fit in
+// it are written afterwards, they are only set so a retry completes them
+func (r *FlightsRepository) updateWithReservations(update *dynamodb.Update, reservations []*dynamodb.TransactWriteItem) error {
+	first := len(reservations)
+	if first > maxTransactItems-1 {
+		first = maxTransactItems - 1
+	}
+	_, err := r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: append([]*dynamodb.TransactWriteItem{{Update: update}}, reservations[:first]...),
+	})
+	if err != nil {
+		return err
+	}
+
+	for start := first; start < len(reservations); start += maxTransactItems {
+		end := start + maxTransactItems
+		if end > len(reservations) {
+			end = len(reservations)
+		}
+		_, err = r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+			TransactItems: reservations[start:end],
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// versionCondition returns the condition of the flight not having changed
+// since it was read, flights stored before versions existed have none
+func versionCondition(flight model.Flight, expressionAttributeValues map[string]*dynamodb.AttributeValue) string {
+	if flight.Version == 0 {
+		return "attribute_not_exists(version)"
+	}
+	expressionAttributeValues[":version"] = &dynamodb.AttributeValue{
+		N: aws.String(strconv.Itoa(flight.Version)),
+	}
+	return "version = :version"
+}
+
+// retryStale runs the write again while the flight keeps changing between
+// reading and writing it
+func retryStale(write func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := write()
+		if err != errFlightChanged {
+			return err
+		}
+		if attempt == maxStaleAttempts {
+			return ErrStaleFlight
+		}
+	}
+}
+
+// findSeat returns the index of the seat in the flight, -1 when it does not
+// exist, and how many seats are free
+func (r *FlightsRepository) findSeat(flight model.Flight, seatID string, now time.Time) (int, int) {
+	seatIndex := -1
+	freeSeats := 0
+	for i, s := range flight.Seats {
+		if seatIndex == -1 && s.ID == seatID {
+			seatIndex = i
+		}
+		if r.isSeatFree(s, now) {
+			freeSeats++
+		}
+	}
+	return seatIndex, freeSeats
+}
+
+// isSeatFree tells if nobody has the seat or its hold already expired
+func (r *FlightsRepository) isSeatFree(seat model.FlightSeat, now time.Time) bool {
+	return seat.IsFree(now)
+}
+
+func (r *FlightsRepository) seatConditionValues(reservation model.Reservation, now time.Time) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		":seatID": {
+			S: aws.String(reservation.SeatID),
+		},
+		":passengerID": {
+			S: aws.String(reservation.PassengerID),
+		},
+		":dash": {
+			S: aws.String("-"),
+		},
+		":now": {
+			S: aws.String(now.UTC().Format(time.RFC3339)),
+		},
+		":zero": {
+			N: aws.String("0"),
+		},
+		":one": {
+			N: aws.String("1"),
+		},
+	}
+}
+
+func (r *FlightsRepository) hasFreeSeatsValue(freeSeats int) *dynamodb.AttributeValue {
+	if freeSeats > 0 {
+		return &dynamodb.AttributeValue{
+			N: aws.String("1"),
+		}
+	}
+	return &dynamodb.AttributeValue{
+		N: aws.String("0"),
+	}
+}
+
+// setOperatingDate gives the flight numbers the local date of the departure
+// when they have no operating date yet
+func (r *FlightsRepository) setOperatingDate(m *model.Flight) error {
+	if m.OperatingDate != "" || m.Carrier == "" || m.FlightNumber == "" {
+		return nil
+	}
+	date, err := m.LocalDate()
+	if err != nil {
+		return err
+	}
+	m.OperatingDate = date
+	return nil
+}
+
+// putFlight writes the flight and its flight numbers in one transaction,
+// flights without flight numbers are put alone
+func (r *FlightsRepository) putFlight(put *dynamodb.Put, flightNumbers []*dynamodb.TransactWriteItem) error {
+	if len(flightNumbers) == 0 {
+		_, err := r.client.PutItem(&dynamodb.PutItemInput{
+			TableName:                 put.TableName,
+			Item:                      put.Item,
+			ConditionExpression:       put.ConditionExpression,
+			ExpressionAttributeValues: put.ExpressionAttributeValues,
+		})
+		return err
+	}
+	_, err := r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: append([]*dynamodb.TransactWriteItem{{Put: put}}, flightNumbers...),
+	})
+	return err
+}
+
+// flightNumberItems takes the flight numbers of the flight, unless another
+// flight has them, and releases the ones the stored flight had and it does
+// not have anymore
+func (r *FlightsRepository) flightNumberItems(m model.Flight, stored model.Flight) []*dynamodb.TransactWriteItem {
+	flightID := map[string]*dynamodb.AttributeValue{
+		":flightID": {
+			S: aws.String(m.ID),
+		},
+	}
+
+	items := []*dynamodb.TransactWriteItem{}
+	kept := map[string]bool{}
+	for _, designator := range designators(m) {
+		id := flightNumberID(designator, m.OperatingDate, m.Origin)
+		if kept[id] {
+			continue
+		}
+		kept[id] = true
+		item := map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+			"flight_id": {
+				S: aws.String(m.ID),
+			},
+			"designator": {
+				S: aws.String(designator),
+			},
+			"operating_date": {
+				S: aws.String(m.OperatingDate),
+			},
+		}
+		items = append(items, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				TableName:                 aws.String(r.table),
+				Item:                      item,
+				ConditionExpression:       aws.String("attribute_not_exists(id) OR flight_id = :flightID"),
+				ExpressionAttributeValues: flightID,
+			},
+		})
+	}
+	for _, designator := range designators(stored) {
+		id := flightNumberID(designator, stored.OperatingDate, stored.Origin)
+		if kept[id] {
+			continue
+		}
+		kept[id] = true
+		items = append(items, &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{
+				TableName: aws.String(r.table),
+				Key: map[string]*dynamodb.AttributeValue{
+					"id": {
+						S: aws.String(id),
+					},
+				},
+				ConditionExpression:       aws.String("flight_id = :flightID"),
+				ExpressionAttributeValues: flightID,
+			},
+		})
+	}
+	return items
+}
+
+// designators returns the operating and the marketing flight numbers of the
+// flight, e.g. AV123 and IB6421
+func designators(m model.Flight) []string {
+	if m.Carrier == "" || m.FlightNumber == "" || m.OperatingDate == "" {
+		return []string{}
+	}
+	designators := []string{model.Designator(m.Carrier, m.FlightNumber)}
+	for _, marketing := range m.MarketingFlights {
+		designators = append(designators, model.Designator(marketing.Carrier, marketing.FlightNumber))
+	}
+	return designators
+}
+
+// flightNumberID is the id of the item giving the flight number of the date
+// to the leg departing from the origin, e.g. flight_number#AV123#2020-05-01#BOG
+func flightNumberID(designator string, operatingDate string, origin string) string {
+	return fmt.Sprintf("%s%s#%s#%s", flightNumberPrefix, designator, operatingDate, origin)
+}
+
+func (r *FlightsRepository) dehydrate(m model.Flight) map[string]*dynamodb.AttributeValue {
+	hasFreeSeats := 0
+	if m.HasFreeSeats {
+		hasFreeSeats = 1
+	}
+
+	seats := make([]*dynamodb.AttributeValue, len(m.Seats))
+	for i, s := range m.Seats {
+		passengerID := s.PassengerID
+		if passengerID == "" {
+			passengerID = "-"
+		}
+		seatMap := map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(s.ID),
+			},
+			"letter": {
+				S: aws.String(s.Letter),
+			},
+			"row": {
+				N: aws.String(strconv.Itoa(s.Row)),
+			},
+			"passenger_id": {
+				S: aws.String(passengerID),
+			},
+			"exit_row": {
+				BOOL: aws.Bool(s.ExitRow),
+			},
+			"extra_legroom": {
+				BOOL: aws.Bool(s.ExtraLegroom),
+			},
+		}
+		if s.Position != "" {
+			seatMap["position"] = &dynamodb.AttributeValue{
+				S: aws.String(s.Position),
+			}
+		}
+		if s.Cabin != "" {
+			seatMap["cabin"] = &dynamodb.AttributeValue{
+				S: aws.String(s.Cabin),
+			}
+		}
+		if s.Price.Currency != "" {
+			seatMap["price"] = dehydratePrice(s.Price)
+		}
+		if s.HeldUntil != "" {
+			seatMap["held_until"] = &dynamodb.AttributeValue{
+				S: aws.String(s.HeldUntil),
+			}
+		}
+		if s.PaymentID != "" {
+			seatMap["payment_id"] = &dynamodb.AttributeValue{
+				S: aws.String(s.PaymentID),
+			}
+		}
+		if s.Locator != "" {
+			seatMap["locator"] = &dynamodb.AttributeValue{
+				S: aws.String(s.Locator),
+			}
+		}
+		if s.CheckedInAt != "" {
+			seatMap["checked_in_at"] = &dynamodb.AttributeValue{
+				S: aws.String(s.CheckedInAt),
+			}
+		}
+		if len(s.SpecialRequests) > 0 {
+			seatMap["special_requests"] = dehydrateStrings(s.SpecialRequests)
+		}
+		if s.AisleAfter {
+			seatMap["aisle_after"] = &dynamodb.AttributeValue{
+				BOOL: aws.Bool(true),
+			}
+		}
+		seats[i] = &dynamodb.AttributeValue{
+			M: seatMap,
+		}
+	}
+
+	item := map[string]*dynamodb.AttributeValue{
+		"id": {
+			S: aws.String(m.ID),
+		},
+		"departure": {
+			S: aws.String(m.Departure),
+		},
+		"has_free_seats": {
+			N: aws.String(strconv.Itoa(hasFreeSeats)),
+		},
+		"seats": {
+			L: seats,
+		},
+		"version": {
+			N: aws.String(strconv.Itoa(m.Version)),
+		},
+	}
+	if m.AircraftType != "" {
+		item["aircraft_type"] = &dynamodb.AttributeValue{
+			S: aws.String(m.AircraftType),
+		}
+	}
+	if m.Origin != "" && m.Destination != "" {
+		item["origin"] = &dynamodb.AttributeValue{
+			S: aws.String(m.Origin),
+		}
+		item["destination"] = &dynamodb.AttributeValue{
+			S: aws.String(m.Destination),
+		}
+		item["route"] = &dynamodb.AttributeValue{
+			S: aws.String(route(m.Origin, m.Destination)),
+		}
+	}
+	if m.OriginTimeZone != "" {
+		item["origin_time_zone"] = &dynamodb.AttributeValue{
+			S: aws.String(m.OriginTimeZone),
+		}
+	}
+	if m.Carrier != "" && m.FlightNumber != "" {
+		item["carrier"] = &dynamodb.AttributeValue{
+			S: aws.String(m.Carrier),
+		}
+		item["flight_number"] = &dynamodb.AttributeValue{
+			S: aws.String(m.FlightNumber),
+		}
+	}
+	if m.OperatingDate != "" {
+		item["operating_date"] = &dynamodb.AttributeValue{
+			S: aws.String(m.OperatingDate),
+		}
+	}
+	if m.BlockMinutes > 0 {
+		item["block_minutes"] = &dynamodb.AttributeValue{
+			N: aws.String(strconv.Itoa(m.BlockMinutes)),
+		}
+	}
+	if m.CheckInSequence > 0 {
+		item["checkin_sequence"] = &dynamodb.AttributeValue{
+			N: aws.String(strconv.Itoa(m.CheckInSequence)),
+		}
+	}
+	if len(m.MarketingFlights) > 0 {
+		marketingFlights := make([]*dynamodb.AttributeValue, len(m.MarketingFlights))
+		for i, marketing := range m.MarketingFlights {
+			marketingFlights[i] = &dynamodb.AttributeValue{
+				M: map[string]*dynamodb.AttributeValue{
+					"carrier": {
+						S: aws.String(marketing.Carrier),
+					},
+					"flight_number": {
+						S: aws.String(marketing.FlightNumber),
+					},
+				},
+			}
+		}
+		item["marketing_flights"] = &dynamodb.AttributeValue{
+			L: marketingFlights,
+		}
+	}
+	if m.Fares.Currency != "" {
+		item["fares"] = r.dehydrateFares(m.Fares)
+	}
+	if m.Status != "" {
+		item["status"] = &dynamodb.AttributeValue{
+			S: aws.String(m.Status),
+		}
+	}
+	if len(m.StatusHistory) > 0 {
+		history := make([]*dynamodb.AttributeValue, len(m.StatusHistory))
+		for i, c := range m.StatusHistory {
+			history[i] = r.dehydrateStatusChange(c)
+		}
+		item["status_history"] = &dynamodb.AttributeValue{
+			L: history,
+		}
+	}
+	return item
+}
+
+func (r *FlightsRepository) dehydrateStatusChange(change model.FlightStatusChange) *dynamodb.AttributeValue {
+	values := map[string]*dynamodb.AttributeValue{
+		"status": {
+			S: aws.String(change.Status),
+		},
+		"departure": {
+			S: aws.String(change.Departure),
+		},
+		"changed_at": {
+			S: aws.String(change.ChangedAt),
+		},
+	}
+	if change.Reason != "" {
+		values["reason"] = &dynamodb.AttributeValue{
+			S: aws.String(change.Reason),
+		}
+	}
+	return &dynamodb.AttributeValue{
+		M: values,
+	}
+}
+
+func (r *FlightsRepository) dehydrateFares(fares model.Fares) *dynamodb.AttributeValue {
+	amounts := func(m map[string]int64) *dynamodb.AttributeValue {
+		values := map[string]*dynamodb.AttributeValue{}
+		for k, v := range m {
+			values[k] = &dynamodb.AttributeValue{
+				N: aws.String(strconv.FormatInt(v, 10)),
+			}
+		}
+		return &dynamodb.AttributeValue{
+			M: values,
+		}
+	}
+
+	return &dynamodb.AttributeValue{
+		M: map[string]*dynamodb.AttributeValue{
+			"currency": {
+				S: aws.String(fares.Currency),
+			},
+			"cabins":     amounts(fares.Cabins),
+			"surcharges": amounts(fares.Surcharges),
+		},
+	}
+}
+
+func dehydrateStrings(values []string) *dynamodb.AttributeValue {
+	list := make([]*dynamodb.AttributeValue, len(values))
+	for i, v := range values {
+		list[i] = &dynamodb.AttributeValue{
+			S: aws.String(v),
+		}
+	}
+	return &dynamodb.AttributeValue{
+		L: list,
+	}
+}
+
+func dehydratePrice(price model.Price) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{
+		M: map[string]*dynamodb.AttributeValue{
+			"amount": {
+				N: aws.String(strconv.FormatInt(price.Amount, 10)),
+			},
+			"currency": {
+				S: aws.String(price.Currency),
+			},
+		},
+	}
+}
+
+// copyAssignment copies what belongs to the passenger of a seat, not the seat
+// itself
+func (r *FlightsRepository) copyAssignment(seat model.FlightSeat, from model.FlightSeat) model.FlightSeat {
+	seat.PassengerID = from.PassengerID
+	seat.HeldUntil = from.HeldUntil
+	seat.Price = from.Price
+	seat.PaymentID = from.PaymentID
+	seat.Locator = from.Locator
+	seat.CheckedInAt = from.CheckedInAt
+	seat.SpecialRequests = from.SpecialRequests
+	return seat
+}
+
+func (r *FlightsRepository) validateSeats(seats []model.FlightSeat) error {
+	seatIDs := map[string]bool{}
+	for _, s := range seats {
+		if seatIDs[s.ID] {
+			return ErrDuplicatedSeatID
+		}
+		seatIDs[s.ID] = true
+	}
+	return nil
+}
+
+// validateTimeZone checks the time zone of the origin is a known IANA one, the
+// local dates of the flight would be in UTC or in the zone of the machine
+// otherwise
+func (r *FlightsRepository) validateTimeZone(m model.Flight) error {
+	if !model.IsTimeZone(m.OriginTimeZone) {
+		return ErrInvalidTimeZone
+	}
+	return nil
+}
+
+// hasFreeSeats tells if a seat is free, seats held until before now count as
+// free
+func (r *FlightsRepository) hasFreeSeats(seats []model.FlightSeat, now time.Time) bool {
+	for _, s := range seats {
+		if s.IsFree(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *FlightsRepository) hydrate(items []map[string]*dynamodb.AttributeValue) ([]model.Flight, error) {
+
+	flights := make([]model.Flight, len(items))
+	for i, item := range items {
+
+		if v, ok := item["id"]; ok {
+			flights[i].ID = *v.S
 		}
 		if v, ok := item["departure"]; ok {
 			flights[i].Departure = *v.S
 		}
+		if v, ok := item["aircraft_type"]; ok {
+			flights[i].AircraftType = *v.S
+		}
+		if v, ok := item["origin"]; ok {
+			flights[i].Origin = *v.S
+		}
+		if v, ok := item["destination"]; ok {
+			flights[i].Destination = *v.S
+		}
+		if v, ok := item["origin_time_zone"]; ok {
+			flights[i].OriginTimeZone = *v.S
+		}
+		if v, ok := item["carrier"]; ok {
+			flights[i].Carrier = *v.S
+		}
+		if v, ok := item["flight_number"]; ok {
+			flights[i].FlightNumber = *v.S
+		}
+		if v, ok := item["operating_date"]; ok {
+			flights[i].OperatingDate = *v.S
+		}
+		if v, ok := item["block_minutes"]; ok {
+			blockMinutes, err := strconv.Atoi(*v.N)
+			if err != nil {
+				return nil, err
+			}
+			flights[i].BlockMinutes = blockMinutes
+		}
+		if v, ok := item["checkin_sequence"]; ok {
+			sequence, err := strconv.Atoi(*v.N)
+			if err != nil {
+				return nil, err
+			}
+			flights[i].CheckInSequence = sequence
+		}
+		if v, ok := item["marketing_flights"]; ok {
+			flights[i].MarketingFlights = make([]model.MarketingFlight, len(v.L))
+			for j, marketing := range v.L {
+				if carrier, ok := marketing.M["carrier"]; ok {
+					flights[i].MarketingFlights[j].Carrier = *carrier.S
+				}
+				if flightNumber, ok := marketing.M["flight_number"]; ok {
+					flights[i].MarketingFlights[j].FlightNumber = *flightNumber.S
+				}
+			}
+		}
+		if v, ok := item["fares"]; ok {
+			fares, err := r.hydrateFares(v.M)
+			if err != nil {
+				return []model.Flight{}, err
+			}
+			flights[i].Fares = fares
+		}
 		if v, ok := item["has_free_seats"]; ok {
 			hasFreeSeats, err := strconv.ParseBool(*v.N)
 			if err != nil {
@@ -215,6 +2052,19 @@ func (r *FlightsRepository) hydrate(items []map[string]*dynamodb.AttributeValue)
 			}
 			flights[i].HasFreeSeats = hasFreeSeats
 		}
+		if v, ok := item["version"]; ok {
+			version, err := strconv.Atoi(*v.N)
+			if err != nil {
+				return []model.Flight{}, err
+			}
+			flights[i].Version = version
+		}
+		if v, ok := item["status"]; ok {
+			flights[i].Status = *v.S
+		}
+		if v, ok := item["status_history"]; ok {
+			flights[i].StatusHistory = r.hydrateStatusHistory(v.L)
+		}
 
 		if seatsList, ok := item["seats"]; ok {
 			seats, err := r.hydrateSeats(seatsList.L)
@@ -229,6 +2079,25 @@ func (r *FlightsRepository) hydrate(items []map[string]*dynamodb.AttributeValue)
 
 }
 
+func (r *FlightsRepository) hydrateStatusHistory(items []*dynamodb.AttributeValue) []model.FlightStatusChange {
+	history := make([]model.FlightStatusChange, len(items))
+	for i, item := range items {
+		if v, ok := item.M["status"]; ok {
+			history[i].Status = *v.S
+		}
+		if v, ok := item.M["departure"]; ok {
+			history[i].Departure = *v.S
+		}
+		if v, ok := item.M["reason"]; ok {
+			history[i].Reason = *v.S
+		}
+		if v, ok := item.M["changed_at"]; ok {
+			history[i].ChangedAt = *v.S
+		}
+	}
+	return history
+}
+
 func (r *FlightsRepository) hydrateSeats(items []*dynamodb.AttributeValue) ([]model.FlightSeat, error) {
 
 	seats := make([]model.FlightSeat, len(items))
@@ -245,6 +2114,43 @@ func (r *FlightsRepository) hydrateSeats(items []*dynamodb.AttributeValue) ([]mo
 		if v, ok := seatMap["passenger_id"]; ok && *v.S != "-" {
 			seats[i].PassengerID = *v.S
 		}
+		if v, ok := seatMap["position"]; ok {
+			seats[i].Position = *v.S
+		}
+		if v, ok := seatMap["cabin"]; ok {
+			seats[i].Cabin = *v.S
+		}
+		if v, ok := seatMap["exit_row"]; ok {
+			seats[i].ExitRow = *v.BOOL
+		}
+		if v, ok := seatMap["extra_legroom"]; ok {
+			seats[i].ExtraLegroom = *v.BOOL
+		}
+		if v, ok := seatMap["aisle_after"]; ok {
+			seats[i].AisleAfter = *v.BOOL
+		}
+		if v, ok := seatMap["held_until"]; ok {
+			seats[i].HeldUntil = *v.S
+		}
+		if v, ok := seatMap["payment_id"]; ok {
+			seats[i].PaymentID = *v.S
+		}
+		if v, ok := seatMap["locator"]; ok {
+			seats[i].Locator = *v.S
+		}
+		if v, ok := seatMap["checked_in_at"]; ok {
+			seats[i].CheckedInAt = *v.S
+		}
+		if v, ok := seatMap["special_requests"]; ok {
+			seats[i].SpecialRequests = hydrateStrings(v.L)
+		}
+		if v, ok := seatMap["price"]; ok {
+			price, err := hydratePrice(v.M)
+			if err != nil {
+				return []model.FlightSeat{}, err
+			}
+			seats[i].Price = price
+		}
 		if v, ok := seatMap["row"]; ok {
 			intVal, err := strconv.Atoi(*v.N)
 			if err != nil {
@@ -257,9 +2163,96 @@ func (r *FlightsRepository) hydrateSeats(items []*dynamodb.AttributeValue) ([]mo
 	return seats, nil
 }
 
-func NewFlightsRepository(client *dynamodb.DynamoDB, table string) *FlightsRepository {
+func (r *FlightsRepository) hydrateFares(item map[string]*dynamodb.AttributeValue) (model.Fares, error) {
+	amounts := func(values map[string]*dynamodb.AttributeValue) (map[string]int64, error) {
+		m := map[string]int64{}
+		for k, v := range values {
+			amount, err := strconv.ParseInt(*v.N, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = amount
+		}
+		return m, nil
+	}
+
+	fares := model.Fares{}
+	if v, ok := item["currency"]; ok {
+		fares.Currency = *v.S
+	}
+	if v, ok := item["cabins"]; ok {
+		cabins, err := amounts(v.M)
+		if err != nil {
+			return model.Fares{}, err
+		}
+		fares.Cabins = cabins
+	}
+	if v, ok := item["surcharges"]; ok {
+		surcharges, err := amounts(v.M)
+		if err != nil {
+			return model.Fares{}, err
+		}
+		fares.Surcharges = surcharges
+	}
+	return fares, nil
+}
+
+func hydrateStrings(items []*dynamodb.AttributeValue) []string {
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = *item.S
+	}
+	return values
+}
+
+func hydratePrice(item map[string]*dynamodb.AttributeValue) (model.Price, error) {
+	price := model.Price{}
+	if v, ok := item["currency"]; ok {
+		price.Currency = *v.S
+	}
+	if v, ok := item["amount"]; ok {
+		amount, err := strconv.ParseInt(*v.N, 10, 64)
+		if err != nil {
+			return model.Price{}, err
+		}
+		price.Amount = amount
+	}
+	return price, nil
+}
+
+// route is the key of the by_route_and_departure index
+func route(origin string, destination string) string {
+	return origin + "-" + destination
+}
+
+// queryAll follows the pages of the query, a single one stops at 1 MB
+func queryAll(client *dynamodb.DynamoDB, input *dynamodb.QueryInput) ([]map[string]*dynamodb.AttributeValue, error) {
+	items := []map[string]*dynamodb.AttributeValue{}
+	err := client.QueryPages(input, func(out *dynamodb.QueryOutput, lastPage bool) bool {
+		items = append(items, out.Items...)
+		return true
+	})
+	return items, err
+}
+
+func isConditionalCheckFailed(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+	}
+	return false
+}
+
+func isTransactionCanceled(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == dynamodb.ErrCodeTransactionCanceledException
+	}
+	return false
+}
+
+func NewFlightsRepository(client *dynamodb.DynamoDB, table string, reservationsTable string) *FlightsRepository {
 	return &FlightsRepository{
-		client: client,
-		table:  table,
+		client:            client,
+		table:             table,
+		reservationsTable: reservationsTable,
 	}
 }
@@ -31,6 +31,18 @@ func createFlightsTable(client *dynamodb.DynamoDB, table string, t *testing.T) {
 				AttributeName: aws.String("departure"),
 				AttributeType: aws.String("S"),
 			},
+			{
+				AttributeName: aws.String("route"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("designator"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("operating_date"),
+				AttributeType: aws.String("S"),
+			},
 		},
 		KeySchema: []*dynamodb.KeySchemaElement{
 			{
@@ -63,6 +75,46 @@ func createFlightsTable(client *dynamodb.DynamoDB, table string, t *testing.T) {
 					WriteCapacityUnits: aws.Int64(5),
 				},
 			},
+			{
+				IndexName: aws.String("by_route_and_departure"),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("route"),
+						KeyType:       aws.String("HASH"),
+					},
+					{
+						AttributeName: aws.String("departure"),
+						KeyType:       aws.String("RANGE"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(5),
+					WriteCapacityUnits: aws.Int64(5),
+				},
+			},
+			{
+				IndexName: aws.String("by_designator_and_operating_date"),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("designator"),
+						KeyType:       aws.String("HASH"),
+					},
+					{
+						AttributeName: aws.String("operating_date"),
+						KeyType:       aws.String("RANGE"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(5),
+					WriteCapacityUnits: aws.Int64(5),
+				},
+			},
 		},
 	})
 	if err != nil {
@@ -70,82 +122,275 @@ func createFlightsTable(client *dynamodb.DynamoDB, table string, t *testing.T) {
 	}
 }
 
+func createReservationsTable(client *dynamodb.DynamoDB, table string, t *testing.T) {
+	_, err := client.CreateTable(&dynamodb.CreateTableInput{
+		TableName: aws.String(table),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("locator"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("flight_id"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("passenger_id"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("departure"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("locator"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("flight_id"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String("by_passenger_and_departure"),
+				KeySchema: []*dynamodb.KeySchemaElement{
+					{
+						AttributeName: aws.String("passenger_id"),
+						KeyType:       aws.String("HASH"),
+					},
+					{
+						AttributeName: aws.String("departure"),
+						KeyType:       aws.String("RANGE"),
+					},
+				},
+				Projection: &dynamodb.Projection{
+					ProjectionType: aws.String("ALL"),
+				},
+				ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(5),
+					WriteCapacityUnits: aws.Int64(5),
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Errorf("Error while creating reservations table: %v\n", err)
+	}
+}
+
 func TestFlightsRepository_SaveAndFind(t *testing.T) {
 
 	table := "flights"
+	reservationsTable := "reservations"
 	closer, client := internal.DynamodbStart(t)
 	defer closer()
 	createFlightsTable(client, table, t)
-	flightsRepo := NewFlightsRepository(client, table)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
 
 	flightsToSave := []model.Flight{
 		{
-			ID:        "f2",
-			Departure: "2019-11-26T09:05:00+0000",
+			ID:             "f1",
+			Departure:      "2019-11-26T09:05:00+0000",
+			OriginTimeZone: "UTC",
+			AircraftType:   "A320",
+			Fares: model.Fares{
+				Currency: "USD",
+				Cabins: map[string]int64{
+					model.CabinEconomy: 10000,
+				},
+				Surcharges: map[string]int64{
+					model.SurchargeExitRow: 2000,
+				},
+			},
 			Seats: []model.FlightSeat{
 				{
-					ID:     "s1",
-					Letter: "A",
-					Row:    1,
+					ID:           "s1",
+					Letter:       "A",
+					Row:          1,
+					Position:     model.SeatPositionWindow,
+					Cabin:        model.CabinEconomy,
+					ExitRow:      true,
+					ExtraLegroom: true,
 				},
 				{
-					ID:     "s1",
-					Letter: "A",
+					ID:     "s2",
+					Letter: "B",
 					Row:    1,
 				},
 			},
 		},
 		{
-			ID:        "f2",
-			Departure: "2019-11-26T09:05:00+0000",
+			ID:             "f2",
+			Departure:      "2019-11-26T09:05:00+0000",
+			OriginTimeZone: "UTC",
 			Seats: []model.FlightSeat{
 				{
-					ID:     "s1",
-					Letter: "A",
-					Row:    1,
-				},
-				{
-					ID:     "s1",
-					Letter: "A",
-					Row:    1,
+					ID:          "s1",
+					Letter:      "A",
+					Row:         1,
+					PassengerID: "p1",
 				},
 			},
 		},
 	}
 
-	for _, f := range flightsToSave {
-		_, err := flightsRepo.Save(f)
+	savedFlights := make([]model.Flight, len(flightsToSave))
+	for i, f := range flightsToSave {
+		savedFlight, err := flightsRepo.Save(f)
 		if err != nil {
 			t.Errorf("Error while saving flight: %v", err)
 		}
+		savedFlights[i] = savedFlight
 	}
 
+	require.True(t, savedFlights[0].HasFreeSeats)
+	require.False(t, savedFlights[1].HasFreeSeats)
 	for i, f := range flightsToSave {
 		foundFlight, err := flightsRepo.Find(f.ID)
 		if err != nil {
 			t.Errorf("Error while finding flight: %v\n", err)
 		}
-		if diff := cmp.Diff(flightsToSave[i], foundFlight); diff != "" {
+		if diff := cmp.Diff(savedFlights[i], foundFlight); diff != "" {
 			t.Errorf("Error while finding flight: (-want,+got)\n%s", diff)
 		}
 	}
 
+	_, err := flightsRepo.Save(model.Flight{
+		ID: "f3",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "s1",
+				Letter: "A",
+				Row:    1,
+			},
+			{
+				ID:     "s1",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	})
+	require.Equal(t, ErrDuplicatedSeatID, err)
+
+}
+
+func TestFlightsRepository_SavePreservesPassengers(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	flightToSave := model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "s1",
+				Letter: "A",
+				Row:    1,
+			},
+			{
+				ID:     "s2",
+				Letter: "B",
+				Row:    1,
+			},
+		},
+	}
+	_, err := flightsRepo.Save(flightToSave)
+	require.NoError(t, err)
+	_, err = flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "s1", PassengerID: "p1"})
+	require.NoError(t, err)
+
+	// Act
+	flightToSave.Departure = "2019-11-27T09:05:00+0000"
+	savedFlight, err := flightsRepo.Save(flightToSave)
+
+	// Assert
+	require.NoError(t, err)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, savedFlight, foundFlight)
+	require.Equal(t, "2019-11-27T09:05:00+0000", foundFlight.Departure)
+	require.Equal(t, "p1", foundFlight.Seats[0].PassengerID)
+	require.True(t, foundFlight.HasFreeSeats)
+
+	// A seat holding a passenger can not be removed
+	flightToSave.Seats = flightToSave.Seats[1:]
+	_, err = flightsRepo.Save(flightToSave)
+	require.Equal(t, ErrPassengerSeatRemoved, err)
+}
+
+func TestFlightsRepository_SaveRejectsStaleVersion(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	savedFlight, err := flightsRepo.Save(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "s1",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, savedFlight.Version)
+
+	// Act, somebody else updates the flight after it was read
+	staleFlight := savedFlight
+	savedFlight.Departure = "2019-11-27T09:05:00+0000"
+	savedFlight, err = flightsRepo.Save(savedFlight)
+	require.NoError(t, err)
+	require.Equal(t, 2, savedFlight.Version)
+
+	staleFlight.Departure = "2019-11-28T09:05:00+0000"
+	_, err = flightsRepo.Save(staleFlight)
+
+	// Assert
+	require.Equal(t, ErrStaleFlight, err)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, "2019-11-27T09:05:00+0000", foundFlight.Departure)
 }
 
 func TestFlightsRepository_ListFlightsByDeparture(t *testing.T) {
 
 	// Arrange
 	table := "flights"
+	reservationsTable := "reservations"
 	closer, client := internal.DynamodbStart(t)
 	defer closer()
 	createFlightsTable(client, table, t)
-	flightsRepo := NewFlightsRepository(client, table)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
 
 	flightsToSave := []model.Flight{
 		{
-			ID:           "f2",
-			Departure:    "2019-11-26T09:05:00+0000",
-			HasFreeSeats: true,
+			ID:             "f1",
+			Departure:      "2019-11-26T09:05:00+0000",
+			OriginTimeZone: "UTC",
 			Seats: []model.FlightSeat{
 				{
 					ID:     "s1",
@@ -153,16 +398,16 @@ func TestFlightsRepository_ListFlightsByDeparture(t *testing.T) {
 					Row:    1,
 				},
 				{
-					ID:     "s1",
-					Letter: "A",
+					ID:     "s2",
+					Letter: "B",
 					Row:    1,
 				},
 			},
 		},
 		{
-			ID:           "f2",
-			Departure:    "2019-11-22T09:05:00+0000",
-			HasFreeSeats: true,
+			ID:             "f2",
+			Departure:      "2019-11-22T09:05:00+0000",
+			OriginTimeZone: "UTC",
 			Seats: []model.FlightSeat{
 				{
 					ID:     "s1",
@@ -170,16 +415,16 @@ func TestFlightsRepository_ListFlightsByDeparture(t *testing.T) {
 					Row:    1,
 				},
 				{
-					ID:     "s1",
-					Letter: "A",
+					ID:     "s2",
+					Letter: "B",
 					Row:    1,
 				},
 			},
 		},
 		{
-			ID:           "f3",
-			Departure:    "2019-11-24T09:05:00+0000",
-			HasFreeSeats: true,
+			ID:             "f3",
+			Departure:      "2019-11-24T09:05:00+0000",
+			OriginTimeZone: "UTC",
 			Seats: []model.FlightSeat{
 				{
 					ID:     "s1",
@@ -187,40 +432,86 @@ func TestFlightsRepository_ListFlightsByDeparture(t *testing.T) {
 					Row:    1,
 				},
 				{
-					ID:     "s1",
-					Letter: "A",
+					ID:     "s2",
+					Letter: "B",
 					Row:    1,
 				},
 			},
 		},
 	}
 
-	for _, f := range flightsToSave {
-		_, err := flightsRepo.Save(f)
+	savedFlights := make([]model.Flight, len(flightsToSave))
+	for i, f := range flightsToSave {
+		savedFlight, err := flightsRepo.Save(f)
 		require.NoError(t, err)
+		savedFlights[i] = savedFlight
 	}
 
 	// Act
 	foundFlights, err := flightsRepo.ListFlightsByDeparture("2019-11-21T00:00:00+0000", "2019-11-25T00:00:00+0000")
 	require.NoError(t, err)
 	require.Len(t, foundFlights, 2)
-	require.Contains(t, foundFlights, flightsToSave[1])
-	require.Contains(t, foundFlights, flightsToSave[2])
+	require.Contains(t, foundFlights, savedFlights[1])
+	require.Contains(t, foundFlights, savedFlights[2])
+}
+
+func TestFlightsRepository_ListFlightsByDepartureFollowsPages(t *testing.T) {
+
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	// Wide-bodies are big enough to fill more than the 1 MB of a page
+	seats := []model.FlightSeat{}
+	for row := 1; row <= 50; row++ {
+		for _, letter := range []string{"A", "B", "C", "D", "E", "F", "G", "H"} {
+			seats = append(seats, model.FlightSeat{
+				ID:     fmt.Sprintf("%v%v", row, letter),
+				Letter: letter,
+				Row:    row,
+			})
+		}
+	}
+	flights := 60
+	for i := 0; i < flights; i++ {
+		_, err := flightsRepo.Save(model.Flight{
+			ID:             fmt.Sprintf("f%v", i),
+			Departure:      "2019-11-22T09:05:00+0000",
+			OriginTimeZone: "UTC",
+			Seats:          seats,
+		})
+		require.NoError(t, err)
+	}
+
+	// Act
+	foundFlights, err := flightsRepo.ListFlightsByDeparture("2019-11-21T00:00:00+0000", "2019-11-25T00:00:00+0000")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, foundFlights, flights)
 }
 
 func TestFlightsRepository_ReserveSeat(t *testing.T) {
 	// Arrange
 	table := "flights"
+	reservationsTable := "reservations"
 	closer, client := internal.DynamodbStart(t)
 	defer closer()
 	createFlightsTable(client, table, t)
-	flightsRepo := NewFlightsRepository(client, table)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
 
 	// Save a flight so we can try to reserve a seat on it
 	flightToSave := model.Flight{
-		ID:           "f2",
-		Departure:    "2019-11-26T09:05:00+0000",
-		HasFreeSeats: true,
+		ID:             "f2",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		HasFreeSeats:   true,
 		Seats: []model.FlightSeat{
 			{
 				ID:     "s1",
@@ -246,18 +537,24 @@ func TestFlightsRepository_ReserveSeat(t *testing.T) {
 	mux := sync.Mutex{}
 	success := 0
 	passengerWinner := ""
+	locatorWinner := ""
 	launchTime := time.Now()
 	for i := 0; i < limit; i++ {
 		go func(ii int) {
 			time.Sleep(time.Until(launchTime))
 			log.Printf("Launching test [%v] at %v\n", ii, time.Now())
 			mux.Lock()
-			err := flightsRepo.ReserveSeat("f2", "s1", fmt.Sprintf("%v", ii))
+			reservation, err := flightsRepo.ReserveSeat(model.Reservation{
+				FlightID:    "f2",
+				SeatID:      "s1",
+				PassengerID: fmt.Sprintf("%v", ii),
+			})
 			if err != nil {
 				log.Printf("[%v] unable to reserve seat\n", ii)
 			} else {
 				log.Printf("[%v] GOT THE SEAT!", ii)
 				passengerWinner = fmt.Sprintf("%v", ii)
+				locatorWinner = reservation.Locator
 				success++
 			}
 			mux.Unlock()
@@ -275,6 +572,1122 @@ func TestFlightsRepository_ReserveSeat(t *testing.T) {
 		Letter:      "A",
 		Row:         1,
 		PassengerID: passengerWinner,
+		Locator:     locatorWinner,
 	})
 
 }
+
+func TestFlightsRepository_ReserveSeatsConcurrentlyKeepsFreeSeats(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{ID: "1A", Letter: "A", Row: 1},
+			{ID: "1B", Letter: "B", Row: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	// Act, both counted the other seat as free when they read the flight
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	for _, seatID := range []string{"1A", "1B"} {
+		go func(seatID string) {
+			defer wg.Done()
+			_, err := flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: seatID, PassengerID: "p" + seatID})
+			if err != nil {
+				log.Printf("[%v] unable to reserve seat: %v\n", seatID, err)
+			}
+		}(seatID)
+	}
+	wg.Wait()
+
+	// Assert
+	flight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	free := false
+	for _, s := range flight.Seats {
+		if s.PassengerID == "" {
+			free = true
+		}
+	}
+	require.Equal(t, free, flight.HasFreeSeats)
+}
+
+func TestFlightsRepository_Create(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	flightToCreate := model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+			{
+				ID:     "1B",
+				Letter: "B",
+				Row:    1,
+			},
+		},
+	}
+
+	// Act
+	created, err := flightsRepo.Create(flightToCreate)
+
+	// Assert
+	require.NoError(t, err)
+	require.True(t, created.HasFreeSeats)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, created, foundFlight)
+
+	require.Equal(t, 1, created.Version)
+
+	_, err = flightsRepo.Create(flightToCreate)
+	require.Equal(t, ErrFlightAlreadyExists, err)
+
+	withUnknownTimeZone := flightToCreate
+	withUnknownTimeZone.ID = "f2"
+	withUnknownTimeZone.OriginTimeZone = "America/Nowhere"
+	_, err = flightsRepo.Create(withUnknownTimeZone)
+	require.Equal(t, ErrInvalidTimeZone, err)
+	_, err = flightsRepo.Save(withUnknownTimeZone)
+	require.Equal(t, ErrInvalidTimeZone, err)
+
+	withoutTimeZone := flightToCreate
+	withoutTimeZone.ID = "f2"
+	withoutTimeZone.OriginTimeZone = ""
+	_, err = flightsRepo.Create(withoutTimeZone)
+	require.Equal(t, ErrInvalidTimeZone, err)
+	_, err = flightsRepo.Save(withoutTimeZone)
+	require.Equal(t, ErrInvalidTimeZone, err)
+
+	withLocalTimeZone := flightToCreate
+	withLocalTimeZone.ID = "f2"
+	withLocalTimeZone.OriginTimeZone = "Local"
+	_, err = flightsRepo.Create(withLocalTimeZone)
+	require.Equal(t, ErrInvalidTimeZone, err)
+	_, err = flightsRepo.Save(withLocalTimeZone)
+	require.Equal(t, ErrInvalidTimeZone, err)
+
+	duplicatedSeats := flightToCreate
+	duplicatedSeats.ID = "f2"
+	duplicatedSeats.Seats = []model.FlightSeat{flightToCreate.Seats[0], flightToCreate.Seats[0]}
+	_, err = flightsRepo.Create(duplicatedSeats)
+	require.Equal(t, ErrDuplicatedSeatID, err)
+}
+
+func TestFlightsRepository_UpdateDeparture(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	reservation, err := flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "1A", PassengerID: "p1"})
+	require.NoError(t, err)
+
+	// Act
+	err = flightsRepo.UpdateDeparture("f1", "2019-11-27T09:05:00+0000")
+
+	// Assert
+	require.NoError(t, err)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, "2019-11-27T09:05:00+0000", foundFlight.Departure)
+	require.Equal(t, "p1", foundFlight.Seats[0].PassengerID)
+	reservations, err := NewReservationsRepository(client, reservationsTable).FindByLocator(reservation.Locator)
+	require.NoError(t, err)
+	require.Equal(t, "2019-11-27T09:05:00+0000", reservations[0].Departure)
+
+	err = flightsRepo.UpdateDeparture("f2", "2019-11-27T09:05:00+0000")
+	require.Equal(t, ErrNoFlightsFound, err)
+}
+
+func TestFlightsRepository_Delete(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	for _, id := range []string{"f1", "f2"} {
+		_, err := flightsRepo.Create(model.Flight{
+			ID:             id,
+			Departure:      "2019-11-26T09:05:00+0000",
+			OriginTimeZone: "UTC",
+			Seats: []model.FlightSeat{
+				{
+					ID:     "1A",
+					Letter: "A",
+					Row:    1,
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+	_, err := flightsRepo.ReserveSeat(model.Reservation{FlightID: "f2", SeatID: "1A", PassengerID: "p1"})
+	require.NoError(t, err)
+
+	// Act & Assert
+	require.NoError(t, flightsRepo.Delete("f1"))
+	_, err = flightsRepo.Find("f1")
+	require.Equal(t, ErrNoFlightsFound, err)
+
+	require.Equal(t, ErrFlightHasPassengers, flightsRepo.Delete("f2"))
+	require.Equal(t, ErrNoFlightsFound, flightsRepo.Delete("f3"))
+}
+
+func TestFlightsRepository_ReserveSeatStoresPrice(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+			{
+				ID:     "1B",
+				Letter: "B",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// Act
+	reservation, err := flightsRepo.ReserveSeat(model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "1B",
+		PassengerID: "p1",
+		Price: model.Price{
+			Amount:   11500,
+			Currency: "USD",
+		},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, model.FlightSeat{
+		ID:          "1B",
+		Letter:      "B",
+		Row:         1,
+		PassengerID: "p1",
+		Price: model.Price{
+			Amount:   11500,
+			Currency: "USD",
+		},
+		Locator: reservation.Locator,
+	}, foundFlight.Seats[1])
+}
+
+func TestFlightsRepository_HoldAndReleaseSeat(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	reservation := model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "p1",
+	}
+
+	// Act & Assert
+	err = flightsRepo.HoldSeat(reservation, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	err = flightsRepo.HoldSeat(reservation, time.Now().Add(2*time.Minute))
+	require.NoError(t, err)
+
+	err = flightsRepo.HoldSeat(model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "p2",
+	}, time.Now().Add(time.Minute))
+	require.Equal(t, ErrSeatNotAvailable, err)
+
+	err = flightsRepo.ReleaseSeat("f1", "1A", "p2")
+	require.Equal(t, ErrSeatNotHeld, err)
+
+	err = flightsRepo.ReleaseSeat("f1", "1A", "p1")
+	require.NoError(t, err)
+
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.True(t, foundFlight.HasFreeSeats)
+	require.Equal(t, "", foundFlight.Seats[0].HeldUntil)
+
+	err = flightsRepo.HoldSeat(reservation, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	_, err = flightsRepo.ReserveSeat(model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "p2",
+	})
+	require.NoError(t, err)
+}
+
+func TestFlightsRepository_ReleaseExpiredSeat(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	reservation := model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "p1",
+	}
+	now := time.Now()
+
+	// Act & Assert
+	err = flightsRepo.HoldSeat(reservation, now.Add(time.Minute))
+	require.NoError(t, err)
+
+	err = flightsRepo.ReleaseExpiredSeat("f1", "1A", "p1", now)
+	require.Equal(t, ErrSeatHoldActive, err)
+
+	err = flightsRepo.ReleaseExpiredSeat("f1", "1A", "p2", now.Add(2*time.Minute))
+	require.Equal(t, ErrSeatNotHeld, err)
+
+	err = flightsRepo.ReleaseExpiredSeat("f1", "1A", "p1", now.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.True(t, foundFlight.HasFreeSeats)
+	require.Equal(t, "", foundFlight.Seats[0].PassengerID)
+}
+
+func TestFlightsRepository_ReserveSeatCreatesReservation(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// Act
+	reservation, err := flightsRepo.ReserveSeat(model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "p1",
+		PaymentID:   "auth-1",
+
+		SpecialRequests: []string{"WCHR", "VGML"},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, reservation.Locator, locatorLength)
+	flight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"WCHR", "VGML"}, flight.Seats[0].SpecialRequests)
+	reservationsRepo := NewReservationsRepository(client, reservationsTable)
+	foundReservations, err := reservationsRepo.FindByLocator(reservation.Locator)
+	require.NoError(t, err)
+	if diff := cmp.Diff([]model.Reservation{reservation}, foundReservations); diff != "" {
+		t.Errorf("FindByLocator() mismatch (-want +got):\n%s", diff)
+	}
+	require.Equal(t, "2019-11-26T09:05:00+0000", foundReservations[0].Departure)
+
+	_, err = flightsRepo.ReserveSeat(model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "p2",
+	})
+	require.Equal(t, ErrSeatNotAvailable, err)
+
+	_, err = reservationsRepo.FindByLocator("NOPE42")
+	require.Equal(t, ErrNoReservationsFound, err)
+}
+
+func TestFlightsRepository_ReserveItinerary(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	for _, flight := range []model.Flight{
+		{
+			ID:             "f1",
+			Departure:      "2019-11-26T09:05:00+0000",
+			OriginTimeZone: "UTC",
+			Origin:         "BOG",
+			Destination:    "MDE",
+			Seats: []model.FlightSeat{
+				{
+					ID:     "1A",
+					Letter: "A",
+					Row:    1,
+				},
+			},
+		},
+		{
+			ID:             "f2",
+			Departure:      "2019-11-26T12:00:00+0000",
+			OriginTimeZone: "UTC",
+			Origin:         "MDE",
+			Destination:    "CTG",
+			Seats: []model.FlightSeat{
+				{
+					ID:     "3C",
+					Letter: "C",
+					Row:    3,
+				},
+			},
+		},
+	} {
+		_, err := flightsRepo.Create(flight)
+		require.NoError(t, err)
+	}
+
+	// Act
+	reserved, err := flightsRepo.ReserveItinerary([]model.Reservation{
+		{FlightID: "f1", SeatID: "1A", PassengerID: "p1"},
+		{FlightID: "f2", SeatID: "3C", PassengerID: "p1"},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, reserved, 2)
+	require.Len(t, reserved[0].Locator, locatorLength)
+	require.Equal(t, reserved[0].Locator, reserved[1].Locator)
+	reservationsRepo := NewReservationsRepository(client, reservationsTable)
+	foundReservations, err := reservationsRepo.FindByLocator(reserved[0].Locator)
+	require.NoError(t, err)
+	require.Len(t, foundReservations, 2)
+
+	// The first seat is free but the second is taken, neither gets reserved
+	_, err = flightsRepo.Create(model.Flight{
+		ID:             "f3",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Origin:         "BOG",
+		Destination:    "MDE",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	_, err = flightsRepo.ReserveItinerary([]model.Reservation{
+		{FlightID: "f3", SeatID: "1A", PassengerID: "p2"},
+		{FlightID: "f2", SeatID: "3C", PassengerID: "p2"},
+	})
+	require.Equal(t, ErrSeatNotAvailable, err)
+	flight, err := flightsRepo.Find("f3")
+	require.NoError(t, err)
+	require.Equal(t, "", flight.Seats[0].PassengerID)
+}
+
+func TestFlightsRepository_ReserveSeatClaimsLocator(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	for _, id := range []string{"f1", "f2", "f3"} {
+		_, err := flightsRepo.Create(model.Flight{
+			ID:             id,
+			Departure:      "2019-11-26T09:05:00+0000",
+			OriginTimeZone: "UTC",
+			Seats: []model.FlightSeat{
+				{
+					ID:     "1A",
+					Letter: "A",
+					Row:    1,
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	// Act
+	_, err := flightsRepo.ReserveSeat(model.Reservation{Locator: "K7QM2X", FlightID: "f1", SeatID: "1A", PassengerID: "p1"})
+	require.NoError(t, err)
+	_, errOtherPassenger := flightsRepo.ReserveSeat(model.Reservation{Locator: "K7QM2X", FlightID: "f2", SeatID: "1A", PassengerID: "p2"})
+	_, errSamePassenger := flightsRepo.ReserveSeat(model.Reservation{Locator: "K7QM2X", FlightID: "f3", SeatID: "1A", PassengerID: "p1"})
+
+	// Assert
+	require.Equal(t, ErrLocatorTaken, errOtherPassenger)
+	require.NoError(t, errSamePassenger)
+	flight, err := flightsRepo.Find("f2")
+	require.NoError(t, err)
+	require.Equal(t, "", flight.Seats[0].PassengerID)
+	reservationsRepo := NewReservationsRepository(client, reservationsTable)
+	found, err := reservationsRepo.FindByLocator("K7QM2X")
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, "p1", found[0].PassengerID)
+	require.Equal(t, "p1", found[1].PassengerID)
+}
+
+func TestFlightsRepository_UpdateStatus(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	reservation, err := flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "1A", PassengerID: "p1"})
+	require.NoError(t, err)
+
+	// Act
+	delayed, err := flightsRepo.UpdateStatus("f1", model.FlightStatusChange{
+		Status:    model.FlightStatusDelayed,
+		Departure: "2019-11-26T11:05:00+0000",
+		ChangedAt: "2019-11-26T08:00:00Z",
+	})
+	require.NoError(t, err)
+	_, err = flightsRepo.UpdateStatus("f1", model.FlightStatusChange{
+		Status:    model.FlightStatusCancelled,
+		Reason:    "weather",
+		ChangedAt: "2019-11-26T10:00:00Z",
+	})
+	require.NoError(t, err)
+
+	// Assert
+	require.Equal(t, "2019-11-26T11:05:00+0000", delayed.Departure)
+	reservations, err := NewReservationsRepository(client, reservationsTable).FindByLocator(reservation.Locator)
+	require.NoError(t, err)
+	require.Equal(t, "2019-11-26T11:05:00+0000", reservations[0].Departure)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, model.FlightStatusCancelled, foundFlight.Status)
+	require.False(t, foundFlight.HasFreeSeats)
+	want := []model.FlightStatusChange{
+		{
+			Status:    model.FlightStatusDelayed,
+			Departure: "2019-11-26T11:05:00+0000",
+			ChangedAt: "2019-11-26T08:00:00Z",
+		},
+		{
+			Status:    model.FlightStatusCancelled,
+			Departure: "2019-11-26T11:05:00+0000",
+			Reason:    "weather",
+			ChangedAt: "2019-11-26T10:00:00Z",
+		},
+	}
+	if diff := cmp.Diff(want, foundFlight.StatusHistory); diff != "" {
+		t.Errorf("Differences found: (-want,+got)\n%s", diff)
+	}
+
+	_, err = flightsRepo.UpdateStatus("f1", model.FlightStatusChange{Status: model.FlightStatusBoarding})
+	require.Equal(t, ErrInvalidStatusChange, err)
+	_, err = flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "1A", PassengerID: "p2"})
+	require.Equal(t, ErrFlightClosed, err)
+}
+
+func TestFlightsRepository_UpdateStatusMovesEveryReservation(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+	reservationsRepo := NewReservationsRepository(client, reservationsTable)
+
+	// More passengers than items fit in a transaction
+	seats := []model.FlightSeat{}
+	for row := 1; row <= 30; row++ {
+		seats = append(seats, model.FlightSeat{ID: fmt.Sprintf("%vA", row), Letter: "A", Row: row})
+	}
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats:          seats,
+	})
+	require.NoError(t, err)
+	locators := []string{}
+	for _, seat := range seats {
+		reservation, err := flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: seat.ID, PassengerID: "p" + seat.ID})
+		require.NoError(t, err)
+		locators = append(locators, reservation.Locator)
+	}
+
+	// Act
+	_, err = flightsRepo.UpdateStatus("f1", model.FlightStatusChange{
+		Status:    model.FlightStatusDelayed,
+		Departure: "2019-11-26T11:05:00+0000",
+		ChangedAt: "2019-11-26T08:00:00Z",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	for _, locator := range locators {
+		reservations, err := reservationsRepo.FindByLocator(locator)
+		require.NoError(t, err)
+		require.Equal(t, "2019-11-26T11:05:00+0000", reservations[0].Departure)
+	}
+}
+
+func TestFlightsRepository_ChangeSeat(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+	reservationsRepo := NewReservationsRepository(client, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "23B",
+				Letter: "B",
+				Row:    23,
+			},
+			{
+				ID:     "23C",
+				Letter: "C",
+				Row:    23,
+			},
+			{
+				ID:     "24C",
+				Letter: "C",
+				Row:    24,
+			},
+		},
+	})
+	require.NoError(t, err)
+	reservation, err := flightsRepo.ReserveSeat(model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "23B",
+		PassengerID: "p1",
+		Price: model.Price{
+			Amount:   11500,
+			Currency: "USD",
+		},
+		SpecialRequests: []string{"WCHR"},
+	})
+	require.NoError(t, err)
+	_, err = flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "24C", PassengerID: "p2"})
+	require.NoError(t, err)
+
+	// Act
+	changed, err := flightsRepo.ChangeSeat("f1", "23B", "23C", "p1")
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, reservation.Locator, changed.Locator)
+	require.Equal(t, "23C", changed.SeatID)
+	require.Equal(t, []string{"WCHR"}, changed.SpecialRequests)
+
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, model.FlightSeat{
+		ID:     "23B",
+		Letter: "B",
+		Row:    23,
+	}, foundFlight.Seats[0])
+	require.Equal(t, model.FlightSeat{
+		ID:          "23C",
+		Letter:      "C",
+		Row:         23,
+		PassengerID: "p1",
+		Price: model.Price{
+			Amount:   11500,
+			Currency: "USD",
+		},
+		Locator:         reservation.Locator,
+		SpecialRequests: []string{"WCHR"},
+	}, foundFlight.Seats[1])
+
+	foundReservations, err := reservationsRepo.FindByLocator(reservation.Locator)
+	require.NoError(t, err)
+	require.Equal(t, "23C", foundReservations[0].SeatID)
+
+	_, err = flightsRepo.ChangeSeat("f1", "23C", "24C", "p1")
+	require.Equal(t, ErrSeatNotAvailable, err)
+	_, err = flightsRepo.ChangeSeat("f1", "23B", "23C", "p1")
+	require.Equal(t, ErrPassengerNotInSeat, err)
+	_, err = flightsRepo.ChangeSeat("f1", "23C", "99Z", "p1")
+	require.Equal(t, ErrNoSeatFoundInFlight, err)
+}
+
+func TestFlightsRepository_MoveReservation(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+	reservationsRepo := NewReservationsRepository(client, reservationsTable)
+
+	for _, f := range []model.Flight{
+		{
+			ID:             "f1",
+			Departure:      "2019-11-26T09:05:00+0000",
+			OriginTimeZone: "UTC",
+			Origin:         "BOG",
+			Destination:    "MDE",
+			Seats: []model.FlightSeat{
+				{
+					ID:     "1A",
+					Letter: "A",
+					Row:    1,
+				},
+			},
+		},
+		{
+			ID:             "f2",
+			Departure:      "2019-11-26T15:05:00+0000",
+			OriginTimeZone: "UTC",
+			Origin:         "BOG",
+			Destination:    "MDE",
+			Seats: []model.FlightSeat{
+				{
+					ID:     "3C",
+					Letter: "C",
+					Row:    3,
+				},
+			},
+		},
+		{
+			ID:             "f3",
+			Departure:      "2019-11-26T12:05:00+0000",
+			OriginTimeZone: "UTC",
+			Origin:         "MDE",
+			Destination:    "BOG",
+			Seats: []model.FlightSeat{
+				{
+					ID:     "2B",
+					Letter: "B",
+					Row:    2,
+				},
+			},
+		},
+	} {
+		_, err := flightsRepo.Create(f)
+		require.NoError(t, err)
+	}
+	reserved, err := flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "1A", PassengerID: "p1"})
+	require.NoError(t, err)
+	_, err = flightsRepo.UpdateStatus("f1", model.FlightStatusChange{Status: model.FlightStatusCancelled})
+	require.NoError(t, err)
+
+	// Act
+	flights, err := flightsRepo.ListFlightsByRoute("BOG", "MDE", "2019-11-26T09:05:00+0000", "2019-11-27T09:05:00+0000")
+	require.NoError(t, err)
+	moved, err := flightsRepo.MoveReservation("f1", "1A", "f2", "3C", "p1")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, flights, 2)
+	require.Equal(t, "f1", flights[0].ID)
+	require.Equal(t, "f2", flights[1].ID)
+	require.Equal(t, reserved.Locator, moved.Locator)
+	cancelled, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, "", cancelled.Seats[0].PassengerID)
+	require.False(t, cancelled.HasFreeSeats)
+	rebooked, err := flightsRepo.Find("f2")
+	require.NoError(t, err)
+	require.Equal(t, "p1", rebooked.Seats[0].PassengerID)
+	require.Equal(t, reserved.Locator, rebooked.Seats[0].Locator)
+	require.False(t, rebooked.HasFreeSeats)
+	reservations, err := reservationsRepo.FindByLocator(reserved.Locator)
+	require.NoError(t, err)
+	require.Len(t, reservations, 1)
+	require.Equal(t, "f2", reservations[0].FlightID)
+	require.Equal(t, "3C", reservations[0].SeatID)
+
+	_, err = flightsRepo.MoveReservation("f2", "3C", "f1", "1A", "p1")
+	require.Equal(t, ErrFlightClosed, err)
+
+	_, err = flightsRepo.MoveReservation("f2", "3C", "f3", "2B", "p1")
+	require.NoError(t, err)
+	reopened, err := flightsRepo.Find("f2")
+	require.NoError(t, err)
+	require.Equal(t, "", reopened.Seats[0].PassengerID)
+	require.True(t, reopened.HasFreeSeats)
+}
+
+func TestFlightsRepository_SwapAircraft(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+	reservationsRepo := NewReservationsRepository(client, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1F",
+				Letter: "F",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	reserved, err := flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "1F", PassengerID: "p1"})
+	require.NoError(t, err)
+	flight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+
+	// Act
+	flight.AircraftType = "TINY"
+	flight.Seats = []model.FlightSeat{
+		{
+			ID:          "1E",
+			Letter:      "E",
+			Row:         1,
+			PassengerID: "p1",
+			Locator:     reserved.Locator,
+		},
+		{
+			ID:     "1D",
+			Letter: "D",
+			Row:    1,
+		},
+	}
+	swapped, err := flightsRepo.SwapAircraft(flight, []model.SeatMove{
+		{
+			PassengerID: "p1",
+			Locator:     reserved.Locator,
+			FromSeatID:  "1F",
+			ToSeatID:    "1E",
+		},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.True(t, swapped.HasFreeSeats)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, "TINY", foundFlight.AircraftType)
+	require.Equal(t, "p1", foundFlight.Seats[0].PassengerID)
+	reservations, err := reservationsRepo.FindByLocator(reserved.Locator)
+	require.NoError(t, err)
+	require.Equal(t, "1E", reservations[0].SeatID)
+
+	_, err = flightsRepo.SwapAircraft(flight, nil)
+	require.Equal(t, ErrStaleFlight, err)
+
+	// The flight is kept as it was when a reservation record can not move
+	_, err = flightsRepo.SwapAircraft(foundFlight, []model.SeatMove{
+		{
+			PassengerID: "p1",
+			Locator:     reserved.Locator,
+			FromSeatID:  "1D",
+			ToSeatID:    "1E",
+		},
+	})
+	require.Equal(t, ErrReservationNotInSeat, err)
+	unchanged, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, foundFlight.Version, unchanged.Version)
+
+	moves := []model.SeatMove{}
+	for i := 0; i < maxTransactItems; i++ {
+		moves = append(moves, model.SeatMove{Locator: fmt.Sprintf("L%05d", i)})
+	}
+	_, err = flightsRepo.SwapAircraft(foundFlight, moves)
+	require.Equal(t, ErrTooManySeatMoves, err)
+
+	cancelled := foundFlight
+	cancelled.Status = model.FlightStatusCancelled
+	_, err = flightsRepo.SwapAircraft(cancelled, nil)
+	require.Equal(t, ErrFlightClosed, err)
+}
+
+func TestFlightsRepository_CheckIn(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	_, err := flightsRepo.Create(model.Flight{
+		ID:             "f1",
+		Departure:      "2019-11-26T09:05:00+0000",
+		OriginTimeZone: "UTC",
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+			{
+				ID:     "1B",
+				Letter: "B",
+				Row:    1,
+			},
+			{
+				ID:     "1C",
+				Letter: "C",
+				Row:    1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	_, err = flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "1A", PassengerID: "p1"})
+	require.NoError(t, err)
+	_, err = flightsRepo.ReserveSeat(model.Reservation{FlightID: "f1", SeatID: "1C", PassengerID: "p2"})
+	require.NoError(t, err)
+	at := time.Date(2019, 11, 25, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	sequence, err := flightsRepo.CheckIn("f1", "1A", "p1", at)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, sequence)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, "2019-11-25T12:00:00Z", foundFlight.Seats[0].CheckedInAt)
+
+	_, err = flightsRepo.CheckIn("f1", "1A", "p1", at)
+	require.Equal(t, ErrAlreadyCheckedIn, err)
+	_, err = flightsRepo.CheckIn("f1", "1B", "p1", at)
+	require.Equal(t, ErrPassengerNotInSeat, err)
+
+	// Saving the flight keeps the sequence numbers given
+	_, err = flightsRepo.Save(foundFlight)
+	require.NoError(t, err)
+	sequence, err = flightsRepo.CheckIn("f1", "1C", "p2", at)
+	require.NoError(t, err)
+	require.Equal(t, 2, sequence)
+
+	_, err = flightsRepo.ChangeSeat("f1", "1A", "1B", "p1")
+	require.NoError(t, err)
+	foundFlight, err = flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, "", foundFlight.Seats[0].CheckedInAt)
+	require.Equal(t, "2019-11-25T12:00:00Z", foundFlight.Seats[1].CheckedInAt)
+}
+
+func TestFlightsRepository_FlightNumbers(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+
+	flight := model.Flight{
+		ID:             "f1",
+		Departure:      "2020-05-02T02:00:00Z",
+		Origin:         "BOG",
+		Destination:    "MIA",
+		OriginTimeZone: "America/Bogota",
+		Carrier:        "AV",
+		FlightNumber:   "123",
+		MarketingFlights: []model.MarketingFlight{
+			{Carrier: "IB", FlightNumber: "6421"},
+		},
+		Seats: []model.FlightSeat{
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+			},
+		},
+	}
+
+	// Act
+	created, err := flightsRepo.Create(flight)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, "2020-05-01", created.OperatingDate)
+	foundFlight, err := flightsRepo.Find("f1")
+	require.NoError(t, err)
+	require.Equal(t, created, foundFlight)
+
+	found, err := flightsRepo.ListFlightsByFlightNumber("AV", "123", "2020-05-01", "2020-05-01")
+	require.NoError(t, err)
+	require.Equal(t, []model.Flight{created}, found)
+	found, err = flightsRepo.ListFlightsByFlightNumber("IB", "6421", "2020-04-30", "2020-05-02")
+	require.NoError(t, err)
+	require.Equal(t, []model.Flight{created}, found)
+	_, err = flightsRepo.ListFlightsByFlightNumber("AV", "123", "2020-05-02", "2020-05-02")
+	require.Equal(t, ErrNoFlightsFound, err)
+
+	// Another flight can not take the flight numbers of the date
+	taken := flight
+	taken.ID = "f2"
+	taken.MarketingFlights = nil
+	_, err = flightsRepo.Create(taken)
+	require.Equal(t, ErrFlightNumberTaken, err)
+	_, err = flightsRepo.Find("f2")
+	require.Equal(t, ErrNoFlightsFound, err)
+
+	// Removing a codeshare releases its flight number
+	created.MarketingFlights = nil
+	_, err = flightsRepo.Save(created)
+	require.NoError(t, err)
+	_, err = flightsRepo.ListFlightsByFlightNumber("IB", "6421", "2020-05-01", "2020-05-01")
+	require.Equal(t, ErrNoFlightsFound, err)
+	codeshare := taken
+	codeshare.Carrier = "IB"
+	codeshare.FlightNumber = "6421"
+	_, err = flightsRepo.Create(codeshare)
+	require.NoError(t, err)
+
+	// Deleting the flight releases its flight number
+	require.NoError(t, flightsRepo.Delete("f1"))
+	_, err = flightsRepo.ListFlightsByFlightNumber("AV", "123", "2020-05-01", "2020-05-01")
+	require.Equal(t, ErrNoFlightsFound, err)
+	next := flight
+	next.ID = "f3"
+	next.MarketingFlights = nil
+	_, err = flightsRepo.Create(next)
+	require.NoError(t, err)
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"crypto/rand"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+// locatorAlphabet leaves out the characters that are easy to misread (0, O, 1, I)
+const locatorAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const (
+	locatorLength      = 6
+	maxLocatorAttempts = 3
+)
+
+// locatorClaim is the flight_id of the item that takes a record locator for a
+// passenger, reservations are keyed by locator and flight so the reservation
+// items alone do not stop two bookings on different flights sharing one
+const locatorClaim = "#claim"
+
+var (
+	ErrNoReservationsFound = errors.New("no_reservations_found")
+)
+
+type ReservationsRepository struct {
+	client *dynamodb.DynamoDB
+	table  string
+}
+
+// FindByLocator returns the reservations with the given record locator, one
+// per flight
+func (r *ReservationsRepository) FindByLocator(locator string) ([]model.Reservation, error) {
+	items, err := queryAll(r.client, &dynamodb.QueryInput{
+		TableName: aws.String(r.table),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"locator": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(locator),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return []model.Reservation{}, err
+	}
+
+	reservations := []map[string]*dynamodb.AttributeValue{}
+	for _, item := range items {
+		if v, ok := item["flight_id"]; ok && *v.S == locatorClaim {
+			continue
+		}
+		reservations = append(reservations, item)
+	}
+	if len(reservations) == 0 {
+		return []model.Reservation{}, ErrNoReservationsFound
+	}
+
+	return hydrateReservations(reservations)
+}
+
+// ListUpcomingByPassenger returns the reservations of the passenger departing
+// from now on, the next departure first
+func (r *ReservationsRepository) ListUpcomingByPassenger(passengerID string, now time.Time) ([]model.Reservation, error) {
+	return r.listByPassenger(passengerID, "GE", now, true)
+}
+
+// ListPastByPassenger returns the reservations of the passenger that already
+// departed, the most recent departure first
+func (r *ReservationsRepository) ListPastByPassenger(passengerID string, now time.Time) ([]model.Reservation, error) {
+	return r.listByPassenger(passengerID, "LT", now, false)
+}
+
+func (r *ReservationsRepository) listByPassenger(
+	passengerID string,
+	departureOperator string,
+	now time.Time,
+	ascending bool,
+) ([]model.Reservation, error) {
+	items, err := queryAll(r.client, &dynamodb.QueryInput{
+		TableName:        aws.String(r.table),
+		IndexName:        aws.String("by_passenger_and_departure"),
+		ScanIndexForward: aws.Bool(ascending),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"passenger_id": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(passengerID),
+					},
+				},
+			},
+			"departure": {
+				ComparisonOperator: aws.String(departureOperator),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(model.FormatDeparture(now)),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return []model.Reservation{}, err
+	}
+
+	return hydrateReservations(items)
+}
+
+// newLocator returns a random record locator like "K7QM2X"
+func newLocator() (string, error) {
+	b := make([]byte, locatorLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = locatorAlphabet[int(b[i])%len(locatorAlphabet)]
+	}
+	return string(b), nil
+}
+
+func dehydrateReservation(m model.Reservation) map[string]*dynamodb.AttributeValue {
+	item := map[string]*dynamodb.AttributeValue{
+		"locator": {
+			S: aws.String(m.Locator),
+		},
+		"flight_id": {
+			S: aws.String(m.FlightID),
+		},
+		"seat_id": {
+			S: aws.String(m.SeatID),
+		},
+		"passenger_id": {
+			S: aws.String(m.PassengerID),
+		},
+		"departure": {
+			S: aws.String(m.Departure),
+		},
+		"created_at": {
+			S: aws.String(m.CreatedAt),
+		},
+	}
+	if m.Price.Currency != "" {
+		item["price"] = dehydratePrice(m.Price)
+	}
+	if m.PaymentID != "" {
+		item["payment_id"] = &dynamodb.AttributeValue{
+			S: aws.String(m.PaymentID),
+		}
+	}
+	if len(m.SpecialRequests) > 0 {
+		item["special_requests"] = dehydrateStrings(m.SpecialRequests)
+	}
+	return item
+}
+
+// dehydrateLocatorClaim returns the item taking the locator for the passenger,
+// it has no departure so it stays out of the by_passenger_and_departure index
+func dehydrateLocatorClaim(locator string, passengerID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"locator": {
+			S: aws.String(locator),
+		},
+		"flight_id": {
+			S: aws.String(locatorClaim),
+		},
+		"claimed_by": {
+			S: aws.String(passengerID),
+		},
+	}
+}
+
+func hydrateReservations(items []map[string]*dynamodb.AttributeValue) ([]model.Reservation, error) {
+	reservations := make([]model.Reservation, len(items))
+	for i, item := range items {
+		reservation, err := hydrateReservation(item)
+		if err != nil {
+			return []model.Reservation{}, err
+		}
+		reservations[i] = reservation
+	}
+	return reservations, nil
+}
+
+func hydrateReservation(item map[string]*dynamodb.AttributeValue) (model.Reservation, error) {
+	m := model.Reservation{}
+	if v, ok := item["locator"]; ok {
+		m.Locator = *v.S
+	}
+	if v, ok := item["flight_id"]; ok {
+		m.FlightID = *v.S
+	}
+	if v, ok := item["seat_id"]; ok {
+		m.SeatID = *v.S
+	}
+	if v, ok := item["passenger_id"]; ok {
+		m.PassengerID = *v.S
+	}
+	if v, ok := item["departure"]; ok {
+		m.Departure = *v.S
+	}
+	if v, ok := item["created_at"]; ok {
+		m.CreatedAt = *v.S
+	}
+	if v, ok := item["payment_id"]; ok {
+		m.PaymentID = *v.S
+	}
+	if v, ok := item["special_requests"]; ok {
+		m.SpecialRequests = hydrateStrings(v.L)
+	}
+	if v, ok := item["price"]; ok {
+		price, err := hydratePrice(v.M)
+		if err != nil {
+			return model.Reservation{}, err
+		}
+		m.Price = price
+	}
+	return m, nil
+}
+
+func NewReservationsRepository(client *dynamodb.DynamoDB, table string) *ReservationsRepository {
+	return &ReservationsRepository{
+		client: client,
+		table:  table,
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLocator(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		locator, err := newLocator()
+		if err != nil {
+			t.Fatalf("newLocator() error = %v", err)
+		}
+		if len(locator) != locatorLength {
+			t.Errorf("newLocator() = %v, want %v characters", locator, locatorLength)
+		}
+		for _, c := range locator {
+			if !strings.ContainsRune(locatorAlphabet, c) {
+				t.Errorf("newLocator() = %v, has %q which is not in the alphabet", locator, c)
+			}
+		}
+		seen[locator] = true
+	}
+	if len(seen) < 99 {
+		t.Errorf("newLocator() repeated too many locators, got %v distinct of 100", len(seen))
+	}
+}
+
+func TestReservationsRepository_ListByPassenger(t *testing.T) {
+	// Arrange
+	table := "flights"
+	reservationsTable := "reservations"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createFlightsTable(client, table, t)
+	createReservationsTable(client, reservationsTable, t)
+	flightsRepo := NewFlightsRepository(client, table, reservationsTable)
+	reservationsRepo := NewReservationsRepository(client, reservationsTable)
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	departures := map[string]string{
+		"f1": "2020-04-01T09:00:00+0000",
+		"f2": "2020-04-20T09:00:00+0000",
+		"f3": "2020-05-02T09:00:00+0000",
+		"f4": "2020-06-01T09:00:00+0000",
+	}
+	for _, id := range []string{"f1", "f2", "f3", "f4"} {
+		_, err := flightsRepo.Create(model.Flight{
+			ID:             id,
+			Departure:      departures[id],
+			OriginTimeZone: "UTC",
+			Seats: []model.FlightSeat{
+				{
+					ID:     "1A",
+					Letter: "A",
+					Row:    1,
+				},
+				{
+					ID:     "1B",
+					Letter: "B",
+					Row:    1,
+				},
+			},
+		})
+		require.NoError(t, err)
+		_, err = flightsRepo.ReserveSeat(model.Reservation{FlightID: id, SeatID: "1A", PassengerID: "p1"})
+		require.NoError(t, err)
+		_, err = flightsRepo.ReserveSeat(model.Reservation{FlightID: id, SeatID: "1B", PassengerID: "p2"})
+		require.NoError(t, err)
+	}
+
+	flightIDs := func(reservations []model.Reservation) []string {
+		ids := []string{}
+		for _, r := range reservations {
+			require.Equal(t, "p1", r.PassengerID)
+			ids = append(ids, r.FlightID)
+		}
+		return ids
+	}
+
+	// Act
+	upcoming, err := reservationsRepo.ListUpcomingByPassenger("p1", now)
+	require.NoError(t, err)
+	past, err := reservationsRepo.ListPastByPassenger("p1", now)
+	require.NoError(t, err)
+	none, err := reservationsRepo.ListUpcomingByPassenger("p3", now)
+	require.NoError(t, err)
+
+	// Assert
+	require.Equal(t, []string{"f3", "f4"}, flightIDs(upcoming))
+	require.Equal(t, []string{"f2", "f1"}, flightIDs(past))
+	require.Empty(t, none)
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+// SchedulesRepository keeps the schedules the dated flights are generated
+// from
+type SchedulesRepository struct {
+	client *dynamodb.DynamoDB
+	table  string
+}
+
+// Save creates the schedule or replaces the one with the same id
+func (r *SchedulesRepository) Save(m model.Schedule) error {
+	_, err := r.client.PutItem(&dynamodb.PutItemInput{
+		TableName: aws.String(r.table),
+		Item:      r.dehydrate(m),
+	})
+	return err
+}
+
+// List returns every schedule sorted by id
+func (r *SchedulesRepository) List() ([]model.Schedule, error) {
+	schedules := []model.Schedule{}
+	err := r.client.ScanPages(&dynamodb.ScanInput{
+		TableName:      aws.String(r.table),
+		ConsistentRead: aws.Bool(true),
+	}, func(out *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, item := range out.Items {
+			schedules = append(schedules, r.hydrate(item))
+		}
+		return true
+	})
+	if err != nil {
+		return []model.Schedule{}, err
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].ID < schedules[j].ID
+	})
+	return schedules, nil
+}
+
+func (r *SchedulesRepository) dehydrate(m model.Schedule) map[string]*dynamodb.AttributeValue {
+	days := make([]*dynamodb.AttributeValue, len(m.DaysOfWeek))
+	for i, d := range m.DaysOfWeek {
+		days[i] = &dynamodb.AttributeValue{
+			N: aws.String(strconv.Itoa(d)),
+		}
+	}
+	return map[string]*dynamodb.AttributeValue{
+		"id": {
+			S: aws.String(m.ID),
+		},
+		"flight_number": {
+			S: aws.String(m.FlightNumber),
+		},
+		"origin": {
+			S: aws.String(m.Origin),
+		},
+		"destination": {
+			S: aws.String(m.Destination),
+		},
+		"days_of_week": {
+			L: days,
+		},
+		"departure_time": {
+			S: aws.String(m.DepartureTime),
+		},
+		"time_zone": {
+			S: aws.String(m.TimeZone),
+		},
+		"valid_from": {
+			S: aws.String(m.ValidFrom),
+		},
+		"valid_to": {
+			S: aws.String(m.ValidTo),
+		},
+		"aircraft_type": {
+			S: aws.String(m.AircraftType),
+		},
+	}
+}
+
+func (r *SchedulesRepository) hydrate(item map[string]*dynamodb.AttributeValue) model.Schedule {
+	m := model.Schedule{}
+	if v, ok := item["id"]; ok {
+		m.ID = *v.S
+	}
+	if v, ok := item["flight_number"]; ok {
+		m.FlightNumber = *v.S
+	}
+	if v, ok := item["origin"]; ok {
+		m.Origin = *v.S
+	}
+	if v, ok := item["destination"]; ok {
+		m.Destination = *v.S
+	}
+	if v, ok := item["days_of_week"]; ok {
+		m.DaysOfWeek = make([]int, len(v.L))
+		for i, d := range v.L {
+			m.DaysOfWeek[i], _ = strconv.Atoi(*d.N)
+		}
+	}
+	if v, ok := item["departure_time"]; ok {
+		m.DepartureTime = *v.S
+	}
+	if v, ok := item["time_zone"]; ok {
+		m.TimeZone = *v.S
+	}
+	if v, ok := item["valid_from"]; ok {
+		m.ValidFrom = *v.S
+	}
+	if v, ok := item["valid_to"]; ok {
+		m.ValidTo = *v.S
+	}
+	if v, ok := item["aircraft_type"]; ok {
+		m.AircraftType = *v.S
+	}
+	return m
+}
+
+func NewSchedulesRepository(client *dynamodb.DynamoDB, table string) *SchedulesRepository {
+	return &SchedulesRepository{
+		client: client,
+		table:  table,
+	}
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func createSchedulesTable(client *dynamodb.DynamoDB, table string, t *testing.T) {
+	_, err := client.CreateTable(&dynamodb.CreateTableInput{
+		TableName: aws.String(table),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String("HASH"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	})
+	if err != nil {
+		t.Errorf("Error while creating schedules table: %v\n", err)
+	}
+}
+
+func TestSchedulesRepository(t *testing.T) {
+	// Arrange
+	table := "schedules"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createSchedulesTable(client, table, t)
+	schedulesRepo := NewSchedulesRepository(client, table)
+
+	s2 := model.Schedule{
+		ID:            "s2",
+		FlightNumber:  "XX124",
+		Origin:        "MDE",
+		Destination:   "BOG",
+		DaysOfWeek:    []int{2, 4},
+		DepartureTime: "18:00",
+		TimeZone:      "America/Bogota",
+		ValidFrom:     "2020-05-01",
+		ValidTo:       "2020-10-31",
+		AircraftType:  "A320",
+	}
+	s1 := s2
+	s1.ID = "s1"
+	s1.FlightNumber = "XX123"
+	s1.Origin, s1.Destination = "BOG", "MDE"
+	s1.DaysOfWeek = []int{1, 3, 5, 7}
+
+	// Act & Assert
+	require.NoError(t, schedulesRepo.Save(s2))
+	require.NoError(t, schedulesRepo.Save(s1))
+	schedules, err := schedulesRepo.List()
+	require.NoError(t, err)
+	require.Equal(t, []model.Schedule{s1, s2}, schedules)
+
+	s1.DepartureTime = "07:00"
+	require.NoError(t, schedulesRepo.Save(s1))
+	schedules, err = schedulesRepo.List()
+	require.NoError(t, err)
+	require.Equal(t, []model.Schedule{s1, s2}, schedules)
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+var (
+	ErrAlreadyWaitlisted     = errors.New("already_waitlisted")
+	ErrWaitlistEmpty         = errors.New("waitlist_empty")
+	ErrWaitlistEntryNotFound = errors.New("waitlist_entry_not_found")
+	ErrWaitlistEntryChanged  = errors.New("waitlist_entry_changed")
+)
+
+// joinedAtLayout has a fixed number of decimals so entries sort by the time
+// they joined
+const joinedAtLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// waitlistedPrefix starts the joined_at of the item that marks a passenger as
+// waiting for the flight, it is written with the entry so a passenger can not
+// join twice. It sorts before the entries and is never listed
+const waitlistedPrefix = "#passenger#"
+
+// WaitlistRepository keeps the passengers waiting for a seat of a full
+// flight, sorted by the time they joined
+type WaitlistRepository struct {
+	client *dynamodb.DynamoDB
+	table  string
+}
+
+// Join adds the passenger at the end of the waitlist of the flight
+func (r *WaitlistRepository) Join(flightID string, passengerID string, now time.Time) (model.WaitlistEntry, error) {
+	entry := model.WaitlistEntry{
+		FlightID:    flightID,
+		JoinedAt:    now.UTC().Format(joinedAtLayout),
+		PassengerID: passengerID,
+		Status:      model.WaitlistStatusWaiting,
+	}
+	_, err := r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(r.table),
+					Item:                r.key(flightID, waitlistedPrefix+passengerID),
+					ConditionExpression: aws.String("attribute_not_exists(joined_at)"),
+				},
+			},
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(r.table),
+					Item:                r.dehydrate(entry),
+					ConditionExpression: aws.String("attribute_not_exists(joined_at)"),
+				},
+			},
+		},
+	})
+	if isTransactionCanceled(err) {
+		return model.WaitlistEntry{}, ErrAlreadyWaitlisted
+	}
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// NextWaiting returns the first passenger of the flight that has not been
+// offered a seat yet
+func (r *WaitlistRepository) NextWaiting(flightID string) (model.WaitlistEntry, error) {
+	entries, err := r.list(flightID)
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+	for _, e := range entries {
+		if e.Status == model.WaitlistStatusWaiting {
+			return e, nil
+		}
+	}
+	return model.WaitlistEntry{}, ErrWaitlistEmpty
+}
+
+func (r *WaitlistRepository) Find(flightID string, joinedAt string) (model.WaitlistEntry, error) {
+	out, err := r.client.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(r.table),
+		Key:       r.key(flightID, joinedAt),
+	})
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+	if len(out.Item) == 0 {
+		return model.WaitlistEntry{}, ErrWaitlistEntryNotFound
+	}
+	return r.hydrate(out.Item), nil
+}
+
+// Offer marks the entry as offered the seat until the given time, only
+// waiting entries can be offered a seat
+func (r *WaitlistRepository) Offer(entry model.WaitlistEntry, seatID string, until time.Time) (model.WaitlistEntry, error) {
+	entry.Status = model.WaitlistStatusOffered
+	entry.OfferedSeatID = seatID
+	entry.OfferExpiresAt = until.UTC().Format(time.RFC3339)
+
+	_, err := r.client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.table),
+		Key:                 r.key(entry.FlightID, entry.JoinedAt),
+		ConditionExpression: aws.String("#status = :waiting"),
+		UpdateExpression:    aws.String("set #status = :offered, offered_seat_id = :seatID, offer_expires_at = :until"),
+		ExpressionAttributeNames: map[string]*string{
+			"#status": aws.String("status"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":waiting": {
+				S: aws.String(model.WaitlistStatusWaiting),
+			},
+			":offered": {
+				S: aws.String(entry.Status),
+			},
+			":seatID": {
+				S: aws.String(entry.OfferedSeatID),
+			},
+			":until": {
+				S: aws.String(entry.OfferExpiresAt),
+			},
+		},
+	})
+	if isConditionalCheckFailed(err) {
+		return model.WaitlistEntry{}, ErrWaitlistEntryChanged
+	}
+	if err != nil {
+		return model.WaitlistEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// Remove takes the entry out of the waitlist, the passenger can join it again
+func (r *WaitlistRepository) Remove(flightID string, joinedAt string) error {
+	entry, err := r.Find(flightID, joinedAt)
+	if err == ErrWaitlistEntryNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Delete: &dynamodb.Delete{
+					TableName: aws.String(r.table),
+					Key:       r.key(flightID, waitlistedPrefix+entry.PassengerID),
+				},
+			},
+			{
+				Delete: &dynamodb.Delete{
+					TableName: aws.String(r.table),
+					Key:       r.key(flightID, joinedAt),
+				},
+			},
+		},
+	})
+	return err
+}
+
+func (r *WaitlistRepository) list(flightID string) ([]model.WaitlistEntry, error) {
+	items, err := queryAll(r.client, &dynamodb.QueryInput{
+		TableName:      aws.String(r.table),
+		ConsistentRead: aws.Bool(true),
+		KeyConditions: map[string]*dynamodb.Condition{
+			"flight_id": {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(flightID),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return []model.WaitlistEntry{}, err
+	}
+
+	entries := []model.WaitlistEntry{}
+	for _, item := range items {
+		if strings.HasPrefix(*item["joined_at"].S, waitlistedPrefix) {
+			continue
+		}
+		entries = append(entries, r.hydrate(item))
+	}
+	return entries, nil
+}
+
+func (r *WaitlistRepository) key(flightID string, joinedAt string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"flight_id": {
+			S: aws.String(flightID),
+		},
+		"joined_at": {
+			S: aws.String(joinedAt),
+		},
+	}
+}
+
+func (r *WaitlistRepository) dehydrate(m model.WaitlistEntry) map[string]*dynamodb.AttributeValue {
+	item := r.key(m.FlightID, m.JoinedAt)
+	item["passenger_id"] = &dynamodb.AttributeValue{
+		S: aws.String(m.PassengerID),
+	}
+	item["status"] = &dynamodb.AttributeValue{
+		S: aws.String(m.Status),
+	}
+	if m.OfferedSeatID != "" {
+		item["offered_seat_id"] = &dynamodb.AttributeValue{
+			S: aws.String(m.OfferedSeatID),
+		}
+	}
+	if m.OfferExpiresAt != "" {
+		item["offer_expires_at"] = &dynamodb.AttributeValue{
+			S: aws.String(m.OfferExpiresAt),
+		}
+	}
+	return item
+}
+
+func (r *WaitlistRepository) hydrate(item map[string]*dynamodb.AttributeValue) model.WaitlistEntry {
+	m := model.WaitlistEntry{}
+	if v, ok := item["flight_id"]; ok {
+		m.FlightID = *v.S
+	}
+	if v, ok := item["joined_at"]; ok {
+		m.JoinedAt = *v.S
+	}
+	if v, ok := item["passenger_id"]; ok {
+		m.PassengerID = *v.S
+	}
+	if v, ok := item["status"]; ok {
+		m.Status = *v.S
+	}
+	if v, ok := item["offered_seat_id"]; ok {
+		m.OfferedSeatID = *v.S
+	}
+	if v, ok := item["offer_expires_at"]; ok {
+		m.OfferExpiresAt = *v.S
+	}
+	return m
+}
+
+func NewWaitlistRepository(client *dynamodb.DynamoDB, table string) *WaitlistRepository {
+	return &WaitlistRepository{
+		client: client,
+		table:  table,
+	}
+}
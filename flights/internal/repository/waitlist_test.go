@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/require"
+)
+
+func createWaitlistTable(client *dynamodb.DynamoDB, table string, t *testing.T) {
+	_, err := client.CreateTable(&dynamodb.CreateTableInput{
+		TableName: aws.String(table),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("flight_id"),
+				AttributeType: aws.String("S"),
+			},
+			{
+				AttributeName: aws.String("joined_at"),
+				AttributeType: aws.String("S"),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("flight_id"),
+				KeyType:       aws.String("HASH"),
+			},
+			{
+				AttributeName: aws.String("joined_at"),
+				KeyType:       aws.String("RANGE"),
+			},
+		},
+		ProvisionedThroughput: &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(5),
+			WriteCapacityUnits: aws.Int64(5),
+		},
+	})
+	if err != nil {
+		t.Errorf("Error while creating waitlist table: %v\n", err)
+	}
+}
+
+func TestWaitlistRepository(t *testing.T) {
+	// Arrange
+	table := "waitlist"
+	closer, client := internal.DynamodbStart(t)
+	defer closer()
+	createWaitlistTable(client, table, t)
+	waitlistRepo := NewWaitlistRepository(client, table)
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	first, err := waitlistRepo.Join("f1", "p1", now)
+	require.NoError(t, err)
+	_, err = waitlistRepo.Join("f1", "p2", now.Add(time.Millisecond))
+	require.NoError(t, err)
+	_, err = waitlistRepo.Join("f1", "p1", now.Add(time.Second))
+	require.Equal(t, ErrAlreadyWaitlisted, err)
+
+	// Act & Assert
+	next, err := waitlistRepo.NextWaiting("f1")
+	require.NoError(t, err)
+	require.Equal(t, first, next)
+
+	offered, err := waitlistRepo.Offer(next, "1A", now.Add(15*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, model.WaitlistStatusOffered, offered.Status)
+	_, err = waitlistRepo.Offer(next, "1B", now.Add(15*time.Minute))
+	require.Equal(t, ErrWaitlistEntryChanged, err)
+
+	found, err := waitlistRepo.Find("f1", first.JoinedAt)
+	require.NoError(t, err)
+	require.Equal(t, offered, found)
+
+	next, err = waitlistRepo.NextWaiting("f1")
+	require.NoError(t, err)
+	require.Equal(t, "p2", next.PassengerID)
+
+	require.NoError(t, waitlistRepo.Remove("f1", first.JoinedAt))
+	require.NoError(t, waitlistRepo.Remove("f1", next.JoinedAt))
+	_, err = waitlistRepo.NextWaiting("f1")
+	require.Equal(t, ErrWaitlistEmpty, err)
+	_, err = waitlistRepo.Find("f1", first.JoinedAt)
+	require.Equal(t, ErrWaitlistEntryNotFound, err)
+
+	// Removed passengers can join again
+	rejoined, err := waitlistRepo.Join("f1", "p1", now.Add(time.Minute))
+	require.NoError(t, err)
+	next, err = waitlistRepo.NextWaiting("f1")
+	require.NoError(t, err)
+	require.Equal(t, rejoined, next)
+}
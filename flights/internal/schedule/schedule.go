@@ -0,0 +1,123 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+var ErrInvalidSchedule = errors.New("invalid_schedule")
+
+// departureTimeLayout is the local departure time of a schedule, e.g. 06:30
+const departureTimeLayout = "15:04"
+
+// Validate checks the schedule can generate flights, the error tells the
+// invalid field
+func Validate(s model.Schedule) error {
+	if strings.TrimSpace(s.ID) == "" {
+		return fmt.Errorf("%w: id", ErrInvalidSchedule)
+	}
+	if _, _, ok := model.ParseDesignator(s.FlightNumber); !ok {
+		return fmt.Errorf("%w: flight_number", ErrInvalidSchedule)
+	}
+	if s.Origin == "" || s.Destination == "" || s.Origin == s.Destination {
+		return fmt.Errorf("%w: route", ErrInvalidSchedule)
+	}
+	if len(s.DaysOfWeek) == 0 {
+		return fmt.Errorf("%w: days_of_week", ErrInvalidSchedule)
+	}
+	for _, d := range s.DaysOfWeek {
+		if d < 1 || d > 7 {
+			return fmt.Errorf("%w: days_of_week", ErrInvalidSchedule)
+		}
+	}
+	if _, err := time.Parse(departureTimeLayout, s.DepartureTime); err != nil {
+		return fmt.Errorf("%w: departure_time", ErrInvalidSchedule)
+	}
+	if !model.IsTimeZone(s.TimeZone) {
+		return fmt.Errorf("%w: time_zone", ErrInvalidSchedule)
+	}
+	from, err := time.Parse(model.ScheduleDateLayout, s.ValidFrom)
+	if err != nil {
+		return fmt.Errorf("%w: valid_from", ErrInvalidSchedule)
+	}
+	to, err := time.Parse(model.ScheduleDateLayout, s.ValidTo)
+	if err != nil || to.Before(from) {
+		return fmt.Errorf("%w: valid_to", ErrInvalidSchedule)
+	}
+	if strings.TrimSpace(s.AircraftType) == "" {
+		return fmt.Errorf("%w: aircraft_type", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// Departures returns the departures of the schedule in the given number of
+// days starting at from, the ones before from are left out
+func Departures(s model.Schedule, from time.Time, days int) ([]time.Time, error) {
+	err := Validate(s)
+	if err != nil {
+		return nil, err
+	}
+	location, _ := time.LoadLocation(s.TimeZone)
+	clock, _ := time.Parse(departureTimeLayout, s.DepartureTime)
+	validFrom, _ := time.ParseInLocation(model.ScheduleDateLayout, s.ValidFrom, location)
+	validTo, _ := time.ParseInLocation(model.ScheduleDateLayout, s.ValidTo, location)
+
+	operates := map[time.Weekday]bool{}
+	for _, d := range s.DaysOfWeek {
+		operates[time.Weekday(d%7)] = true
+	}
+
+	departures := []time.Time{}
+	start := from.In(location)
+	for i := 0; i < days; i++ {
+		day := time.Date(start.Year(), start.Month(), start.Day()+i, 0, 0, 0, 0, location)
+		if day.Before(validFrom) || day.After(validTo) || !operates[day.Weekday()] {
+			continue
+		}
+		departure := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+		if departure.Before(from) {
+			continue
+		}
+		departures = append(departures, departure)
+	}
+	return departures, nil
+}
+
+// FlightID is the id of the flight of the schedule departing at the given
+// time, the flight number, the local date and the origin, e.g.
+// XX123-20200501-BOG. The origin tells apart the legs of a flight number
+// stopping on the way and the id is the same every time so a flight is
+// generated only once
+func FlightID(s model.Schedule, departure time.Time) string {
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	return fmt.Sprintf("%s-%s-%s", s.FlightNumber, departure.In(location).Format("20060102"), s.Origin)
+}
+
+// Flight builds the flight of the schedule departing at the given time, seats
+// are the ones of the aircraft and the operating date the local one
+func Flight(s model.Schedule, departure time.Time, seats []model.FlightSeat) model.Flight {
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	carrier, flightNumber, _ := model.ParseDesignator(s.FlightNumber)
+	return model.Flight{
+		ID:             FlightID(s, departure),
+		Departure:      model.FormatDeparture(departure),
+		Origin:         s.Origin,
+		Destination:    s.Destination,
+		OriginTimeZone: s.TimeZone,
+		AircraftType:   s.AircraftType,
+		Seats:          seats,
+		Carrier:        carrier,
+		FlightNumber:   flightNumber,
+		OperatingDate:  departure.In(location).Format(model.OperatingDateLayout),
+	}
+}
@@ -0,0 +1,174 @@
+package schedule
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func schedule() model.Schedule {
+	return model.Schedule{
+		ID:            "s1",
+		FlightNumber:  "XX123",
+		Origin:        "BOG",
+		Destination:   "MDE",
+		DaysOfWeek:    []int{1, 3, 5},
+		DepartureTime: "06:30",
+		TimeZone:      "America/Bogota",
+		ValidFrom:     "2020-05-01",
+		ValidTo:       "2020-05-10",
+		AircraftType:  "A320",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(s *model.Schedule)
+		wantErr error
+	}{
+		{
+			name:   "A valid schedule",
+			change: func(s *model.Schedule) {},
+		},
+		{
+			name:    "Fail because the flight number has no carrier",
+			change:  func(s *model.Schedule) { s.FlightNumber = "123" },
+			wantErr: fmt.Errorf("%w: flight_number", ErrInvalidSchedule),
+		},
+		{
+			name:    "Fail because the route goes nowhere",
+			change:  func(s *model.Schedule) { s.Destination = "BOG" },
+			wantErr: fmt.Errorf("%w: route", ErrInvalidSchedule),
+		},
+		{
+			name:    "Fail because of a day out of the week",
+			change:  func(s *model.Schedule) { s.DaysOfWeek = []int{0} },
+			wantErr: fmt.Errorf("%w: days_of_week", ErrInvalidSchedule),
+		},
+		{
+			name:    "Fail because of an invalid departure time",
+			change:  func(s *model.Schedule) { s.DepartureTime = "25:00" },
+			wantErr: fmt.Errorf("%w: departure_time", ErrInvalidSchedule),
+		},
+		{
+			name:    "Fail because of an unknown time zone",
+			change:  func(s *model.Schedule) { s.TimeZone = "Mars/Olympus" },
+			wantErr: fmt.Errorf("%w: time_zone", ErrInvalidSchedule),
+		},
+		{
+			name:    "Fail because the validity ends before it starts",
+			change:  func(s *model.Schedule) { s.ValidTo = "2020-04-30" },
+			wantErr: fmt.Errorf("%w: valid_to", ErrInvalidSchedule),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := schedule()
+			tt.change(&s)
+
+			// Act
+			err := Validate(s)
+
+			// Assert
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.wantErr.Error())
+		})
+	}
+}
+
+func TestDepartures(t *testing.T) {
+	// 2020-05-01 is a Friday, 07:00 in Bogota
+	from := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		daysOfWeek []int
+		days       int
+		want       []time.Time
+	}{
+		{
+			name:       "Departures on the days of the week in the validity, the past ones are left out",
+			daysOfWeek: []int{1, 3, 5},
+			days:       15,
+			want: []time.Time{
+				time.Date(2020, 5, 4, 11, 30, 0, 0, time.UTC),
+				time.Date(2020, 5, 6, 11, 30, 0, 0, time.UTC),
+				time.Date(2020, 5, 8, 11, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "Sundays are the day 7",
+			daysOfWeek: []int{7},
+			days:       3,
+			want: []time.Time{
+				time.Date(2020, 5, 3, 11, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "No departures in the given days",
+			daysOfWeek: []int{2},
+			days:       3,
+			want:       []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			s := schedule()
+			s.DaysOfWeek = tt.daysOfWeek
+
+			// Act
+			got, err := Departures(s, from, tt.days)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFlight(t *testing.T) {
+	departure := time.Date(2020, 5, 5, 3, 30, 0, 0, time.UTC)
+	seats := []model.FlightSeat{{ID: "1A", Row: 1, Letter: "A"}}
+
+	got := Flight(schedule(), departure, seats)
+
+	want := model.Flight{
+		ID:             "XX123-20200504-BOG",
+		Departure:      "2020-05-05T03:30:00Z",
+		Origin:         "BOG",
+		Destination:    "MDE",
+		OriginTimeZone: "America/Bogota",
+		AircraftType:   "A320",
+		Seats:          seats,
+		Carrier:        "XX",
+		FlightNumber:   "123",
+		OperatingDate:  "2020-05-04",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Differences found: (-want,+got)\n%s", diff)
+	}
+}
+
+func TestFlightID(t *testing.T) {
+	departure := time.Date(2020, 5, 5, 3, 30, 0, 0, time.UTC)
+	secondLeg := schedule()
+	secondLeg.Origin = "MDE"
+	secondLeg.Destination = "CTG"
+
+	require.Equal(t, "XX123-20200504-BOG", FlightID(schedule(), departure))
+	require.Equal(t, "XX123-20200504-MDE", FlightID(secondLeg, departure.Add(time.Hour)))
+}
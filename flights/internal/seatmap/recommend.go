@@ -0,0 +1,140 @@
+package seatmap
+
+import (
+	"sort"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+// Recommendation is a block of free seats for a group
+type Recommendation struct {
+	Seats []model.FlightSeat
+	// Split tells the group is seated in two rows, one behind the other
+	Split bool
+}
+
+type candidate struct {
+	Recommendation
+	orphans int
+}
+
+// Recommend ranks the blocks of free seats next to each other in the same row
+// where a group fits. Blocks that do not leave a lonely free seat come first,
+// then the front rows. When no row has room for the whole group it is split in
+// a front row and the row behind it, the back seats right behind the front ones
+func Recommend(seats []model.FlightSeat, size int, fits func(seat model.FlightSeat) bool, limit int) []Recommendation {
+	if size <= 0 || limit <= 0 {
+		return []Recommendation{}
+	}
+
+	candidates := []candidate{}
+	for _, row := range rows(seats) {
+		for _, run := range freeRuns(row, fits) {
+			for start := 0; start+size <= len(run); start++ {
+				orphans := 0
+				if start == 1 {
+					orphans++
+				}
+				if len(run)-start-size == 1 {
+					orphans++
+				}
+				candidates = append(candidates, candidate{
+					Recommendation: Recommendation{Seats: run[start : start+size]},
+					orphans:        orphans,
+				})
+			}
+		}
+	}
+	if len(candidates) == 0 && size > 1 {
+		candidates = splitCandidates(seats, size, fits)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].orphans != candidates[j].orphans {
+			return candidates[i].orphans < candidates[j].orphans
+		}
+		return candidates[i].Seats[0].Row < candidates[j].Seats[0].Row
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	recommendations := make([]Recommendation, len(candidates))
+	for i, c := range candidates {
+		recommendations[i] = c.Recommendation
+	}
+	return recommendations
+}
+
+// splitCandidates seats the bigger half of the group in a row and the rest
+// right behind them
+func splitCandidates(seats []model.FlightSeat, size int, fits func(seat model.FlightSeat) bool) []candidate {
+	frontSize := (size + 1) / 2
+	backSize := size / 2
+
+	candidates := []candidate{}
+	for _, front := range rows(seats) {
+		back := Row(seats, front[0].Row+1, Cabin(front[0]))
+		for _, frontRun := range freeRuns(front, fits) {
+			for f := 0; f+frontSize <= len(frontRun); f++ {
+				frontBlock := frontRun[f : f+frontSize]
+				letters := map[string]bool{}
+				for _, s := range frontBlock {
+					letters[s.Letter] = true
+				}
+				backBlock := findBehind(freeRuns(back, fits), backSize, letters)
+				if backBlock == nil {
+					continue
+				}
+				block := append(append([]model.FlightSeat{}, frontBlock...), backBlock...)
+				candidates = append(candidates, candidate{
+					Recommendation: Recommendation{Seats: block, Split: true},
+				})
+			}
+		}
+	}
+	return candidates
+}
+
+// findBehind returns the first block of the size whose letters are all in the
+// front block
+func findBehind(runs [][]model.FlightSeat, size int, letters map[string]bool) []model.FlightSeat {
+	for _, run := range runs {
+		for start := 0; start+size <= len(run); start++ {
+			block := run[start : start+size]
+			behind := true
+			for _, s := range block {
+				behind = behind && letters[s.Letter]
+			}
+			if behind {
+				return block
+			}
+		}
+	}
+	return nil
+}
+
+// freeRuns splits a row in the runs of free seats that fit and are next to each
+// other, aisles and taken seats break the runs
+func freeRuns(row []model.FlightSeat, fits func(seat model.FlightSeat) bool) [][]model.FlightSeat {
+	runs := [][]model.FlightSeat{}
+	var run []model.FlightSeat
+	for i, s := range row {
+		if s.PassengerID != "" || !fits(s) {
+			if len(run) > 0 {
+				runs = append(runs, run)
+			}
+			run = nil
+			continue
+		}
+		if len(run) > 0 && !Adjacent(row[i-1], s, row) {
+			runs = append(runs, run)
+			run = nil
+		}
+		run = append(run, s)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
@@ -0,0 +1,121 @@
+package seatmap
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+func TestRecommend(t *testing.T) {
+	economy := func(seat model.FlightSeat) bool {
+		return Cabin(seat) == model.CabinEconomy
+	}
+	// rows 3 and 4 are 3-3 economy rows behind the business row
+	layout := func(passengers map[string]string) []model.FlightSeat {
+		s := seats(passengers)
+		for _, row := range []string{"3", "4"} {
+			for _, letter := range []string{"A", "B", "C", "D", "E", "F"} {
+				position := model.SeatPositionMiddle
+				if letter == "A" || letter == "F" {
+					position = model.SeatPositionWindow
+				}
+				if letter == "C" || letter == "D" {
+					position = model.SeatPositionAisle
+				}
+				n := 3
+				if row == "4" {
+					n = 4
+				}
+				s = append(s, model.FlightSeat{ID: row + letter, Letter: letter, Row: n, Position: position,
+					PassengerID: passengers[row+letter]})
+			}
+		}
+		return s
+	}
+
+	type recommendation struct {
+		Seats []string
+		Split bool
+	}
+
+	tests := []struct {
+		name       string
+		passengers map[string]string
+		size       int
+		limit      int
+		want       []recommendation
+	}{
+		{
+			name:       "Blocks are ranked by row and never cross the aisle",
+			passengers: map[string]string{},
+			size:       3,
+			limit:      5,
+			want: []recommendation{
+				{Seats: []string{"1A", "1B", "1C"}},
+				{Seats: []string{"1D", "1E", "1F"}},
+				{Seats: []string{"3A", "3B", "3C"}},
+				{Seats: []string{"3D", "3E", "3F"}},
+				{Seats: []string{"4A", "4B", "4C"}},
+			},
+		},
+		{
+			name: "Blocks leaving a lonely free seat go last",
+			passengers: map[string]string{"1A": "p1", "1B": "p2", "1C": "p3", "1D": "p4",
+				"4A": "p5", "4B": "p6", "4C": "p7", "4D": "p8", "4E": "p9", "4F": "p10"},
+			size:  1,
+			limit: 6,
+			want: []recommendation{
+				{Seats: []string{"3A"}},
+				{Seats: []string{"3C"}},
+				{Seats: []string{"3D"}},
+				{Seats: []string{"3F"}},
+				{Seats: []string{"1E"}},
+				{Seats: []string{"1F"}},
+			},
+		},
+		{
+			name:       "The limit caps the recommendations",
+			passengers: map[string]string{},
+			size:       3,
+			limit:      1,
+			want: []recommendation{
+				{Seats: []string{"1A", "1B", "1C"}},
+			},
+		},
+		{
+			name: "Split the group in a front and a back row when no row has room",
+			passengers: map[string]string{"1A": "p1", "1C": "p2", "1E": "p3", "3A": "p4", "3E": "p5",
+				"4A": "p6", "4B": "p7", "4D": "p8", "4F": "p9"},
+			size:  3,
+			limit: 5,
+			want: []recommendation{
+				{Seats: []string{"3B", "3C", "4C"}, Split: true},
+			},
+		},
+		{
+			name: "No recommendations when the group does not fit",
+			passengers: map[string]string{"1A": "p1", "1C": "p2", "1E": "p3", "3B": "p4", "3D": "p5", "3F": "p6",
+				"4A": "p7", "4C": "p8", "4E": "p9"},
+			size:  2,
+			limit: 5,
+			want:  []recommendation{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			recommendations := Recommend(layout(tt.passengers), tt.size, economy, tt.limit)
+
+			// Assert
+			got := []recommendation{}
+			for _, r := range recommendations {
+				got = append(got, recommendation{Seats: ids(r.Seats), Split: r.Split})
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+		})
+	}
+}
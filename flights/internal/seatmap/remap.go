@@ -0,0 +1,114 @@
+package seatmap
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+var (
+	ErrPassengersDoNotFit = errors.New("passengers_do_not_fit")
+)
+
+// Remap assigns the passengers of the old seats to the new ones, the same seat
+// when it exists in the same cabin and otherwise the nearest free seat of the
+// cabin. Exit rows are only given to passengers that had one. It returns the
+// new seats and the passengers that changed seat
+func Remap(oldSeats []model.FlightSeat, newSeats []model.FlightSeat) ([]model.FlightSeat, []model.SeatMove, error) {
+	seats := make([]model.FlightSeat, len(newSeats))
+	copy(seats, newSeats)
+	for i := range seats {
+		seats[i] = assign(seats[i], model.FlightSeat{})
+	}
+
+	index := map[string]int{}
+	for i, s := range seats {
+		index[s.ID] = i
+	}
+
+	occupied := []model.FlightSeat{}
+	for _, s := range oldSeats {
+		if s.PassengerID != "" {
+			occupied = append(occupied, s)
+		}
+	}
+	sort.SliceStable(occupied, func(i, j int) bool {
+		if occupied[i].Row != occupied[j].Row {
+			return occupied[i].Row < occupied[j].Row
+		}
+		return occupied[i].Letter < occupied[j].Letter
+	})
+
+	// Keep the same seat first so nobody takes it from its passenger
+	pending := []model.FlightSeat{}
+	for _, old := range occupied {
+		i, ok := index[old.ID]
+		if ok && fits(seats[i], old) {
+			seats[i] = assign(seats[i], old)
+			continue
+		}
+		pending = append(pending, old)
+	}
+
+	moves := []model.SeatMove{}
+	for _, old := range pending {
+		nearest := -1
+		for i, s := range seats {
+			if s.PassengerID != "" || !fits(s, old) {
+				continue
+			}
+			if nearest == -1 || distance(old, s) < distance(old, seats[nearest]) {
+				nearest = i
+			}
+		}
+		if nearest == -1 {
+			return nil, nil, ErrPassengersDoNotFit
+		}
+		seats[nearest] = assign(seats[nearest], old)
+		moves = append(moves, model.SeatMove{
+			PassengerID: old.PassengerID,
+			Locator:     old.Locator,
+			FromSeatID:  old.ID,
+			ToSeatID:    seats[nearest].ID,
+		})
+	}
+
+	return seats, moves, nil
+}
+
+// fits tells if the passenger of the old seat can take the new one
+func fits(seat model.FlightSeat, old model.FlightSeat) bool {
+	return seat.PassengerID == "" && Cabin(seat) == Cabin(old) && (!seat.ExitRow || old.ExitRow)
+}
+
+// distance prefers the closest row and then the closest letter
+func distance(old model.FlightSeat, seat model.FlightSeat) int {
+	return abs(old.Row-seat.Row)*100 + abs(int(letter(old))-int(letter(seat)))
+}
+
+func letter(seat model.FlightSeat) byte {
+	if seat.Letter == "" {
+		return 0
+	}
+	return seat.Letter[0]
+}
+
+// assign copies what belongs to the passenger of a seat, not the seat itself
+func assign(seat model.FlightSeat, from model.FlightSeat) model.FlightSeat {
+	seat.PassengerID = from.PassengerID
+	seat.HeldUntil = from.HeldUntil
+	seat.Price = from.Price
+	seat.PaymentID = from.PaymentID
+	seat.Locator = from.Locator
+	seat.CheckedInAt = from.CheckedInAt
+	seat.SpecialRequests = from.SpecialRequests
+	return seat
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
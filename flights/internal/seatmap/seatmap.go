@@ -0,0 +1,155 @@
+package seatmap
+
+import (
+	"sort"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+// Adjacent tells if two seats are next to each other, they must be in the same
+// row and cabin with no seat or aisle between them. Rows whose seats do not
+// tell where the aisles are, like seat maps stored before they did, take two
+// aisle seats as having the aisle between them
+func Adjacent(a model.FlightSeat, b model.FlightSeat, seats []model.FlightSeat) bool {
+	if a.Row != b.Row || Cabin(a) != Cabin(b) || a.ID == b.ID {
+		return false
+	}
+	row := Row(seats, a.Row, Cabin(a))
+	if !hasAisles(row) && a.Position == model.SeatPositionAisle && b.Position == model.SeatPositionAisle {
+		return false
+	}
+	for i := 1; i < len(row); i++ {
+		if (row[i-1].ID == a.ID && row[i].ID == b.ID) || (row[i-1].ID == b.ID && row[i].ID == a.ID) {
+			return !row[i-1].AisleAfter
+		}
+	}
+	return false
+}
+
+func hasAisles(row []model.FlightSeat) bool {
+	for _, s := range row {
+		if s.AisleAfter {
+			return true
+		}
+	}
+	return false
+}
+
+// Row returns the seats of a row in a cabin ordered by letter
+func Row(seats []model.FlightSeat, row int, cabin string) []model.FlightSeat {
+	found := []model.FlightSeat{}
+	for _, s := range seats {
+		if s.Row == row && Cabin(s) == cabin {
+			found = append(found, s)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Letter < found[j].Letter
+	})
+	return found
+}
+
+// Groups returns the passengers with a confirmed seat, the ones seated next to
+// each other are in the same group. Groups are ordered by row
+func Groups(seats []model.FlightSeat) [][]model.FlightSeat {
+	groups := [][]model.FlightSeat{}
+	for _, row := range rows(seats) {
+		var group []model.FlightSeat
+		for i, s := range row {
+			if s.PassengerID == "" || s.HeldUntil != "" {
+				if len(group) > 0 {
+					groups = append(groups, group)
+				}
+				group = nil
+				continue
+			}
+			if len(group) > 0 && !Adjacent(row[i-1], s, row) {
+				groups = append(groups, group)
+				group = nil
+			}
+			group = append(group, s)
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// FindBlock returns the first run of free seats next to each other that fit,
+// nil when there is none
+func FindBlock(seats []model.FlightSeat, size int, fits func(seat model.FlightSeat) bool) []model.FlightSeat {
+	if size <= 0 {
+		return nil
+	}
+	for _, row := range rows(seats) {
+		var block []model.FlightSeat
+		for i, s := range row {
+			if s.PassengerID != "" || !fits(s) {
+				block = nil
+				continue
+			}
+			if len(block) > 0 && !Adjacent(row[i-1], s, row) {
+				block = nil
+			}
+			block = append(block, s)
+			if len(block) == size {
+				return block
+			}
+		}
+	}
+	return nil
+}
+
+// FindSeats returns the first free seats that fit wherever they are, nil when
+// there are not enough
+func FindSeats(seats []model.FlightSeat, size int, fits func(seat model.FlightSeat) bool) []model.FlightSeat {
+	if size <= 0 {
+		return nil
+	}
+	found := []model.FlightSeat{}
+	for _, row := range rows(seats) {
+		for _, s := range row {
+			if s.PassengerID != "" || !fits(s) {
+				continue
+			}
+			found = append(found, s)
+			if len(found) == size {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// rows splits the seats by row and cabin, ordered by row and then by letter
+func rows(seats []model.FlightSeat) [][]model.FlightSeat {
+	sorted := make([]model.FlightSeat, len(seats))
+	copy(sorted, seats)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Row != sorted[j].Row {
+			return sorted[i].Row < sorted[j].Row
+		}
+		if Cabin(sorted[i]) != Cabin(sorted[j]) {
+			return Cabin(sorted[i]) < Cabin(sorted[j])
+		}
+		return sorted[i].Letter < sorted[j].Letter
+	})
+
+	result := [][]model.FlightSeat{}
+	for i, s := range sorted {
+		if i == 0 || s.Row != sorted[i-1].Row || Cabin(s) != Cabin(sorted[i-1]) {
+			result = append(result, []model.FlightSeat{})
+		}
+		result[len(result)-1] = append(result[len(result)-1], s)
+	}
+	return result
+}
+
+// Cabin returns the cabin of the seat, seats without one are economy
+func Cabin(seat model.FlightSeat) string {
+	if seat.Cabin == "" {
+		return model.CabinEconomy
+	}
+	return seat.Cabin
+}
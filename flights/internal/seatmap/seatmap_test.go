@@ -0,0 +1,268 @@
+package seatmap
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+)
+
+// seats builds a 3-3 economy row 1 and a 2-2 business row 2
+func seats(passengers map[string]string) []model.FlightSeat {
+	layout := []model.FlightSeat{
+		{ID: "1A", Letter: "A", Row: 1, Position: model.SeatPositionWindow},
+		{ID: "1B", Letter: "B", Row: 1, Position: model.SeatPositionMiddle},
+		{ID: "1C", Letter: "C", Row: 1, Position: model.SeatPositionAisle, AisleAfter: true},
+		{ID: "1D", Letter: "D", Row: 1, Position: model.SeatPositionAisle},
+		{ID: "1E", Letter: "E", Row: 1, Position: model.SeatPositionMiddle},
+		{ID: "1F", Letter: "F", Row: 1, Position: model.SeatPositionWindow},
+		{ID: "2A", Letter: "A", Row: 2, Position: model.SeatPositionWindow, Cabin: model.CabinBusiness},
+		{ID: "2C", Letter: "C", Row: 2, Position: model.SeatPositionAisle, Cabin: model.CabinBusiness, AisleAfter: true},
+		{ID: "2D", Letter: "D", Row: 2, Position: model.SeatPositionAisle, Cabin: model.CabinBusiness},
+		{ID: "2F", Letter: "F", Row: 2, Position: model.SeatPositionWindow, Cabin: model.CabinBusiness},
+	}
+	for i, s := range layout {
+		layout[i].PassengerID = passengers[s.ID]
+	}
+	return layout
+}
+
+func ids(seats []model.FlightSeat) []string {
+	if seats == nil {
+		return nil
+	}
+	found := []string{}
+	for _, s := range seats {
+		found = append(found, s.ID)
+	}
+	return found
+}
+
+func TestGroups(t *testing.T) {
+	tests := []struct {
+		name       string
+		passengers map[string]string
+		want       [][]string
+	}{
+		{
+			name:       "Passengers next to each other are one group",
+			passengers: map[string]string{"1A": "p1", "1B": "p2", "1C": "p3", "1F": "p4"},
+			want:       [][]string{{"1A", "1B", "1C"}, {"1F"}},
+		},
+		{
+			name:       "The aisle splits a group",
+			passengers: map[string]string{"1C": "p1", "1D": "p2", "2A": "p3", "2C": "p4"},
+			want:       [][]string{{"1C"}, {"1D"}, {"2A", "2C"}},
+		},
+		{
+			name:       "A flight without passengers has no groups",
+			passengers: map[string]string{},
+			want:       [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			groups := Groups(seats(tt.passengers))
+
+			// Assert
+			got := [][]string{}
+			for _, g := range groups {
+				got = append(got, ids(g))
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindBlock(t *testing.T) {
+	economy := func(seat model.FlightSeat) bool {
+		return Cabin(seat) == model.CabinEconomy
+	}
+	tests := []struct {
+		name       string
+		passengers map[string]string
+		size       int
+		fits       func(seat model.FlightSeat) bool
+		want       []string
+	}{
+		{
+			name:       "Find the first free seats next to each other",
+			passengers: map[string]string{"1A": "p1"},
+			size:       2,
+			fits:       economy,
+			want:       []string{"1B", "1C"},
+		},
+		{
+			name:       "Skip a block split by the aisle",
+			passengers: map[string]string{"1A": "p1", "1B": "p2", "1E": "p3"},
+			size:       2,
+			fits:       economy,
+			want:       nil,
+		},
+		{
+			name:       "Find a block in another cabin",
+			passengers: map[string]string{},
+			size:       2,
+			fits: func(seat model.FlightSeat) bool {
+				return Cabin(seat) == model.CabinBusiness
+			},
+			want: []string{"2A", "2C"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := ids(FindBlock(seats(tt.passengers), tt.size, tt.fits))
+
+			// Assert
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+		})
+	}
+}
+
+// A wide-body business cabin has a seat at each window and two in the middle,
+// the aisles are between A and D and between G and K
+func TestWideBodyAisles(t *testing.T) {
+	layout := func(passengers map[string]string) []model.FlightSeat {
+		s := aircraft.Configuration{
+			Sections: []aircraft.Section{
+				{Cabin: model.CabinBusiness, Layout: "A-DG-K", FirstRow: 1, LastRow: 2},
+			},
+		}.Seats()
+		for i := range s {
+			s[i].PassengerID = passengers[s[i].ID]
+		}
+		return s
+	}
+	business := func(seat model.FlightSeat) bool {
+		return Cabin(seat) == model.CabinBusiness
+	}
+
+	t.Run("Only the middle seats are adjacent", func(t *testing.T) {
+		s := layout(map[string]string{})
+		byID := map[string]model.FlightSeat{}
+		for _, seat := range s {
+			byID[seat.ID] = seat
+		}
+		if Adjacent(byID["1A"], byID["1D"], s) || Adjacent(byID["1G"], byID["1K"], s) {
+			t.Errorf("Seats across an aisle are adjacent")
+		}
+		if !Adjacent(byID["1D"], byID["1G"], s) {
+			t.Errorf("1D and 1G are not adjacent")
+		}
+	})
+
+	t.Run("Groups are split by the aisles", func(t *testing.T) {
+		got := [][]string{}
+		for _, g := range Groups(layout(map[string]string{"1A": "p1", "1D": "p2", "1G": "p3", "1K": "p4"})) {
+			got = append(got, ids(g))
+		}
+		if diff := cmp.Diff([][]string{{"1A"}, {"1D", "1G"}, {"1K"}}, got); diff != "" {
+			t.Errorf("Differences found: (-want,+got)\n%s", diff)
+		}
+	})
+
+	t.Run("Blocks are the middle seats", func(t *testing.T) {
+		got := ids(FindBlock(layout(map[string]string{"1D": "p1"}), 2, business))
+		if diff := cmp.Diff([]string{"2D", "2G"}, got); diff != "" {
+			t.Errorf("Differences found: (-want,+got)\n%s", diff)
+		}
+	})
+
+	t.Run("Pairs are only recommended in the middle", func(t *testing.T) {
+		got := [][]string{}
+		for _, r := range Recommend(layout(map[string]string{}), 2, business, 5) {
+			got = append(got, ids(r.Seats))
+		}
+		if diff := cmp.Diff([][]string{{"1D", "1G"}, {"2D", "2G"}}, got); diff != "" {
+			t.Errorf("Differences found: (-want,+got)\n%s", diff)
+		}
+	})
+}
+
+func TestFindSeats(t *testing.T) {
+	// Act
+	got := ids(FindSeats(seats(map[string]string{"1B": "p1", "1C": "p2", "1D": "p3"}), 3, func(seat model.FlightSeat) bool {
+		return Cabin(seat) == model.CabinEconomy
+	}))
+
+	// Assert
+	if diff := cmp.Diff([]string{"1A", "1E", "1F"}, got); diff != "" {
+		t.Errorf("Differences found: (-want,+got)\n%s", diff)
+	}
+}
+
+func TestRemap(t *testing.T) {
+	oldSeats := []model.FlightSeat{
+		{ID: "1A", Letter: "A", Row: 1, PassengerID: "p1", Locator: "AAAAAA"},
+		{ID: "1F", Letter: "F", Row: 1, PassengerID: "p2", Locator: "BBBBBB"},
+		{ID: "2B", Letter: "B", Row: 2, PassengerID: "p3", Locator: "CCCCCC"},
+		{ID: "2C", Letter: "C", Row: 2},
+	}
+	newSeats := []model.FlightSeat{
+		{ID: "1A", Letter: "A", Row: 1},
+		{ID: "1B", Letter: "B", Row: 1},
+		{ID: "1D", Letter: "D", Row: 1},
+		{ID: "1E", Letter: "E", Row: 1},
+		{ID: "2A", Letter: "A", Row: 2, ExitRow: true},
+		{ID: "2B", Letter: "B", Row: 2, ExitRow: true},
+	}
+
+	tests := []struct {
+		name      string
+		newSeats  []model.FlightSeat
+		wantSeats map[string]string
+		wantMoves []model.SeatMove
+		wantErr   error
+	}{
+		{
+			name:      "Keep the same seat or move to the nearest one of the cabin out of exit rows",
+			newSeats:  newSeats,
+			wantSeats: map[string]string{"1A": "p1", "1B": "p3", "1E": "p2"},
+			wantMoves: []model.SeatMove{
+				{PassengerID: "p2", Locator: "BBBBBB", FromSeatID: "1F", ToSeatID: "1E"},
+				{PassengerID: "p3", Locator: "CCCCCC", FromSeatID: "2B", ToSeatID: "1B"},
+			},
+		},
+		{
+			name:     "Fail because the passengers do not fit in the cabin",
+			newSeats: newSeats[:2],
+			wantErr:  ErrPassengersDoNotFit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			seats, moves, err := Remap(oldSeats, tt.newSeats)
+
+			// Assert
+			if err != tt.wantErr {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			gotSeats := map[string]string{}
+			for _, s := range seats {
+				if s.PassengerID != "" {
+					gotSeats[s.ID] = s.PassengerID
+				}
+			}
+			if diff := cmp.Diff(tt.wantSeats, gotSeats); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantMoves, moves); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+		})
+	}
+}
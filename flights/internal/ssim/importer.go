@@ -0,0 +1,118 @@
+package ssim
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	Save(m model.Flight) (model.Flight, error)
+}
+
+type AircraftCatalog interface {
+	FindByIATACode(code string) (string, aircraft.Configuration, error)
+}
+
+type AirportCatalog interface {
+	Find(code string) (airport.Airport, error)
+}
+
+type Report struct {
+	Created  []string `json:"created"`
+	Existing []string `json:"existing"`
+	Failed   []Failed `json:"failed"`
+}
+
+// Failed is a record that could not be read or a flight of a record that
+// could not be saved
+type Failed struct {
+	Line     int    `json:"line"`
+	FlightID string `json:"flight_id"`
+	Reason   string `json:"reason"`
+}
+
+// Import creates the flights of the file departing between from and to, the
+// seats are the ones of the aircraft and the time zone the one of the origin
+// airport. Flights that exist already are left as they are so a file can be
+// imported again
+func Import(r io.Reader, flightsRepo FlightsRepository, catalog AircraftCatalog, airports AirportCatalog, from time.Time, to time.Time) (Report, error) {
+	records, lineErrors, err := Parse(r)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{
+		Created:  []string{},
+		Existing: []string{},
+		Failed:   []Failed{},
+	}
+	for _, e := range lineErrors {
+		report.Failed = append(report.Failed, Failed{Line: e.Line, Reason: e.Err.Error()})
+	}
+
+	for _, record := range records {
+		departures := record.Departures(from, to)
+		if len(departures) == 0 {
+			continue
+		}
+		aircraftType, configuration, err := catalog.FindByIATACode(record.AircraftType)
+		if err != nil {
+			report.Failed = append(report.Failed, Failed{Line: record.Line, Reason: err.Error()})
+			continue
+		}
+		origin, err := airports.Find(record.Origin)
+		if err == nil {
+			_, err = airports.Find(record.Destination)
+		}
+		if err != nil {
+			report.Failed = append(report.Failed, Failed{Line: record.Line, Reason: err.Error()})
+			continue
+		}
+
+		for _, departure := range departures {
+			flight := model.Flight{
+				ID:             departure.FlightID,
+				Departure:      model.FormatDeparture(departure.Time),
+				Origin:         record.Origin,
+				Destination:    record.Destination,
+				OriginTimeZone: origin.TimeZone,
+				AircraftType:   aircraftType,
+				Seats:          configuration.Seats(),
+				OperatingDate:  departure.OperatingDate,
+				BlockMinutes:   int(departure.Arrival.Sub(departure.Time).Minutes()),
+			}
+			// Three letter ICAO airline designators have no flight number
+			if model.ValidCarrier(record.Airline) {
+				flight.Carrier = record.Airline
+				flight.FlightNumber = strconv.Itoa(record.FlightNumber) + record.Suffix
+			}
+			_, err := flightsRepo.Find(flight.ID)
+			if err == nil {
+				report.Existing = append(report.Existing, flight.ID)
+				continue
+			}
+			if err == repository.ErrNoFlightsFound {
+				_, err = flightsRepo.Save(flight)
+			}
+			// Another import created it in the meantime
+			if err == repository.ErrStaleFlight {
+				report.Existing = append(report.Existing, flight.ID)
+				continue
+			}
+			if err != nil {
+				report.Failed = append(report.Failed, Failed{Line: record.Line, FlightID: flight.ID, Reason: err.Error()})
+				continue
+			}
+			report.Created = append(report.Created, flight.ID)
+		}
+	}
+
+	return report, nil
+}
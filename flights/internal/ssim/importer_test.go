@@ -0,0 +1,142 @@
+package ssim
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) Save(f model.Flight) (model.Flight, error) {
+	ret := m.Called(f)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func TestImport(t *testing.T) {
+	catalog := aircraft.Catalog{
+		"TINY": {
+			IATACode: "320",
+			Sections: []aircraft.Section{
+				{
+					Layout:   "A-B",
+					FirstRow: 1,
+					LastRow:  1,
+				},
+			},
+		},
+	}
+	airports := airport.Catalog{
+		"BOG": {IATACode: "BOG", TimeZone: "America/Bogota"},
+		"MDE": {IATACode: "MDE", TimeZone: "America/Bogota"},
+	}
+	// 2020-05-01 is a Friday
+	from := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 5, 5, 0, 0, 0, 0, time.UTC)
+
+	flight := func(id string, departure string) model.Flight {
+		return model.Flight{
+			ID:             id,
+			Departure:      departure,
+			Origin:         "BOG",
+			Destination:    "MDE",
+			OriginTimeZone: "America/Bogota",
+			AircraftType:   "TINY",
+			Seats:          catalog["TINY"].Seats(),
+			Carrier:        "XX",
+			FlightNumber:   "123",
+			OperatingDate:  departure[:10],
+			BlockMinutes:   65,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		file   []string
+		want   Report
+		mocker func(m *FlightsRepositoryMock)
+	}{
+		{
+			name: "Import the flights of the file and leave the existing ones",
+			file: []string{
+				"1AIRLINE STANDARD SCHEDULE DATA SET",
+				leg(nil),
+				leg(map[int]string{47: "-05AA"}),
+				leg(map[int]string{5: " 125", 72: "744"}),
+				leg(map[int]string{5: " 127", 54: "XXX"}),
+			},
+			want: Report{
+				Created:  []string{"XX123-20200503-BOG"},
+				Existing: []string{"XX123-20200504-BOG"},
+				Failed: []Failed{
+					{Line: 3, Reason: "invalid_ssim_record: departure_utc_variation"},
+					{Line: 4, Reason: aircraft.ErrUnknownAircraftType.Error()},
+					{Line: 5, Reason: airport.ErrUnknownAirport.Error()},
+				},
+			},
+			mocker: func(m *FlightsRepositoryMock) {
+				created := flight("XX123-20200503-BOG", "2020-05-03T11:30:00Z")
+				m.On("Find", created.ID).Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+				m.On("Save", created).Return(created, nil).Once()
+
+				existing := flight("XX123-20200504-BOG", "2020-05-04T11:30:00Z")
+				m.On("Find", existing.ID).Return(existing, nil).Once()
+			},
+		},
+		{
+			name: "Report the flights that could not be saved",
+			file: []string{leg(nil)},
+			want: Report{
+				Created:  []string{},
+				Existing: []string{"XX123-20200504-BOG"},
+				Failed: []Failed{
+					{Line: 1, FlightID: "XX123-20200503-BOG", Reason: "unexpected"},
+				},
+			},
+			mocker: func(m *FlightsRepositoryMock) {
+				failed := flight("XX123-20200503-BOG", "2020-05-03T11:30:00Z")
+				m.On("Find", failed.ID).Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+				m.On("Save", failed).Return(model.Flight{}, errors.New("unexpected")).Once()
+
+				// Created by another import between Find and Save
+				raced := flight("XX123-20200504-BOG", "2020-05-04T11:30:00Z")
+				m.On("Find", raced.ID).Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+				m.On("Save", raced).Return(model.Flight{}, repository.ErrStaleFlight).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			flightsRepo := &FlightsRepositoryMock{}
+			tt.mocker(flightsRepo)
+
+			// Act
+			got, err := Import(strings.NewReader(strings.Join(tt.file, "\n")), flightsRepo, catalog, airports, from, to)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			flightsRepo.AssertExpectations(t)
+		})
+	}
+}
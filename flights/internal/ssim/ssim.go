@@ -0,0 +1,299 @@
+// Package ssim reads the flight leg records (type 3) of IATA SSIM chapter 7
+// schedule files
+package ssim
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecord = errors.New("invalid_ssim_record")
+
+const (
+	recordLength    = 200
+	flightLegRecord = '3'
+	// indefinitePeriod ends a period of operation with no end date
+	indefinitePeriod = "00XXX00"
+	dateLayout       = "02Jan06"
+)
+
+// Record is a flight leg, it departs from the origin on the days of the week
+// of the period of operation
+type Record struct {
+	Line         int
+	Airline      string
+	FlightNumber int
+	Suffix       string
+	LegSequence  int
+	PeriodFrom   time.Time
+	// PeriodTo is zero when the period has no end
+	PeriodTo time.Time
+	// DaysOfWeek go from 1 (Monday) to 7 (Sunday)
+	DaysOfWeek []int
+	Origin     string
+	// DepartureTime is the local passenger departure time, e.g. 0630
+	DepartureTime string
+	// Location is the UTC offset of the origin at the departure
+	Location    *time.Location
+	Destination string
+	// ArrivalTime is the local passenger arrival time, e.g. 0735
+	ArrivalTime string
+	// ArrivalLocation is the UTC offset of the destination at the arrival
+	ArrivalLocation *time.Location
+	// AircraftType is the IATA aircraft code, e.g. 320
+	AircraftType string
+	// DateVariation is the days the leg departs after the first leg of the
+	// flight
+	DateVariation int
+	// ArrivalDateVariation is the days the leg arrives after the first leg of
+	// the flight departs
+	ArrivalDateVariation int
+}
+
+// Departure of a leg on a day its flight operates
+type Departure struct {
+	FlightID string
+	Time     time.Time
+	Arrival  time.Time
+	// OperatingDate is the local date the first leg of the flight departs,
+	// e.g. 2020-05-01
+	OperatingDate string
+}
+
+// LineError is a record of the file that could not be read
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+// Parse reads the flight leg records of a file, the other records are
+// skipped. Malformed flight leg records are returned as line errors
+func Parse(r io.Reader) ([]Record, []LineError, error) {
+	records := []Record{}
+	lineErrors := []LineError{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) == 0 || text[0] != flightLegRecord {
+			continue
+		}
+		record, err := ParseRecord(text)
+		if err != nil {
+			lineErrors = append(lineErrors, LineError{Line: line, Err: err})
+			continue
+		}
+		record.Line = line
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return records, lineErrors, nil
+}
+
+// ParseRecord reads a flight leg record, the error tells the invalid field.
+// Trailing spaces can be left out
+func ParseRecord(line string) (Record, error) {
+	if len(line) > recordLength {
+		return Record{}, fmt.Errorf("%w: length", ErrInvalidRecord)
+	}
+	line += strings.Repeat(" ", recordLength-len(line))
+	if line[0] != flightLegRecord {
+		return Record{}, fmt.Errorf("%w: record_type", ErrInvalidRecord)
+	}
+
+	record := Record{
+		Airline:     strings.TrimSpace(line[2:5]),
+		Suffix:      strings.TrimSpace(line[1:2]),
+		Origin:      line[36:39],
+		Destination: line[54:57],
+	}
+	if len(record.Airline) < 2 {
+		return Record{}, fmt.Errorf("%w: airline_designator", ErrInvalidRecord)
+	}
+	flightNumber, err := strconv.Atoi(strings.TrimSpace(line[5:9]))
+	if err != nil || flightNumber <= 0 {
+		return Record{}, fmt.Errorf("%w: flight_number", ErrInvalidRecord)
+	}
+	record.FlightNumber = flightNumber
+	legSequence, err := strconv.Atoi(line[11:13])
+	if err != nil || legSequence <= 0 {
+		return Record{}, fmt.Errorf("%w: leg_sequence_number", ErrInvalidRecord)
+	}
+	record.LegSequence = legSequence
+
+	record.PeriodFrom, err = time.Parse(dateLayout, line[14:21])
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: period_of_operation", ErrInvalidRecord)
+	}
+	if line[21:28] != indefinitePeriod {
+		record.PeriodTo, err = time.Parse(dateLayout, line[21:28])
+		if err != nil || record.PeriodTo.Before(record.PeriodFrom) {
+			return Record{}, fmt.Errorf("%w: period_of_operation", ErrInvalidRecord)
+		}
+	}
+
+	for i, d := range line[28:35] {
+		if d == ' ' {
+			continue
+		}
+		if int(d-'0') != i+1 {
+			return Record{}, fmt.Errorf("%w: days_of_operation", ErrInvalidRecord)
+		}
+		record.DaysOfWeek = append(record.DaysOfWeek, i+1)
+	}
+	if len(record.DaysOfWeek) == 0 {
+		return Record{}, fmt.Errorf("%w: days_of_operation", ErrInvalidRecord)
+	}
+	if line[35] != ' ' && line[35] != '1' {
+		return Record{}, fmt.Errorf("%w: frequency_rate", ErrInvalidRecord)
+	}
+
+	if !isStation(record.Origin) {
+		return Record{}, fmt.Errorf("%w: departure_station", ErrInvalidRecord)
+	}
+	if _, err := time.Parse("1504", line[39:43]); err != nil {
+		return Record{}, fmt.Errorf("%w: departure_time", ErrInvalidRecord)
+	}
+	record.DepartureTime = line[39:43]
+	record.Location, err = parseVariation(line[47:52])
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: departure_utc_variation", ErrInvalidRecord)
+	}
+	if !isStation(record.Destination) || record.Destination == record.Origin {
+		return Record{}, fmt.Errorf("%w: arrival_station", ErrInvalidRecord)
+	}
+	if _, err := time.Parse("1504", line[61:65]); err != nil {
+		return Record{}, fmt.Errorf("%w: arrival_time", ErrInvalidRecord)
+	}
+	record.ArrivalTime = line[61:65]
+	record.ArrivalLocation, err = parseVariation(line[65:70])
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: arrival_utc_variation", ErrInvalidRecord)
+	}
+
+	record.AircraftType = strings.TrimSpace(line[72:75])
+	if len(record.AircraftType) != 3 {
+		return Record{}, fmt.Errorf("%w: aircraft_type", ErrInvalidRecord)
+	}
+
+	record.DateVariation, err = parseDateVariation(line[192])
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: date_variation", ErrInvalidRecord)
+	}
+	record.ArrivalDateVariation, err = parseDateVariation(line[193])
+	if err != nil {
+		return Record{}, fmt.Errorf("%w: arrival_date_variation", ErrInvalidRecord)
+	}
+	if record.BlockTime() <= 0 {
+		return Record{}, fmt.Errorf("%w: arrival_time", ErrInvalidRecord)
+	}
+
+	return record, nil
+}
+
+// BlockTime is the time from the departure to the arrival of the leg, both
+// UTC variations are fixed so it is the same every day
+func (r Record) BlockTime() time.Duration {
+	day := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	return r.arrival(day).Sub(r.departure(day))
+}
+
+// departure is the departure of the leg of the flight operating on the day
+func (r Record) departure(day time.Time) time.Time {
+	hour, _ := strconv.Atoi(r.DepartureTime[:2])
+	minute, _ := strconv.Atoi(r.DepartureTime[2:])
+	return time.Date(day.Year(), day.Month(), day.Day()+r.DateVariation, hour, minute, 0, 0, r.Location)
+}
+
+// arrival is the arrival of the leg of the flight operating on the day
+func (r Record) arrival(day time.Time) time.Time {
+	hour, _ := strconv.Atoi(r.ArrivalTime[:2])
+	minute, _ := strconv.Atoi(r.ArrivalTime[2:])
+	return time.Date(day.Year(), day.Month(), day.Day()+r.ArrivalDateVariation, hour, minute, 0, 0, r.ArrivalLocation)
+}
+
+// Flight is the airline designator, the flight number and the operational
+// suffix, e.g. XX123
+func (r Record) Flight() string {
+	return fmt.Sprintf("%s%d%s", r.Airline, r.FlightNumber, r.Suffix)
+}
+
+// Departures returns the departures of the leg from the given time and before
+// the end one. Flight ids are the flight, the date it operates and the origin
+// like the flights of schedules, e.g. XX123-20200501-BOG, so a flight
+// imported after it was generated from a schedule is the same one
+func (r Record) Departures(from time.Time, to time.Time) []Departure {
+	departures := []Departure{}
+	for day := r.PeriodFrom; r.PeriodTo.IsZero() || !day.After(r.PeriodTo); day = day.AddDate(0, 0, 1) {
+		departure := r.departure(day)
+		if !departure.Before(to) {
+			break
+		}
+		if departure.Before(from) || !r.operates(day.Weekday()) {
+			continue
+		}
+		departures = append(departures, Departure{
+			FlightID:      fmt.Sprintf("%s-%s-%s", r.Flight(), day.Format("20060102"), r.Origin),
+			Time:          departure,
+			Arrival:       r.arrival(day),
+			OperatingDate: day.Format("2006-01-02"),
+		})
+	}
+	return departures
+}
+
+func (r Record) operates(weekday time.Weekday) bool {
+	for _, d := range r.DaysOfWeek {
+		if time.Weekday(d%7) == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDateVariation reads the days after the flight date, A is the day
+// before
+func parseDateVariation(v byte) (int, error) {
+	switch {
+	case v == ' ':
+		return 0, nil
+	case v == 'A':
+		return -1, nil
+	case v >= '0' && v <= '9':
+		return int(v - '0'), nil
+	}
+	return 0, fmt.Errorf("invalid date variation %q", v)
+}
+
+// parseVariation reads a UTC variation like -0500 as a fixed time zone
+func parseVariation(variation string) (*time.Location, error) {
+	offset, err := time.Parse("-0700", variation)
+	if err != nil {
+		return nil, err
+	}
+	_, seconds := offset.Zone()
+	return time.FixedZone(variation, seconds), nil
+}
+
+func isStation(code string) bool {
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return len(code) == 3
+}
@@ -0,0 +1,224 @@
+package ssim
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/stretchr/testify/require"
+)
+
+// leg builds a flight leg record of XX123 from BOG to MDE, the changes replace
+// the fields starting at the given positions
+func leg(changes map[int]string) string {
+	fields := map[int]string{
+		0:   "3",
+		2:   "XX ",
+		5:   " 123",
+		9:   "01",
+		11:  "01",
+		13:  "J",
+		14:  "01MAY20",
+		21:  "31MAY20",
+		28:  "1 3 5 7",
+		36:  "BOG",
+		39:  "0630",
+		43:  "0630",
+		47:  "-0500",
+		54:  "MDE",
+		57:  "0735",
+		61:  "0735",
+		65:  "-0500",
+		72:  "320",
+		194: "000003",
+	}
+	for position, value := range changes {
+		fields[position] = value
+	}
+	line := []byte(strings.Repeat(" ", recordLength))
+	for position, value := range fields {
+		copy(line[position:], value)
+	}
+	return string(line)
+}
+
+func TestParseRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Record
+		wantErr error
+	}{
+		{
+			name: "Parse a flight leg",
+			line: leg(nil),
+			want: Record{
+				Airline:       "XX",
+				FlightNumber:  123,
+				LegSequence:   1,
+				PeriodFrom:    time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+				PeriodTo:      time.Date(2020, 5, 31, 0, 0, 0, 0, time.UTC),
+				DaysOfWeek:    []int{1, 3, 5, 7},
+				Origin:        "BOG",
+				DepartureTime: "0630",
+				Destination:   "MDE",
+				ArrivalTime:   "0735",
+				AircraftType:  "320",
+			},
+		},
+		{
+			name: "Parse a second leg departing the next day of a period with no end",
+			line: strings.TrimRight(leg(map[int]string{1: "A", 11: "02", 21: "00XXX00", 192: "1", 193: "1"}), " "),
+			want: Record{
+				Airline:              "XX",
+				FlightNumber:         123,
+				Suffix:               "A",
+				LegSequence:          2,
+				PeriodFrom:           time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+				DaysOfWeek:           []int{1, 3, 5, 7},
+				Origin:               "BOG",
+				DepartureTime:        "0630",
+				Destination:          "MDE",
+				ArrivalTime:          "0735",
+				AircraftType:         "320",
+				DateVariation:        1,
+				ArrivalDateVariation: 1,
+			},
+		},
+		{
+			name:    "Fail because of an invalid flight number",
+			line:    leg(map[int]string{5: "12X4"}),
+			wantErr: fmt.Errorf("%w: flight_number", ErrInvalidRecord),
+		},
+		{
+			name:    "Fail because the period ends before it starts",
+			line:    leg(map[int]string{21: "30APR20"}),
+			wantErr: fmt.Errorf("%w: period_of_operation", ErrInvalidRecord),
+		},
+		{
+			name:    "Fail because a day is out of its position",
+			line:    leg(map[int]string{28: "2 3 5 7"}),
+			wantErr: fmt.Errorf("%w: days_of_operation", ErrInvalidRecord),
+		},
+		{
+			name:    "Fail because of an invalid departure time",
+			line:    leg(map[int]string{39: "2460"}),
+			wantErr: fmt.Errorf("%w: departure_time", ErrInvalidRecord),
+		},
+		{
+			name:    "Fail because of an invalid UTC variation",
+			line:    leg(map[int]string{47: "-05AA"}),
+			wantErr: fmt.Errorf("%w: departure_utc_variation", ErrInvalidRecord),
+		},
+		{
+			name:    "Fail because the leg arrives where it departs",
+			line:    leg(map[int]string{54: "BOG"}),
+			wantErr: fmt.Errorf("%w: arrival_station", ErrInvalidRecord),
+		},
+		{
+			name:    "Fail because the leg arrives before it departs",
+			line:    leg(map[int]string{61: "0600"}),
+			wantErr: fmt.Errorf("%w: arrival_time", ErrInvalidRecord),
+		},
+		{
+			name:    "Fail because the aircraft type is missing",
+			line:    leg(map[int]string{72: "   "}),
+			wantErr: fmt.Errorf("%w: aircraft_type", ErrInvalidRecord),
+		},
+		{
+			name:    "Fail because the record is too long",
+			line:    leg(nil) + " ",
+			wantErr: fmt.Errorf("%w: length", ErrInvalidRecord),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := ParseRecord(tt.line)
+
+			// Assert
+			if tt.wantErr != nil {
+				require.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(Record{}, "Location", "ArrivalLocation")); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			_, offset := time.Date(2020, 5, 1, 0, 0, 0, 0, got.Location).Zone()
+			require.Equal(t, -5*60*60, offset)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	file := strings.Join([]string{
+		"1AIRLINE STANDARD SCHEDULE DATA SET",
+		"",
+		"2LXX  0008    01MAY2031MAY20",
+		leg(nil),
+		leg(map[int]string{5: "ABCD"}),
+		"5 XX 000005E000006",
+	}, "\r\n")
+
+	records, lineErrors, err := Parse(strings.NewReader(file))
+
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, 4, records[0].Line)
+	require.Equal(t, "XX123", records[0].Flight())
+	require.Len(t, lineErrors, 1)
+	require.Equal(t, "line 5: invalid_ssim_record: flight_number", lineErrors[0].Error())
+}
+
+func TestRecord_Departures(t *testing.T) {
+	// 2020-05-01 is a Friday
+	from := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 5, 8, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		line string
+		want []Departure
+	}{
+		{
+			name: "Departures on the days of operation, the ones before from are left out",
+			line: leg(nil),
+			want: []Departure{
+				{FlightID: "XX123-20200503-BOG", Time: time.Date(2020, 5, 3, 11, 30, 0, 0, time.UTC), Arrival: time.Date(2020, 5, 3, 12, 35, 0, 0, time.UTC), OperatingDate: "2020-05-03"},
+				{FlightID: "XX123-20200504-BOG", Time: time.Date(2020, 5, 4, 11, 30, 0, 0, time.UTC), Arrival: time.Date(2020, 5, 4, 12, 35, 0, 0, time.UTC), OperatingDate: "2020-05-04"},
+				{FlightID: "XX123-20200506-BOG", Time: time.Date(2020, 5, 6, 11, 30, 0, 0, time.UTC), Arrival: time.Date(2020, 5, 6, 12, 35, 0, 0, time.UTC), OperatingDate: "2020-05-06"},
+			},
+		},
+		{
+			name: "A second leg departs the day after the flight operates",
+			line: leg(map[int]string{11: "02", 21: "00XXX00", 39: "0100", 192: "1", 193: "1"}),
+			want: []Departure{
+				{FlightID: "XX123-20200501-BOG", Time: time.Date(2020, 5, 2, 6, 0, 0, 0, time.UTC), Arrival: time.Date(2020, 5, 2, 12, 35, 0, 0, time.UTC), OperatingDate: "2020-05-01"},
+				{FlightID: "XX123-20200503-BOG", Time: time.Date(2020, 5, 4, 6, 0, 0, 0, time.UTC), Arrival: time.Date(2020, 5, 4, 12, 35, 0, 0, time.UTC), OperatingDate: "2020-05-03"},
+				{FlightID: "XX123-20200504-BOG", Time: time.Date(2020, 5, 5, 6, 0, 0, 0, time.UTC), Arrival: time.Date(2020, 5, 5, 12, 35, 0, 0, time.UTC), OperatingDate: "2020-05-04"},
+				{FlightID: "XX123-20200506-BOG", Time: time.Date(2020, 5, 7, 6, 0, 0, 0, time.UTC), Arrival: time.Date(2020, 5, 7, 12, 35, 0, 0, time.UTC), OperatingDate: "2020-05-06"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			record, err := ParseRecord(tt.line)
+			require.NoError(t, err)
+
+			// Act
+			got := record.Departures(from, to)
+
+			// Assert
+			if diff := cmp.Diff(tt.want, got, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package ssr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownSSR = errors.New("unknown_special_request")
+)
+
+type Code struct {
+	Description string
+	// ExitRow tells if the passenger can still sit in an exit row, passengers
+	// that need help or look after somebody else can not
+	ExitRow bool
+}
+
+// Catalog are the IATA special service request codes that are accepted
+var Catalog = map[string]Code{
+	"WCHR": {Description: "Wheelchair, can climb stairs", ExitRow: false},
+	"WCHS": {Description: "Wheelchair, can not climb stairs", ExitRow: false},
+	"WCHC": {Description: "Wheelchair, immobile", ExitRow: false},
+	"BLND": {Description: "Blind passenger", ExitRow: false},
+	"DEAF": {Description: "Deaf passenger", ExitRow: false},
+	"DPNA": {Description: "Disabled passenger needing assistance", ExitRow: false},
+	"MEDA": {Description: "Medical case", ExitRow: false},
+	"UMNR": {Description: "Unaccompanied minor", ExitRow: false},
+	"INFT": {Description: "Infant on lap", ExitRow: false},
+	"PETC": {Description: "Pet in cabin", ExitRow: false},
+	"VGML": {Description: "Vegetarian meal", ExitRow: true},
+	"VLML": {Description: "Vegetarian lacto-ovo meal", ExitRow: true},
+	"KSML": {Description: "Kosher meal", ExitRow: true},
+	"MOML": {Description: "Muslim meal", ExitRow: true},
+	"HNML": {Description: "Hindu meal", ExitRow: true},
+	"DBML": {Description: "Diabetic meal", ExitRow: true},
+	"GFML": {Description: "Gluten free meal", ExitRow: true},
+	"CHML": {Description: "Child meal", ExitRow: true},
+}
+
+// Validate checks the codes are in the catalog and returns them in upper case
+// without duplicates, nil when there are none
+func Validate(codes []string) ([]string, error) {
+	var valid []string
+	seen := map[string]bool{}
+	for _, c := range codes {
+		code := strings.ToUpper(strings.TrimSpace(c))
+		if _, ok := Catalog[code]; !ok {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownSSR, c)
+		}
+		if !seen[code] {
+			seen[code] = true
+			valid = append(valid, code)
+		}
+	}
+	return valid, nil
+}
+
+// AllowedInExitRow tells if a passenger with the codes can sit in an exit row
+func AllowedInExitRow(codes []string) bool {
+	for _, c := range codes {
+		if !Catalog[c].ExitRow {
+			return false
+		}
+	}
+	return true
+}
+
+// Describe writes the codes with their description, e.g. "WCHR (Wheelchair,
+// can climb stairs)"
+func Describe(codes []string) string {
+	described := make([]string, len(codes))
+	for i, c := range codes {
+		described[i] = c
+		if code, ok := Catalog[c]; ok {
+			described[i] = fmt.Sprintf("%v (%v)", c, code.Description)
+		}
+	}
+	return strings.Join(described, ", ")
+}
@@ -0,0 +1,55 @@
+package ssr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		codes   []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "Accept codes of the catalog in upper case without duplicates",
+			codes: []string{"wchr", "VGML", "WCHR"},
+			want:  []string{"WCHR", "VGML"},
+		},
+		{
+			name:  "Accept no codes",
+			codes: []string{},
+			want:  nil,
+		},
+		{
+			name:    "Reject codes that are not in the catalog",
+			codes:   []string{"VGML", "XXXX"},
+			wantErr: ErrUnknownSSR,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(tt.codes)
+
+			require.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			if tt.wantErr == nil {
+				require.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAllowedInExitRow(t *testing.T) {
+	require.True(t, AllowedInExitRow(nil))
+	require.True(t, AllowedInExitRow([]string{"VGML", "KSML"}))
+	require.False(t, AllowedInExitRow([]string{"VGML", "UMNR"}))
+	require.False(t, AllowedInExitRow([]string{"WCHR"}))
+}
+
+func TestDescribe(t *testing.T) {
+	require.Equal(t, "UMNR (Unaccompanied minor), VGML (Vegetarian meal)", Describe([]string{"UMNR", "VGML"}))
+}
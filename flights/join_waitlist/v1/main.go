@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+var (
+	ErrFlightHasFreeSeats = errors.New("flight_has_free_seats")
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+}
+
+type WaitlistRepository interface {
+	Join(flightID string, passengerID string, now time.Time) (model.WaitlistEntry, error)
+}
+
+type Request struct {
+	PassengerID string `json:"passenger_id"`
+}
+
+type Response struct {
+	FlightID    string `json:"flight_id"`
+	PassengerID string `json:"passenger_id"`
+	JoinedAt    string `json:"joined_at"`
+	Status      string `json:"status"`
+}
+
+func Adapter(flightsRepo FlightsRepository, waitlistRepo WaitlistRepository, now func() time.Time) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		flightID := req.PathParameters["flightID"]
+
+		request := Request{}
+		err := json.Unmarshal([]byte(req.Body), &request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Validations
+		if internal.TrimLines(request.PassengerID) == "" {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+
+		// Only full flights that can still be booked have a waitlist
+		flight, err := flightsRepo.Find(flightID)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+		if !flight.IsBookable() {
+			return internal.Error(http.StatusUnprocessableEntity, repository.ErrFlightClosed), nil
+		}
+		if flight.HasFreeSeats {
+			return internal.Error(http.StatusUnprocessableEntity, ErrFlightHasFreeSeats), nil
+		}
+
+		// Join the waitlist
+		entry, err := waitlistRepo.Join(flight.ID, request.PassengerID, now())
+		if err == repository.ErrAlreadyWaitlisted {
+			return internal.Error(http.StatusConflict, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Respond
+		responseBytes, _ := json.Marshal(Response{
+			FlightID:    entry.FlightID,
+			PassengerID: entry.PassengerID,
+			JoinedAt:    entry.JoinedAt,
+			Status:      entry.Status,
+		})
+		return internal.Respond(http.StatusCreated, string(responseBytes)), nil
+	}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	waitlistTable := os.Getenv("DYNAMODB_WAITLIST")
+	if internal.TrimLines(waitlistTable) == "" {
+		panic("DYNAMODB_WAITLIST is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	waitlistRepo := repository.NewWaitlistRepository(dynamodbClient, waitlistTable)
+	lambda.Start(Adapter(flightsRepo, waitlistRepo, time.Now))
+}
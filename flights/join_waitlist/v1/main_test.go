@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+type WaitlistRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WaitlistRepositoryMock) Join(flightID string, passengerID string, now time.Time) (model.WaitlistEntry, error) {
+	ret := m.Called(flightID, passengerID, now)
+	return ret.Get(0).(model.WaitlistEntry), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo  *FlightsRepositoryMock
+		waitlistRepo *WaitlistRepositoryMock
+	}
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	fullFlight := model.Flight{
+		ID:           "f1",
+		Departure:    "2020-05-02T00:00:00+0000",
+		HasFreeSeats: false,
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 201 status code after joining the waitlist of a full flight",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f1",
+				},
+				Body: `{"passenger_id": "someone@some.com"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusCreated,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"flight_id":"f1",
+					"passenger_id":"someone@some.com",
+					"joined_at":"2020-05-01T12:00:00.000000000Z",
+					"status":"waiting"
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo:  &FlightsRepositoryMock{},
+				waitlistRepo: &WaitlistRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(fullFlight, nil).Once()
+				m.waitlistRepo.On("Join", "f1", "someone@some.com", now).Return(
+					model.WaitlistEntry{
+						FlightID:    "f1",
+						JoinedAt:    "2020-05-01T12:00:00.000000000Z",
+						PassengerID: "someone@some.com",
+						Status:      model.WaitlistStatusWaiting,
+					},
+					nil,
+				).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the flight still has free seats",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f1",
+				},
+				Body: `{"passenger_id": "someone@some.com"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, ErrFlightHasFreeSeats),
+				),
+			},
+			mocks: mocks{
+				flightsRepo:  &FlightsRepositoryMock{},
+				waitlistRepo: &WaitlistRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(model.Flight{ID: "f1", HasFreeSeats: true}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the flight was cancelled",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f1",
+				},
+				Body: `{"passenger_id": "someone@some.com"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrFlightClosed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo:  &FlightsRepositoryMock{},
+				waitlistRepo: &WaitlistRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(model.Flight{ID: "f1", Status: model.FlightStatusCancelled}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because the passenger is already waiting",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f1",
+				},
+				Body: `{"passenger_id": "someone@some.com"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrAlreadyWaitlisted),
+				),
+			},
+			mocks: mocks{
+				flightsRepo:  &FlightsRepositoryMock{},
+				waitlistRepo: &WaitlistRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(fullFlight, nil).Once()
+				m.waitlistRepo.On("Join", "f1", "someone@some.com", now).Return(model.WaitlistEntry{}, repository.ErrAlreadyWaitlisted).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because the flight was not found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f2",
+				},
+				Body: `{"passenger_id": "someone@some.com"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo:  &FlightsRepositoryMock{},
+				waitlistRepo: &WaitlistRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f2").Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because the passenger is missing",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f1",
+				},
+				Body: `{}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				flightsRepo:  &FlightsRepositoryMock{},
+				waitlistRepo: &WaitlistRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo, tt.mocks.waitlistRepo, func() time.Time { return now })
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+			tt.mocks.waitlistRepo.AssertExpectations(t)
+		})
+	}
+
+}
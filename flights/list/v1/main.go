@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -21,33 +24,85 @@ type Response []ResponseFlight
 
 type ResponseFlight struct {
 	ID           string               `json:"id"`
+	FlightNumber string               `json:"flight_number"`
 	Departure    string               `json:"departure"`
 	HasFreeSeats bool                 `json:"has_free_seats"`
 	Seats        []ResponseFlightSeat `json:"seats"`
 }
 
+// ResponseFlightSeat tells if the seat is taken without telling who took it
 type ResponseFlightSeat struct {
-	ID          string `json:"id"`
-	Letter      string `json:"letter"`
-	Row         int    `json:"row"`
-	PassengerID string `json:"passenger_id"`
+	ID           string `json:"id"`
+	Letter       string `json:"letter"`
+	Row          int    `json:"row"`
+	Taken        bool   `json:"taken"`
+	Position     string `json:"position"`
+	Cabin        string `json:"cabin"`
+	ExitRow      bool   `json:"exit_row"`
+	ExtraLegroom bool   `json:"extra_legroom"`
+}
+
+// SeatFilter keeps the flights having at least one free seat with the given
+// attributes, empty fields are not filtered
+type SeatFilter struct {
+	Position     string
+	Cabin        string
+	ExitRow      *bool
+	ExtraLegroom *bool
+}
+
+// Time zones go from UTC-12 to UTC+14, a local day starts at most 14 hours
+// before the UTC one and ends at most 12 hours after it
+const (
+	earliestOffset = 14 * time.Hour
+	latestOffset   = 12 * time.Hour
+)
+
+// dateLayout is a bound of the range with no time, it is the day in the local
+// time of the origin of each flight
+const dateLayout = "2006-01-02"
+
+// DepartureRange keeps the flights departing between two dates or two
+// departure times, both bounds are included
+type DepartureRange struct {
+	From     time.Time
+	FromDate bool
+	To       time.Time
+	ToDate   bool
 }
 
 type FlightsRepository interface {
 	ListFlightsByDeparture(dateFrom string, dateTo string) ([]model.Flight, error)
+	ListFlightsByFlightNumber(carrier string, flightNumber string, dateFrom string, dateTo string) ([]model.Flight, error)
 }
 
-func Adapter(flightsRepo FlightsRepository) Handler {
+func Adapter(flightsRepo FlightsRepository, now func() time.Time) Handler {
 	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		// Get request parameters
-		dateFrom := req.PathParameters["dateFrom"]
-		dateTo := req.PathParameters["dateTo"]
-
-		// Look for flights
-		flights, err := flightsRepo.ListFlightsByDeparture(
-			dateFrom,
-			dateTo,
-		)
+		departureRange, err := getDepartureRange(req.PathParameters["dateFrom"], req.PathParameters["dateTo"])
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+		filter, err := getSeatFilter(req.QueryStringParameters)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+		designator, hasFlightNumber := req.QueryStringParameters["flight_number"]
+		carrier, flightNumber, ok := model.ParseDesignator(designator)
+		if hasFlightNumber && !ok {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid flight_number")), nil
+		}
+
+		// Look for flights, dates are widened to every time zone and then
+		// compared with the local departure of each flight
+		var flights []model.Flight
+		if hasFlightNumber {
+			dateFrom, dateTo := departureRange.operatingDates()
+			flights, err = flightsRepo.ListFlightsByFlightNumber(carrier, flightNumber, dateFrom, dateTo)
+		} else {
+			dateFrom, dateTo := departureRange.query()
+			flights, err = flightsRepo.ListFlightsByDeparture(dateFrom, dateTo)
+		}
 		if err == repository.ErrNoFlightsFound {
 			return internal.Error(http.StatusNotFound, err), nil
 		}
@@ -55,6 +110,13 @@ func Adapter(flightsRepo FlightsRepository) Handler {
 			return internal.Error(http.StatusInternalServerError, err), nil
 		}
 
+		// Keep only the flights in the range with the requested kind of seats
+		checkedAt := now()
+		flights = filterFlights(flights, departureRange, filter, checkedAt)
+		if len(flights) == 0 {
+			return internal.Error(http.StatusNotFound, repository.ErrNoFlightsFound), nil
+		}
+
 		// Prepare response
 		response := make(Response, len(flights))
 		for i, f := range flights {
@@ -64,11 +126,18 @@ func Adapter(flightsRepo FlightsRepository) Handler {
 				rSeat.ID = s.ID
 				rSeat.Letter = s.Letter
 				rSeat.Row = s.Row
-				rSeat.PassengerID = s.PassengerID
+				rSeat.Taken = !s.IsFree(checkedAt)
+				rSeat.Position = s.Position
+				rSeat.Cabin = s.Cabin
+				rSeat.ExitRow = s.ExitRow
+				rSeat.ExtraLegroom = s.ExtraLegroom
 				rSeats[j] = rSeat
 			}
 			rFlight := ResponseFlight{}
 			rFlight.ID = f.ID
+			if f.Carrier != "" {
+				rFlight.FlightNumber = model.Designator(f.Carrier, f.FlightNumber)
+			}
 			rFlight.Departure = f.Departure
 			rFlight.HasFreeSeats = f.HasFreeSeats
 			rFlight.Seats = rSeats
@@ -81,13 +150,143 @@ func Adapter(flightsRepo FlightsRepository) Handler {
 	}
 }
 
+func getDepartureRange(dateFrom string, dateTo string) (DepartureRange, error) {
+	departureRange := DepartureRange{}
+	var err error
+	departureRange.From, departureRange.FromDate, err = parseBound(dateFrom)
+	if err != nil {
+		return DepartureRange{}, errors.New("invalid dateFrom")
+	}
+	departureRange.To, departureRange.ToDate, err = parseBound(dateTo)
+	if err != nil {
+		return DepartureRange{}, errors.New("invalid dateTo")
+	}
+	if departureRange.From.After(departureRange.To) {
+		return DepartureRange{}, errors.New("invalid date range")
+	}
+	return departureRange, nil
+}
+
+// parseBound reads a date like 2019-11-25 or a departure time, it tells if it
+// is a date
+func parseBound(bound string) (time.Time, bool, error) {
+	date, err := time.Parse(dateLayout, bound)
+	if err == nil {
+		return date, true, nil
+	}
+	departure, err := model.ParseDeparture(bound)
+	return departure, false, err
+}
+
+// query returns the departures to look for in UTC
+func (r DepartureRange) query() (string, string) {
+	from := r.From
+	if r.FromDate {
+		from = from.Add(-earliestOffset)
+	}
+	to := r.To
+	if r.ToDate {
+		to = to.Add(24*time.Hour + latestOffset - time.Second)
+	}
+	return model.FormatDeparture(from), model.FormatDeparture(to)
+}
+
+// operatingDates returns the operating dates to look for, a day before and
+// after the range as departure times are in UTC and delays can move the
+// departure to the day after the operating date
+func (r DepartureRange) operatingDates() (string, string) {
+	return r.From.AddDate(0, 0, -1).Format(model.OperatingDateLayout),
+		r.To.AddDate(0, 0, 1).Format(model.OperatingDateLayout)
+}
+
+// contains tells if the flight departs in the range, dates are compared with
+// the local date at the origin. Flights without a time zone are in UTC
+func (r DepartureRange) contains(flight model.Flight) bool {
+	departure, err := model.ParseDeparture(flight.Departure)
+	if err != nil {
+		return false
+	}
+	local := departure.In(flight.Location())
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+
+	if (r.FromDate && date.Before(r.From)) || (!r.FromDate && departure.Before(r.From)) {
+		return false
+	}
+	if (r.ToDate && date.After(r.To)) || (!r.ToDate && departure.After(r.To)) {
+		return false
+	}
+	return true
+}
+
+func getSeatFilter(params map[string]string) (SeatFilter, error) {
+	filter := SeatFilter{
+		Position: params["position"],
+		Cabin:    params["cabin"],
+	}
+
+	if v, ok := params["exit_row"]; ok {
+		exitRow, err := strconv.ParseBool(v)
+		if err != nil {
+			return SeatFilter{}, errors.New("invalid exit_row")
+		}
+		filter.ExitRow = &exitRow
+	}
+	if v, ok := params["extra_legroom"]; ok {
+		extraLegroom, err := strconv.ParseBool(v)
+		if err != nil {
+			return SeatFilter{}, errors.New("invalid extra_legroom")
+		}
+		filter.ExtraLegroom = &extraLegroom
+	}
+
+	return filter, nil
+}
+
+// filterFlights keeps the flights in the range with a free seat matching the
+// filter, seats held until before now are free
+func filterFlights(flights []model.Flight, departureRange DepartureRange, filter SeatFilter, now time.Time) []model.Flight {
+	filtered := []model.Flight{}
+	for _, f := range flights {
+		if !departureRange.contains(f) {
+			continue
+		}
+		for _, s := range f.Seats {
+			if s.IsFree(now) && filter.matches(s) {
+				filtered = append(filtered, f)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func (f SeatFilter) matches(s model.FlightSeat) bool {
+	if f.Position != "" && f.Position != s.Position {
+		return false
+	}
+	if f.Cabin != "" && f.Cabin != s.Cabin {
+		return false
+	}
+	if f.ExitRow != nil && *f.ExitRow != s.ExitRow {
+		return false
+	}
+	if f.ExtraLegroom != nil && *f.ExtraLegroom != s.ExtraLegroom {
+		return false
+	}
+	return true
+}
+
 func main() {
 	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
 	if internal.TrimLines(flightsTable) == "" {
 		panic("DYNAMODB_FLIGHTS is empty")
 	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
 	session := session.New()
 	dynamodbClient := dynamodb.New(session)
-	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable)
-	lambda.Start(Adapter(flightsRepo))
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	lambda.Start(Adapter(flightsRepo, time.Now))
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/google/go-cmp/cmp"
@@ -23,12 +24,19 @@ func (m *FlightsRepositoryMock) ListFlightsByDeparture(dateFrom string, dateTo s
 	return args.Get(0).([]model.Flight), args.Error(1)
 }
 
+func (m *FlightsRepositoryMock) ListFlightsByFlightNumber(carrier string, flightNumber string, dateFrom string, dateTo string) ([]model.Flight, error) {
+	args := m.Called(carrier, flightNumber, dateFrom, dateTo)
+	return args.Get(0).([]model.Flight), args.Error(1)
+}
+
 func TestAdapter(t *testing.T) {
 
 	type mocks struct {
 		flightsRepo *FlightsRepositoryMock
 	}
 
+	now := time.Date(2019, 11, 25, 12, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name   string
 		mocks  mocks
@@ -55,6 +63,7 @@ func TestAdapter(t *testing.T) {
 				Body: internal.TrimLines(`[
 					{
 						"id":"flight-1",
+						"flight_number":"",
 						"departure":"2019-11-26T09:25:00+0000",
 						"has_free_seats": true,
 						"seats":[
@@ -62,18 +71,27 @@ func TestAdapter(t *testing.T) {
 								"id":"seat-1",
 								"letter":"A",
 								"row":1,
-								"passenger_id":""
+								"taken":false,
+								"position":"",
+								"cabin":"",
+								"exit_row":false,
+								"extra_legroom":false
 							},
 							{
 								"id":"seat-2",
 								"letter":"B",
 								"row":1,
-								"passenger_id":"p1"
+								"taken":true,
+								"position":"",
+								"cabin":"",
+								"exit_row":false,
+								"extra_legroom":false
 							}
 						]
 					},
 					{
 						"id":"flight-2",
+						"flight_number":"",
 						"departure":"2019-11-26T09:25:00+0000",
 						"has_free_seats": true,
 						"seats":[
@@ -81,13 +99,21 @@ func TestAdapter(t *testing.T) {
 								"id":"seat-1",
 								"letter":"B",
 								"row":1,
-								"passenger_id":""
+								"taken":false,
+								"position":"",
+								"cabin":"",
+								"exit_row":false,
+								"extra_legroom":false
 							},
 							{
 								"id":"seat-2",
 								"letter":"B",
 								"row":2,
-								"passenger_id":"p2"
+								"taken":true,
+								"position":"",
+								"cabin":"",
+								"exit_row":false,
+								"extra_legroom":false
 							}
 						]
 					}
@@ -96,8 +122,8 @@ func TestAdapter(t *testing.T) {
 			mocker: func(m mocks) {
 				m.flightsRepo.On(
 					"ListFlightsByDeparture",
-					"2019-11-25",
-					"2019-11-27",
+					"2019-11-24T10:00:00Z",
+					"2019-11-28T11:59:59Z",
 				).Return([]model.Flight{
 					{
 						ID:           "flight-1",
@@ -162,8 +188,8 @@ func TestAdapter(t *testing.T) {
 			mocker: func(m mocks) {
 				m.flightsRepo.On(
 					"ListFlightsByDeparture",
-					"2019-11-25",
-					"2019-11-27",
+					"2019-11-24T10:00:00Z",
+					"2019-11-28T11:59:59Z",
 				).Return(
 					[]model.Flight{},
 					errors.New("Some error"),
@@ -193,14 +219,327 @@ func TestAdapter(t *testing.T) {
 			mocker: func(m mocks) {
 				m.flightsRepo.On(
 					"ListFlightsByDeparture",
-					"2019-11-25",
-					"2019-11-27",
+					"2019-11-24T10:00:00Z",
+					"2019-11-28T11:59:59Z",
 				).Return(
 					[]model.Flight{},
 					repository.ErrNoFlightsFound,
 				).Once()
 			},
 		},
+		{
+			name: "Return a 200 status code with the flights having free aisle seats",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "2019-11-25",
+					"dateTo":   "2019-11-27",
+				},
+				QueryStringParameters: map[string]string{
+					"position": "aisle",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 200,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`[
+					{
+						"id":"flight-2",
+						"flight_number":"",
+						"departure":"2019-11-26T09:25:00+0000",
+						"has_free_seats": true,
+						"seats":[
+							{
+								"id":"1C",
+								"letter":"C",
+								"row":1,
+								"taken":false,
+								"position":"aisle",
+								"cabin":"economy",
+								"exit_row":false,
+								"extra_legroom":false
+							}
+						]
+					}
+				]`),
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On(
+					"ListFlightsByDeparture",
+					"2019-11-24T10:00:00Z",
+					"2019-11-28T11:59:59Z",
+				).Return([]model.Flight{
+					{
+						ID:           "flight-1",
+						Departure:    "2019-11-26T09:25:00+0000",
+						HasFreeSeats: true,
+						Seats: []model.FlightSeat{
+							{
+								ID:       "1A",
+								Letter:   "A",
+								Row:      1,
+								Position: model.SeatPositionWindow,
+								Cabin:    model.CabinEconomy,
+							},
+							{
+								ID:          "1C",
+								Letter:      "C",
+								Row:         1,
+								PassengerID: "p1",
+								Position:    model.SeatPositionAisle,
+								Cabin:       model.CabinEconomy,
+							},
+						},
+					},
+					{
+						ID:           "flight-2",
+						Departure:    "2019-11-26T09:25:00+0000",
+						HasFreeSeats: true,
+						Seats: []model.FlightSeat{
+							{
+								ID:       "1C",
+								Letter:   "C",
+								Row:      1,
+								Position: model.SeatPositionAisle,
+								Cabin:    model.CabinEconomy,
+							},
+						},
+					},
+				}, nil).Once()
+			},
+		},
+		{
+			name: "Return a 200 status code with the flights departing on the dates in the local time of their origin",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "2019-11-25",
+					"dateTo":   "2019-11-25",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 200,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`[
+					{"id":"late-in-bogota","flight_number":"","departure":"2019-11-26T03:00:00Z","has_free_seats":true,"seats":[
+							{"id":"1A","letter":"A","row":1,"taken":false,"position":"","cabin":"","exit_row":false,"extra_legroom":false}
+					]},
+					{"id":"without-time-zone","flight_number":"","departure":"2019-11-25T23:00:00+0000","has_free_seats":true,"seats":[
+							{"id":"1A","letter":"A","row":1,"taken":false,"position":"","cabin":"","exit_row":false,"extra_legroom":false}
+					]}
+				]`),
+			},
+			mocker: func(m mocks) {
+				seats := []model.FlightSeat{{ID: "1A", Letter: "A", Row: 1}}
+				m.flightsRepo.On(
+					"ListFlightsByDeparture",
+					"2019-11-24T10:00:00Z",
+					"2019-11-26T11:59:59Z",
+				).Return([]model.Flight{
+					{ID: "early-in-bogota", Departure: "2019-11-25T02:00:00Z", OriginTimeZone: "America/Bogota", HasFreeSeats: true, Seats: seats},
+					{ID: "late-in-bogota", Departure: "2019-11-26T03:00:00Z", OriginTimeZone: "America/Bogota", HasFreeSeats: true, Seats: seats},
+					{ID: "without-time-zone", Departure: "2019-11-25T23:00:00+0000", HasFreeSeats: true, Seats: seats},
+				}, nil).Once()
+			},
+		},
+		{
+			name: "Return a 200 status code with the flights departing between two times",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "2019-11-25T10:00:00-05:00",
+					"dateTo":   "2019-11-25T18:00:00Z",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 200,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`[
+					{"id":"f1","flight_number":"","departure":"2019-11-25T16:00:00Z","has_free_seats":true,"seats":[
+							{"id":"1A","letter":"A","row":1,"taken":false,"position":"","cabin":"","exit_row":false,"extra_legroom":false}
+					]}
+				]`),
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On(
+					"ListFlightsByDeparture",
+					"2019-11-25T15:00:00Z",
+					"2019-11-25T18:00:00Z",
+				).Return([]model.Flight{
+					{ID: "f1", Departure: "2019-11-25T16:00:00Z", HasFreeSeats: true, Seats: []model.FlightSeat{{ID: "1A", Letter: "A", Row: 1}}},
+				}, nil).Once()
+			},
+		},
+		{
+			name: "Return a 200 status code with the flights whose seat hold expired",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "2019-11-25T10:00:00Z",
+					"dateTo":   "2019-11-25T18:00:00Z",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 200,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`[
+					{"id":"expired-hold","flight_number":"","departure":"2019-11-25T16:00:00Z","has_free_seats":false,"seats":[
+							{"id":"1A","letter":"A","row":1,"taken":false,"position":"","cabin":"","exit_row":false,"extra_legroom":false}
+					]}
+				]`),
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On(
+					"ListFlightsByDeparture",
+					"2019-11-25T10:00:00Z",
+					"2019-11-25T18:00:00Z",
+				).Return([]model.Flight{
+					{ID: "expired-hold", Departure: "2019-11-25T16:00:00Z", Seats: []model.FlightSeat{
+						{ID: "1A", Letter: "A", Row: 1, PassengerID: "p1", HeldUntil: "2019-11-25T11:59:00Z"},
+					}},
+					{ID: "active-hold", Departure: "2019-11-25T16:00:00Z", Seats: []model.FlightSeat{
+						{ID: "1A", Letter: "A", Row: 1, PassengerID: "p1", HeldUntil: "2019-11-25T12:10:00Z"},
+					}},
+				}, nil).Once()
+			},
+		},
+		{
+			name: "Return a 200 status code with the flights of a flight number on the dates",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "2019-11-25",
+					"dateTo":   "2019-11-25",
+				},
+				QueryStringParameters: map[string]string{
+					"flight_number": "av 0123",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 200,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`[
+					{"id":"AV123-20191125","flight_number":"AV123","departure":"2019-11-26T03:00:00Z","has_free_seats":true,"seats":[
+							{"id":"1A","letter":"A","row":1,"taken":false,"position":"","cabin":"","exit_row":false,"extra_legroom":false}
+					]}
+				]`),
+			},
+			mocker: func(m mocks) {
+				seats := []model.FlightSeat{{ID: "1A", Letter: "A", Row: 1}}
+				m.flightsRepo.On("ListFlightsByFlightNumber", "AV", "123", "2019-11-24", "2019-11-26").Return([]model.Flight{
+					{ID: "AV123-20191124", Carrier: "AV", FlightNumber: "123", Departure: "2019-11-25T03:00:00Z", OriginTimeZone: "America/Bogota", HasFreeSeats: true, Seats: seats},
+					{ID: "AV123-20191125", Carrier: "AV", FlightNumber: "123", Departure: "2019-11-26T03:00:00Z", OriginTimeZone: "America/Bogota", HasFreeSeats: true, Seats: seats},
+				}, nil).Once()
+			},
+		},
+		{
+			name: "Return a 400 status code because the flight number is malformed",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "2019-11-25",
+					"dateTo":   "2019-11-25",
+				},
+				QueryStringParameters: map[string]string{
+					"flight_number": "123",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 400,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid flight_number"]}`),
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Return a 400 status code because a date is malformed",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "25-11-2019",
+					"dateTo":   "2019-11-27",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 400,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid dateFrom"]}`),
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Return a 400 status code because the range ends before it starts",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "2019-11-27",
+					"dateTo":   "2019-11-25",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 400,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid date range"]}`),
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Return a 400 status code because the exit_row filter is not a boolean",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"dateFrom": "2019-11-25",
+					"dateTo":   "2019-11-27",
+				},
+				QueryStringParameters: map[string]string{
+					"exit_row": "maybe",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 400,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+						"errors":["invalid exit_row"]
+					}`),
+			},
+			mocker: func(m mocks) {},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -208,7 +547,7 @@ func TestAdapter(t *testing.T) {
 			tt.mocker(tt.mocks)
 
 			// Act
-			handler := Adapter(tt.mocks.flightsRepo)
+			handler := Adapter(tt.mocks.flightsRepo, func() time.Time { return now })
 			got, err := handler(context.Background(), tt.req)
 
 			// Assert
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type ReservationsRepository interface {
+	ListUpcomingByPassenger(passengerID string, now time.Time) ([]model.Reservation, error)
+	ListPastByPassenger(passengerID string, now time.Time) ([]model.Reservation, error)
+}
+
+type Response struct {
+	Upcoming []ResponseReservation `json:"upcoming"`
+	Past     []ResponseReservation `json:"past"`
+}
+
+type ResponseReservation struct {
+	Locator   string      `json:"locator"`
+	FlightID  string      `json:"flight_id"`
+	SeatID    string      `json:"seat_id"`
+	Departure string      `json:"departure"`
+	Price     model.Price `json:"price"`
+	CreatedAt string      `json:"created_at"`
+}
+
+func Adapter(reservationsRepo ReservationsRepository, now func() time.Time) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// The passenger is the signed in user
+		passengerID, err := internal.AuthenticatedEmail(req)
+		if err != nil {
+			return internal.Error(http.StatusUnauthorized, err), nil
+		}
+
+		// Find the reservations
+		upcoming, err := reservationsRepo.ListUpcomingByPassenger(passengerID, now())
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+		past, err := reservationsRepo.ListPastByPassenger(passengerID, now())
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Respond
+		responseBytes, _ := json.Marshal(Response{
+			Upcoming: toResponse(upcoming),
+			Past:     toResponse(past),
+		})
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func toResponse(reservations []model.Reservation) []ResponseReservation {
+	response := make([]ResponseReservation, len(reservations))
+	for i, r := range reservations {
+		response[i] = ResponseReservation{
+			Locator:   r.Locator,
+			FlightID:  r.FlightID,
+			SeatID:    r.SeatID,
+			Departure: r.Departure,
+			Price:     r.Price,
+			CreatedAt: r.CreatedAt,
+		}
+	}
+	return response
+}
+
+func main() {
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	reservationsRepo := repository.NewReservationsRepository(dynamodbClient, reservationsTable)
+	lambda.Start(Adapter(reservationsRepo, time.Now))
+}
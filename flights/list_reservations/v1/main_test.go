@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type ReservationsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *ReservationsRepositoryMock) ListUpcomingByPassenger(passengerID string, now time.Time) ([]model.Reservation, error) {
+	ret := m.Called(passengerID, now)
+	return ret.Get(0).([]model.Reservation), ret.Error(1)
+}
+
+func (m *ReservationsRepositoryMock) ListPastByPassenger(passengerID string, now time.Time) ([]model.Reservation, error) {
+	ret := m.Called(passengerID, now)
+	return ret.Get(0).([]model.Reservation), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		reservationsRepo *ReservationsRepositoryMock
+	}
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	signedIn := events.APIGatewayProxyRequestContext{
+		Authorizer: map[string]interface{}{
+			"claims": map[string]interface{}{
+				"email": "someone@some.com",
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code with the upcoming and past reservations of the passenger",
+			req: events.APIGatewayProxyRequest{
+				RequestContext: signedIn,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"upcoming":[
+						{
+							"locator":"K7QM2X",
+							"flight_id":"f2",
+							"seat_id":"12C",
+							"departure":"2020-06-01T09:00:00+0000",
+							"price":{"amount":11500,"currency":"USD"},
+							"created_at":"2020-04-28T10:00:00Z"
+						}
+					],
+					"past":[
+						{
+							"locator":"P4TW9C",
+							"flight_id":"f1",
+							"seat_id":"3A",
+							"departure":"2020-04-01T09:00:00+0000",
+							"price":{"amount":0,"currency":""},
+							"created_at":"2020-03-20T10:00:00Z"
+						}
+					]
+				}`),
+			},
+			mocks: mocks{
+				reservationsRepo: &ReservationsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("ListUpcomingByPassenger", "someone@some.com", now).Return(
+					[]model.Reservation{
+						{
+							Locator:     "K7QM2X",
+							FlightID:    "f2",
+							SeatID:      "12C",
+							PassengerID: "someone@some.com",
+							Departure:   "2020-06-01T09:00:00+0000",
+							Price: model.Price{
+								Amount:   11500,
+								Currency: "USD",
+							},
+							PaymentID: "auth-1",
+							CreatedAt: "2020-04-28T10:00:00Z",
+						},
+					},
+					nil,
+				).Once()
+				m.reservationsRepo.On("ListPastByPassenger", "someone@some.com", now).Return(
+					[]model.Reservation{
+						{
+							Locator:     "P4TW9C",
+							FlightID:    "f1",
+							SeatID:      "3A",
+							PassengerID: "someone@some.com",
+							Departure:   "2020-04-01T09:00:00+0000",
+							CreatedAt:   "2020-03-20T10:00:00Z",
+						},
+					},
+					nil,
+				).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code with empty lists because the passenger has no reservations",
+			req: events.APIGatewayProxyRequest{
+				RequestContext: signedIn,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"upcoming":[],"past":[]}`),
+			},
+			mocks: mocks{
+				reservationsRepo: &ReservationsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("ListUpcomingByPassenger", "someone@some.com", now).Return([]model.Reservation{}, nil).Once()
+				m.reservationsRepo.On("ListPastByPassenger", "someone@some.com", now).Return([]model.Reservation{}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 401 status because the request has no signed in user",
+			req:  events.APIGatewayProxyRequest{},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnauthorized,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, internal.ErrUnauthenticated),
+				),
+			},
+			mocks: mocks{
+				reservationsRepo: &ReservationsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 500 status because the reservations could not be listed",
+			req: events.APIGatewayProxyRequest{
+				RequestContext: signedIn,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocks: mocks{
+				reservationsRepo: &ReservationsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.reservationsRepo.On("ListUpcomingByPassenger", "someone@some.com", now).Return([]model.Reservation{}, errors.New("unexpected")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.reservationsRepo, func() time.Time { return now })
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.reservationsRepo.AssertExpectations(t)
+		})
+	}
+
+}
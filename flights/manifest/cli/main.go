@@ -0,0 +1,50 @@
+// Command cli exports the manifest of a flight to the standard output, e.g.
+//
+//	go run ./flights/manifest/cli -flight <id> -format csv -flights-table dev-flights
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/manifest"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+)
+
+func main() {
+	flightID := flag.String("flight", "", "id of the flight")
+	format := flag.String("format", manifest.FormatCSV, "csv or json")
+	flightsTable := flag.String("flights-table", os.Getenv("DYNAMODB_FLIGHTS"), "flights table")
+	reservationsTable := flag.String("reservations-table", os.Getenv("DYNAMODB_RESERVATIONS"), "reservations table")
+	flag.Parse()
+
+	if *flightID == "" || *flightsTable == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !manifest.IsFormat(*format) {
+		fail(manifest.ErrUnknownFormat)
+	}
+
+	session := session.Must(session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	}))
+	flightsRepo := repository.NewFlightsRepository(dynamodb.New(session), *flightsTable, *reservationsTable)
+	flight, err := flightsRepo.Find(*flightID)
+	if err != nil {
+		fail(err)
+	}
+
+	err = manifest.Write(os.Stdout, manifest.Build(flight), *format)
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/manifest"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+}
+
+func Adapter(flightsRepo FlightsRepository) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		flightID := req.PathParameters["id"]
+		format := req.QueryStringParameters["format"]
+		if format == "" {
+			format = manifest.FormatJSON
+		}
+
+		// Validations
+		if internal.TrimLines(flightID) == "" {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+		if !manifest.IsFormat(format) {
+			return internal.Error(http.StatusBadRequest, manifest.ErrUnknownFormat), nil
+		}
+
+		flight, err := flightsRepo.Find(flightID)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		body := bytes.Buffer{}
+		err = manifest.Write(&body, manifest.Build(flight), format)
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		response := internal.Respond(http.StatusOK, body.String())
+		response.Headers["Content-Type"] = manifest.ContentType(format)
+		return response, nil
+	}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	lambda.Start(Adapter(flightsRepo))
+}
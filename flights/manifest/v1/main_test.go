@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/manifest"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+	}
+
+	flight := model.Flight{
+		ID:          "f1",
+		Departure:   "2020-05-01T00:00:00+0000",
+		Origin:      "BOG",
+		Destination: "MDE",
+		Seats: []model.FlightSeat{
+			{ID: "3A", Row: 3, Letter: "A", Cabin: model.CabinEconomy, PassengerID: "p2", Locator: "BBBBBB"},
+			{ID: "1A", Row: 1, Letter: "A", Cabin: model.CabinEconomy, PassengerID: "p1", Locator: "AAAAAA",
+				CheckedInAt: "2020-04-30T10:00:00Z", SpecialRequests: []string{"WCHR"}},
+			{ID: "1B", Row: 1, Letter: "B", Cabin: model.CabinEconomy},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code with the manifest as JSON by default",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"flight_id":"f1",
+					"departure":"2020-05-01T00:00:00+0000",
+					"origin":"BOG",
+					"destination":"MDE",
+					"status":"scheduled",
+					"passengers":[
+						{"seat_id":"1A","row":1,"letter":"A","cabin":"economy","passenger_id":"p1","locator":"AAAAAA",
+						"checked_in":true,"checked_in_at":"2020-04-30T10:00:00Z","special_requests":["WCHR"]},
+						{"seat_id":"3A","row":3,"letter":"A","cabin":"economy","passenger_id":"p2","locator":"BBBBBB",
+						"checked_in":false,"checked_in_at":"","special_requests":[]}
+					]
+				}`) + "\n",
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code with the manifest as CSV",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				QueryStringParameters: map[string]string{
+					"format": "csv",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "text/csv",
+				},
+				Body: "row,letter,seat_id,cabin,passenger_id,locator,checked_in,checked_in_at,special_requests\n" +
+					"1,A,1A,economy,p1,AAAAAA,true,2020-04-30T10:00:00Z,WCHR\n" +
+					"3,A,3A,economy,p2,BBBBBB,false,,\n",
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because of an unknown format",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				QueryStringParameters: map[string]string{
+					"format": "xml",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, manifest.ErrUnknownFormat),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 404 status because the flight was not found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f2",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f2").Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+			},
+		},
+		{
+			name: "Get a 500 status because the flight could not be found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(model.Flight{}, errors.New("unexpected")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+		})
+	}
+
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+var (
+	ErrUnknownMsgType = errors.New("unknown_msg_type")
+)
+
+// offerDuration is how long a waitlisted passenger has to confirm the seat,
+// it can not be longer than the 15 minutes SQS can delay a message
+const offerDuration = 15 * time.Minute
+
+type Handler func(ctx context.Context, event events.SQSEvent) error
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	HoldSeat(reservation model.Reservation, until time.Time) error
+	ReleaseSeat(flightID string, seatID string, passengerID string) error
+	ReleaseExpiredSeat(flightID string, seatID string, passengerID string, now time.Time) error
+}
+
+type WaitlistRepository interface {
+	NextWaiting(flightID string) (model.WaitlistEntry, error)
+	Find(flightID string, joinedAt string) (model.WaitlistEntry, error)
+	Offer(entry model.WaitlistEntry, seatID string, until time.Time) (model.WaitlistEntry, error)
+	Remove(flightID string, joinedAt string) error
+}
+
+type Enqueuer interface {
+	SendMsg(msg interface{}, queue string) error
+	SendDelayedMsg(msg interface{}, queue string, delay time.Duration) error
+}
+
+type processor struct {
+	flightsRepo        FlightsRepository
+	waitlistRepo       WaitlistRepository
+	enqueuer           Enqueuer
+	notificationsQueue string
+	waitlistQueue      string
+	now                func() time.Time
+}
+
+func Adapter(
+	flightsRepo FlightsRepository,
+	waitlistRepo WaitlistRepository,
+	enqueuer Enqueuer,
+	notificationsQueue string,
+	waitlistQueue string,
+	now func() time.Time,
+) Handler {
+	p := processor{
+		flightsRepo:        flightsRepo,
+		waitlistRepo:       waitlistRepo,
+		enqueuer:           enqueuer,
+		notificationsQueue: notificationsQueue,
+		waitlistQueue:      waitlistQueue,
+		now:                now,
+	}
+	return func(ctx context.Context, event events.SQSEvent) error {
+		for _, record := range event.Records {
+			msg := model.QueueMsg{}
+			err := json.Unmarshal([]byte(record.Body), &msg)
+			if err != nil {
+				return err
+			}
+
+			switch msg.Type {
+			case model.QueueMsgTypeSeatReleased:
+				released := model.QueueMsgSeatReleased{}
+				err = json.Unmarshal([]byte(record.Body), &released)
+				if err == nil {
+					err = p.offerSeat(released.FlightID, released.SeatID)
+				}
+			case model.QueueMsgTypeWaitlistOfferExpired:
+				expired := model.QueueMsgWaitlistOfferExpired{}
+				err = json.Unmarshal([]byte(record.Body), &expired)
+				if err == nil {
+					err = p.expireOffer(expired)
+				}
+			case model.QueueMsgTypeSeatHoldExpired:
+				expired := model.QueueMsgSeatHoldExpired{}
+				err = json.Unmarshal([]byte(record.Body), &expired)
+				if err == nil {
+					err = p.expireHold(expired)
+				}
+			default:
+				err = fmt.Errorf("%w: %v", ErrUnknownMsgType, msg.Type)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// offerSeat holds the seat for the next waitlisted passenger and lets them
+// know, the offer is checked again once it expires
+func (p processor) offerSeat(flightID string, seatID string) error {
+	for {
+		entry, err := p.waitlistRepo.NextWaiting(flightID)
+		if err == repository.ErrWaitlistEmpty {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		flight, err := p.flightsRepo.Find(flightID)
+		if err != nil {
+			return err
+		}
+
+		// Somebody else could have taken the seat meanwhile
+		until := p.now().Add(offerDuration)
+		err = p.flightsRepo.HoldSeat(model.Reservation{
+			FlightID:    flightID,
+			SeatID:      seatID,
+			PassengerID: entry.PassengerID,
+		}, until)
+		if err == repository.ErrSeatNotAvailable || err == repository.ErrNoSeatFoundInFlight || err == repository.ErrFlightClosed {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Another release could have offered a seat to the same passenger
+		offered, err := p.waitlistRepo.Offer(entry, seatID, until)
+		if err == repository.ErrWaitlistEntryChanged {
+			err = p.flightsRepo.ReleaseSeat(flightID, seatID, entry.PassengerID)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		err = p.enqueuer.SendDelayedMsg(
+			model.QueueMsgWaitlistOfferExpired{
+				Type:        model.QueueMsgTypeWaitlistOfferExpired,
+				FlightID:    flightID,
+				JoinedAt:    offered.JoinedAt,
+				SeatID:      seatID,
+				PassengerID: offered.PassengerID,
+			},
+			p.waitlistQueue,
+			offerDuration,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = p.enqueuer.SendMsg(
+			model.QueueMsgWaitlistOffer{
+				Type:              model.QueueMsgTypeWaitlistOffer,
+				FlightID:          flightID,
+				FlightDeparture:   flight.Departure,
+				FlightOrigin:      flight.Origin,
+				FlightDestination: flight.Destination,
+				SeatID:            seatID,
+				UserID:            offered.PassengerID,
+				ExpiresAt:         offered.OfferExpiresAt,
+			},
+			p.notificationsQueue,
+		)
+		if err != nil {
+			log.Printf("An error ocurred while sending message to queue %v: %v", p.notificationsQueue, err)
+		}
+		return nil
+	}
+}
+
+// expireOffer releases the seat if the passenger did not reserve it and offers
+// it to the next one
+func (p processor) expireOffer(msg model.QueueMsgWaitlistOfferExpired) error {
+	entry, err := p.waitlistRepo.Find(msg.FlightID, msg.JoinedAt)
+	if err == repository.ErrWaitlistEntryNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if entry.Status != model.WaitlistStatusOffered || entry.OfferedSeatID != msg.SeatID {
+		return nil
+	}
+
+	// The seat is no longer held when the passenger reserved it, and the hold
+	// is extended while the passenger pays for it
+	err = p.flightsRepo.ReleaseExpiredSeat(msg.FlightID, msg.SeatID, entry.PassengerID, p.now())
+	if err == repository.ErrSeatNotHeld {
+		return p.waitlistRepo.Remove(entry.FlightID, entry.JoinedAt)
+	}
+	if err == repository.ErrSeatHoldActive {
+		return p.enqueuer.SendDelayedMsg(msg, p.waitlistQueue, offerDuration)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = p.waitlistRepo.Remove(entry.FlightID, entry.JoinedAt)
+	if err != nil {
+		return err
+	}
+	return p.offerSeat(msg.FlightID, msg.SeatID)
+}
+
+// expireHold frees a seat whose payment hold ran out and tells the waitlist
+// the seat is free again
+func (p processor) expireHold(msg model.QueueMsgSeatHoldExpired) error {
+	err := p.flightsRepo.ReleaseExpiredSeat(msg.FlightID, msg.SeatID, msg.PassengerID, p.now())
+	if err == repository.ErrSeatNotHeld || err == repository.ErrNoSeatFoundInFlight || err == repository.ErrNoFlightsFound {
+		return nil
+	}
+	if err == repository.ErrSeatHoldActive {
+		return p.enqueuer.SendDelayedMsg(msg, p.waitlistQueue, offerDuration)
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.enqueuer.SendMsg(
+		model.QueueMsgSeatReleased{
+			Type:     model.QueueMsgTypeSeatReleased,
+			FlightID: msg.FlightID,
+			SeatID:   msg.SeatID,
+		},
+		p.waitlistQueue,
+	)
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	waitlistTable := os.Getenv("DYNAMODB_WAITLIST")
+	if internal.TrimLines(waitlistTable) == "" {
+		panic("DYNAMODB_WAITLIST is empty")
+	}
+	notificationsQueue := os.Getenv("NOTIFICATIONS_QUEUE")
+	if internal.TrimLines(notificationsQueue) == "" {
+		panic("NOTIFICATIONS_QUEUE is empty")
+	}
+	waitlistQueue := os.Getenv("WAITLIST_QUEUE")
+	if internal.TrimLines(waitlistQueue) == "" {
+		panic("WAITLIST_QUEUE is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	waitlistRepo := repository.NewWaitlistRepository(dynamodbClient, waitlistTable)
+	sqsClient := sqs.New(session)
+	enqueuer := internal.NewEnqueuer(sqsClient)
+	lambda.Start(Adapter(flightsRepo, waitlistRepo, enqueuer, notificationsQueue, waitlistQueue, time.Now))
+}
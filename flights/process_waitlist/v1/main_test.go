@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) HoldSeat(reservation model.Reservation, until time.Time) error {
+	ret := m.Called(reservation, until)
+	return ret.Error(0)
+}
+
+func (m *FlightsRepositoryMock) ReleaseSeat(flightID string, seatID string, passengerID string) error {
+	ret := m.Called(flightID, seatID, passengerID)
+	return ret.Error(0)
+}
+
+func (m *FlightsRepositoryMock) ReleaseExpiredSeat(flightID string, seatID string, passengerID string, now time.Time) error {
+	ret := m.Called(flightID, seatID, passengerID, now)
+	return ret.Error(0)
+}
+
+type WaitlistRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *WaitlistRepositoryMock) NextWaiting(flightID string) (model.WaitlistEntry, error) {
+	ret := m.Called(flightID)
+	return ret.Get(0).(model.WaitlistEntry), ret.Error(1)
+}
+
+func (m *WaitlistRepositoryMock) Find(flightID string, joinedAt string) (model.WaitlistEntry, error) {
+	ret := m.Called(flightID, joinedAt)
+	return ret.Get(0).(model.WaitlistEntry), ret.Error(1)
+}
+
+func (m *WaitlistRepositoryMock) Offer(entry model.WaitlistEntry, seatID string, until time.Time) (model.WaitlistEntry, error) {
+	ret := m.Called(entry, seatID, until)
+	return ret.Get(0).(model.WaitlistEntry), ret.Error(1)
+}
+
+func (m *WaitlistRepositoryMock) Remove(flightID string, joinedAt string) error {
+	ret := m.Called(flightID, joinedAt)
+	return ret.Error(0)
+}
+
+type EnqueuerMock struct {
+	mock.Mock
+}
+
+func (m *EnqueuerMock) SendMsg(msg interface{}, queue string) error {
+	ret := m.Called(msg, queue)
+	return ret.Error(0)
+}
+
+func (m *EnqueuerMock) SendDelayedMsg(msg interface{}, queue string, delay time.Duration) error {
+	ret := m.Called(msg, queue, delay)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo  *FlightsRepositoryMock
+		waitlistRepo *WaitlistRepositoryMock
+		enqueuer     *EnqueuerMock
+	}
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(offerDuration)
+	flight := model.Flight{
+		ID:        "f1",
+		Departure: "2020-05-02T00:00:00+0000",
+	}
+	first := model.WaitlistEntry{
+		FlightID:    "f1",
+		JoinedAt:    "2020-04-30T10:00:00.000000000Z",
+		PassengerID: "p1",
+		Status:      model.WaitlistStatusWaiting,
+	}
+	firstOffered := first
+	firstOffered.Status = model.WaitlistStatusOffered
+	firstOffered.OfferedSeatID = "1A"
+	firstOffered.OfferExpiresAt = "2020-05-01T12:15:00Z"
+	second := model.WaitlistEntry{
+		FlightID:    "f1",
+		JoinedAt:    "2020-04-30T11:00:00.000000000Z",
+		PassengerID: "p2",
+		Status:      model.WaitlistStatusWaiting,
+	}
+	secondOffered := second
+	secondOffered.Status = model.WaitlistStatusOffered
+	secondOffered.OfferedSeatID = "1A"
+	secondOffered.OfferExpiresAt = "2020-05-01T12:15:00Z"
+
+	expectOffer := func(m mocks, entry model.WaitlistEntry, offered model.WaitlistEntry) {
+		m.waitlistRepo.On("NextWaiting", "f1").Return(entry, nil).Once()
+		m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+		m.flightsRepo.On("HoldSeat", model.Reservation{FlightID: "f1", SeatID: "1A", PassengerID: entry.PassengerID}, until).Return(nil).Once()
+		m.waitlistRepo.On("Offer", entry, "1A", until).Return(offered, nil).Once()
+		m.enqueuer.On(
+			"SendDelayedMsg",
+			model.QueueMsgWaitlistOfferExpired{
+				Type:        model.QueueMsgTypeWaitlistOfferExpired,
+				FlightID:    "f1",
+				JoinedAt:    entry.JoinedAt,
+				SeatID:      "1A",
+				PassengerID: entry.PassengerID,
+			},
+			"waitlist",
+			offerDuration,
+		).Return(nil).Once()
+		m.enqueuer.On(
+			"SendMsg",
+			model.QueueMsgWaitlistOffer{
+				Type:            model.QueueMsgTypeWaitlistOffer,
+				FlightID:        "f1",
+				FlightDeparture: "2020-05-02T00:00:00+0000",
+				SeatID:          "1A",
+				UserID:          entry.PassengerID,
+				ExpiresAt:       "2020-05-01T12:15:00Z",
+			},
+			"notifications",
+		).Return(nil).Once()
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+		mocker  func(m mocks)
+	}{
+		{
+			name: "Offer a released seat to the first waitlisted passenger",
+			body: `{"type":"seat_released","flight_id":"f1","seat_id":"1A"}`,
+			mocker: func(m mocks) {
+				expectOffer(m, first, firstOffered)
+			},
+		},
+		{
+			name: "Do nothing when nobody is waiting",
+			body: `{"type":"seat_released","flight_id":"f1","seat_id":"1A"}`,
+			mocker: func(m mocks) {
+				m.waitlistRepo.On("NextWaiting", "f1").Return(model.WaitlistEntry{}, repository.ErrWaitlistEmpty).Once()
+			},
+		},
+		{
+			name: "Do nothing when the seat was taken before it could be offered",
+			body: `{"type":"seat_released","flight_id":"f1","seat_id":"1A"}`,
+			mocker: func(m mocks) {
+				m.waitlistRepo.On("NextWaiting", "f1").Return(first, nil).Once()
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				m.flightsRepo.On("HoldSeat", model.Reservation{FlightID: "f1", SeatID: "1A", PassengerID: "p1"}, until).Return(repository.ErrSeatNotAvailable).Once()
+			},
+		},
+		{
+			name: "Move on to the next passenger when the offer expires",
+			body: `{"type":"waitlist_offer_expired","flight_id":"f1","joined_at":"2020-04-30T10:00:00.000000000Z","seat_id":"1A","passenger_id":"p1"}`,
+			mocker: func(m mocks) {
+				m.waitlistRepo.On("Find", "f1", first.JoinedAt).Return(firstOffered, nil).Once()
+				m.flightsRepo.On("ReleaseExpiredSeat", "f1", "1A", "p1", now).Return(nil).Once()
+				m.waitlistRepo.On("Remove", "f1", first.JoinedAt).Return(nil).Once()
+				expectOffer(m, second, secondOffered)
+			},
+		},
+		{
+			name: "Remove the passenger from the waitlist once the offered seat is reserved",
+			body: `{"type":"waitlist_offer_expired","flight_id":"f1","joined_at":"2020-04-30T10:00:00.000000000Z","seat_id":"1A","passenger_id":"p1"}`,
+			mocker: func(m mocks) {
+				m.waitlistRepo.On("Find", "f1", first.JoinedAt).Return(firstOffered, nil).Once()
+				m.flightsRepo.On("ReleaseExpiredSeat", "f1", "1A", "p1", now).Return(repository.ErrSeatNotHeld).Once()
+				m.waitlistRepo.On("Remove", "f1", first.JoinedAt).Return(nil).Once()
+			},
+		},
+		{
+			name: "Check the offer again while the passenger pays for the seat",
+			body: `{"type":"waitlist_offer_expired","flight_id":"f1","joined_at":"2020-04-30T10:00:00.000000000Z","seat_id":"1A","passenger_id":"p1"}`,
+			mocker: func(m mocks) {
+				m.waitlistRepo.On("Find", "f1", first.JoinedAt).Return(firstOffered, nil).Once()
+				m.flightsRepo.On("ReleaseExpiredSeat", "f1", "1A", "p1", now).Return(repository.ErrSeatHoldActive).Once()
+				m.enqueuer.On(
+					"SendDelayedMsg",
+					model.QueueMsgWaitlistOfferExpired{
+						Type:        model.QueueMsgTypeWaitlistOfferExpired,
+						FlightID:    "f1",
+						JoinedAt:    first.JoinedAt,
+						SeatID:      "1A",
+						PassengerID: "p1",
+					},
+					"waitlist",
+					offerDuration,
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Release a seat whose payment hold expired",
+			body: `{"type":"seat_hold_expired","flight_id":"f1","seat_id":"1A","passenger_id":"p1"}`,
+			mocker: func(m mocks) {
+				m.flightsRepo.On("ReleaseExpiredSeat", "f1", "1A", "p1", now).Return(nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgSeatReleased{
+						Type:     model.QueueMsgTypeSeatReleased,
+						FlightID: "f1",
+						SeatID:   "1A",
+					},
+					"waitlist",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Do nothing when the held seat was reserved",
+			body: `{"type":"seat_hold_expired","flight_id":"f1","seat_id":"1A","passenger_id":"p1"}`,
+			mocker: func(m mocks) {
+				m.flightsRepo.On("ReleaseExpiredSeat", "f1", "1A", "p1", now).Return(repository.ErrSeatNotHeld).Once()
+			},
+		},
+		{
+			name: "Check the payment hold again when it was extended",
+			body: `{"type":"seat_hold_expired","flight_id":"f1","seat_id":"1A","passenger_id":"p1"}`,
+			mocker: func(m mocks) {
+				m.flightsRepo.On("ReleaseExpiredSeat", "f1", "1A", "p1", now).Return(repository.ErrSeatHoldActive).Once()
+				m.enqueuer.On(
+					"SendDelayedMsg",
+					model.QueueMsgSeatHoldExpired{
+						Type:        model.QueueMsgTypeSeatHoldExpired,
+						FlightID:    "f1",
+						SeatID:      "1A",
+						PassengerID: "p1",
+					},
+					"waitlist",
+					offerDuration,
+				).Return(nil).Once()
+			},
+		},
+		{
+			name:    "Fail on an unknown message type",
+			body:    `{"type":"something_else"}`,
+			wantErr: ErrUnknownMsgType,
+			mocker:  func(m mocks) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m := mocks{
+				flightsRepo:  &FlightsRepositoryMock{},
+				waitlistRepo: &WaitlistRepositoryMock{},
+				enqueuer:     &EnqueuerMock{},
+			}
+			tt.mocker(m)
+
+			// Act
+			handler := Adapter(m.flightsRepo, m.waitlistRepo, m.enqueuer, "notifications", "waitlist", func() time.Time { return now })
+			err := handler(context.Background(), events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: tt.body,
+					},
+				},
+			})
+
+			// Assert
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			} else {
+				require.NoError(t, err)
+			}
+			m.flightsRepo.AssertExpectations(t)
+			m.waitlistRepo.AssertExpectations(t)
+			m.enqueuer.AssertExpectations(t)
+		})
+	}
+
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/pricing"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+}
+
+type Response struct {
+	FlightID      string `json:"flight_id"`
+	SeatID        string `json:"seat_id"`
+	Cabin         string `json:"cabin"`
+	BaseFare      int64  `json:"base_fare"`
+	SeatSurcharge int64  `json:"seat_surcharge"`
+	Total         int64  `json:"total"`
+	Currency      string `json:"currency"`
+}
+
+func Adapter(flightsRepo FlightsRepository) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		flightID := req.PathParameters["flightID"]
+		seatID := req.PathParameters["seatID"]
+
+		// Find the flight and the seat
+		flight, err := flightsRepo.Find(flightID)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		seat := model.FlightSeat{}
+		for _, s := range flight.Seats {
+			if s.ID == seatID {
+				seat = s
+				break
+			}
+		}
+		if seat.ID == "" {
+			return internal.Error(http.StatusNotFound, repository.ErrNoSeatFoundInFlight), nil
+		}
+
+		// Price the seat
+		quote, err := pricing.QuoteSeat(flight.Fares, seat)
+		if err == pricing.ErrNoFareForCabin {
+			return internal.Error(http.StatusUnprocessableEntity, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Respond
+		responseBytes, _ := json.Marshal(Response{
+			FlightID:      flight.ID,
+			SeatID:        seat.ID,
+			Cabin:         quote.Cabin,
+			BaseFare:      quote.BaseFare,
+			SeatSurcharge: quote.SeatSurcharge,
+			Total:         quote.Total,
+			Currency:      quote.Currency,
+		})
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	lambda.Start(Adapter(flightsRepo))
+}
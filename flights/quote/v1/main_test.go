@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/pricing"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+	}
+
+	flight := model.Flight{
+		ID:        "f1",
+		Departure: "2020-05-01T00:00:00+0000",
+		Fares: model.Fares{
+			Currency: "USD",
+			Cabins: map[string]int64{
+				model.CabinEconomy: 10000,
+			},
+			Surcharges: map[string]int64{
+				model.SeatPositionAisle: 1000,
+				model.SurchargeExitRow:  2000,
+			},
+		},
+		Seats: []model.FlightSeat{
+			{
+				ID:       "11C",
+				Letter:   "C",
+				Row:      11,
+				Position: model.SeatPositionAisle,
+				Cabin:    model.CabinEconomy,
+				ExitRow:  true,
+			},
+			{
+				ID:     "1A",
+				Letter: "A",
+				Row:    1,
+				Cabin:  model.CabinBusiness,
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code with the price of the seat",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f1",
+					"seatID":   "11C",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"flight_id":"f1",
+					"seat_id":"11C",
+					"cabin":"economy",
+					"base_fare":10000,
+					"seat_surcharge":3000,
+					"total":13000,
+					"currency":"USD"
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because the seat is not in the flight",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f1",
+					"seatID":   "99Z",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoSeatFoundInFlight),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the cabin of the seat has no fare",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f1",
+					"seatID":   "1A",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, pricing.ErrNoFareForCabin),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because the flight was not found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"flightID": "f2",
+					"seatID":   "1A",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f2").Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+		})
+	}
+
+}
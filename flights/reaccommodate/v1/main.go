@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/seatmap"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+var (
+	ErrFlightNotCancelled = errors.New("flight_not_cancelled")
+	ErrFlightWithoutRoute = errors.New("flight_without_route")
+	ErrNoSeatsAvailable   = errors.New("no_seats_available")
+)
+
+// searchWindow is how long after the cancelled departure passengers can be
+// moved to
+const searchWindow = 48 * time.Hour
+
+// cabinRanks gives the passengers of the premium cabins the first pick
+var cabinRanks = map[string]int{
+	model.CabinFirst:          0,
+	model.CabinBusiness:       1,
+	model.CabinPremiumEconomy: 2,
+	model.CabinEconomy:        3,
+}
+
+type Handler func(ctx context.Context, req Request) (Report, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	ListFlightsByRoute(origin string, destination string, dateFrom string, dateTo string) ([]model.Flight, error)
+	MoveReservation(fromFlightID string, fromSeatID string, toFlightID string, toSeatID string, passengerID string) (model.Reservation, error)
+}
+
+type Enqueuer interface {
+	SendMsg(msg interface{}, queue string) error
+}
+
+type Request struct {
+	FlightID string `json:"flight_id"`
+}
+
+type Report struct {
+	FlightID string     `json:"flight_id"`
+	Moved    []Moved    `json:"moved"`
+	NotMoved []NotMoved `json:"not_moved"`
+}
+
+type Moved struct {
+	PassengerID  string `json:"passenger_id"`
+	Locator      string `json:"locator"`
+	FromSeatID   string `json:"from_seat_id"`
+	FlightID     string `json:"flight_id"`
+	SeatID       string `json:"seat_id"`
+	CabinChanged bool   `json:"cabin_changed"`
+}
+
+type NotMoved struct {
+	PassengerID string `json:"passenger_id"`
+	Locator     string `json:"locator"`
+	SeatID      string `json:"seat_id"`
+	Reason      string `json:"reason"`
+}
+
+// Adapter moves the passengers of a cancelled flight to the next flights of the
+// same route. Passengers seated next to each other are kept together and in
+// their cabin when possible, the ones that can not be moved are reported. It
+// can be run again, moved passengers are no longer in the cancelled flight
+func Adapter(flightsRepo FlightsRepository, enqueuer Enqueuer, notificationsQueue string) Handler {
+	return func(ctx context.Context, req Request) (Report, error) {
+		// Find the cancelled flight
+		cancelled, err := flightsRepo.Find(req.FlightID)
+		if err != nil {
+			return Report{}, err
+		}
+		if cancelled.Status != model.FlightStatusCancelled {
+			return Report{}, ErrFlightNotCancelled
+		}
+		if cancelled.Origin == "" || cancelled.Destination == "" {
+			return Report{}, ErrFlightWithoutRoute
+		}
+
+		// Find the flights of the same route that can take passengers
+		departure, err := model.ParseDeparture(cancelled.Departure)
+		if err != nil {
+			return Report{}, err
+		}
+		found, err := flightsRepo.ListFlightsByRoute(
+			cancelled.Origin,
+			cancelled.Destination,
+			model.FormatDeparture(departure),
+			model.FormatDeparture(departure.Add(searchWindow)),
+		)
+		if err != nil && err != repository.ErrNoFlightsFound {
+			return Report{}, err
+		}
+		flights := []model.Flight{}
+		for _, f := range found {
+			if f.ID != cancelled.ID && f.IsBookable() && f.HasFreeSeats {
+				// Seats are marked as taken while passengers are placed
+				seats := make([]model.FlightSeat, len(f.Seats))
+				copy(seats, f.Seats)
+				f.Seats = seats
+				flights = append(flights, f)
+			}
+		}
+
+		groups := seatmap.Groups(cancelled.Seats)
+		sort.SliceStable(groups, func(i, j int) bool {
+			return cabinRanks[seatmap.Cabin(groups[i][0])] < cabinRanks[seatmap.Cabin(groups[j][0])]
+		})
+
+		report := Report{
+			FlightID: cancelled.ID,
+			Moved:    []Moved{},
+			NotMoved: []NotMoved{},
+		}
+		for _, group := range groups {
+			// Split the group when it does not fit together
+			placements := [][]model.FlightSeat{group}
+			flightIndex, seats := place(flights, group)
+			if flightIndex == -1 {
+				placements = [][]model.FlightSeat{}
+				for _, s := range group {
+					placements = append(placements, []model.FlightSeat{s})
+				}
+			}
+
+			for _, passengers := range placements {
+				if len(placements) > 1 {
+					flightIndex, seats = place(flights, passengers)
+				}
+				for i, passenger := range passengers {
+					if flightIndex == -1 {
+						report.NotMoved = append(report.NotMoved, notMoved(passenger, ErrNoSeatsAvailable))
+						continue
+					}
+
+					flight := &flights[flightIndex]
+					moved, err := flightsRepo.MoveReservation(cancelled.ID, passenger.ID, flight.ID, seats[i].ID, passenger.PassengerID)
+					if err == repository.ErrSeatNotAvailable {
+						takeSeat(flight, seats[i].ID, "-")
+					}
+					if err == repository.ErrSeatNotAvailable ||
+						err == repository.ErrFlightClosed ||
+						err == repository.ErrPassengerNotInSeat {
+						report.NotMoved = append(report.NotMoved, notMoved(passenger, err))
+						continue
+					}
+					if err != nil {
+						return Report{}, err
+					}
+					takeSeat(flight, seats[i].ID, passenger.PassengerID)
+
+					report.Moved = append(report.Moved, Moved{
+						PassengerID:  moved.PassengerID,
+						Locator:      moved.Locator,
+						FromSeatID:   passenger.ID,
+						FlightID:     moved.FlightID,
+						SeatID:       moved.SeatID,
+						CabinChanged: seatmap.Cabin(seats[i]) != seatmap.Cabin(passenger),
+					})
+					err = enqueuer.SendMsg(
+						model.QueueMsgReaccommodated{
+							Type:              model.QueueMsgTypeReaccommodated,
+							Locator:           moved.Locator,
+							FromFlightID:      cancelled.ID,
+							FlightID:          moved.FlightID,
+							FlightDeparture:   moved.Departure,
+							FlightOrigin:      cancelled.Origin,
+							FlightDestination: cancelled.Destination,
+							SeatID:            moved.SeatID,
+							UserID:            moved.PassengerID,
+						},
+						notificationsQueue,
+					)
+					if err != nil {
+						log.Printf("An error ocurred while sending message to queue %v: %v", notificationsQueue, err)
+					}
+				}
+			}
+		}
+
+		return report, nil
+	}
+}
+
+// place finds seats for the passengers in one flight, first together in their
+// cabin, then apart in their cabin and then in any cabin. Exit rows are only
+// given to passengers that were already in one
+func place(flights []model.Flight, passengers []model.FlightSeat) (int, []model.FlightSeat) {
+	cabin := seatmap.Cabin(passengers[0])
+	exitRow := true
+	for _, p := range passengers {
+		exitRow = exitRow && p.ExitRow
+	}
+
+	attempts := []struct {
+		sameCabin bool
+		together  bool
+	}{
+		{true, true},
+		{true, false},
+		{false, true},
+		{false, false},
+	}
+	for _, a := range attempts {
+		fits := func(seat model.FlightSeat) bool {
+			return (!seat.ExitRow || exitRow) && (!a.sameCabin || seatmap.Cabin(seat) == cabin)
+		}
+		find := seatmap.FindSeats
+		if a.together {
+			find = seatmap.FindBlock
+		}
+		for i, f := range flights {
+			seats := find(f.Seats, len(passengers), fits)
+			if seats != nil {
+				return i, seats
+			}
+		}
+	}
+	return -1, nil
+}
+
+// takeSeat marks the seat as taken so it is not given twice
+func takeSeat(flight *model.Flight, seatID string, passengerID string) {
+	for i, s := range flight.Seats {
+		if s.ID == seatID {
+			flight.Seats[i].PassengerID = passengerID
+		}
+	}
+}
+
+func notMoved(passenger model.FlightSeat, reason error) NotMoved {
+	return NotMoved{
+		PassengerID: passenger.PassengerID,
+		Locator:     passenger.Locator,
+		SeatID:      passenger.ID,
+		Reason:      reason.Error(),
+	}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	notificationsQueue := os.Getenv("NOTIFICATIONS_QUEUE")
+	if internal.TrimLines(notificationsQueue) == "" {
+		panic("NOTIFICATIONS_QUEUE is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	sqsClient := sqs.New(session)
+	enqueuer := internal.NewEnqueuer(sqsClient)
+	lambda.Start(Adapter(flightsRepo, enqueuer, notificationsQueue))
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) ListFlightsByRoute(origin string, destination string, dateFrom string, dateTo string) ([]model.Flight, error) {
+	ret := m.Called(origin, destination, dateFrom, dateTo)
+	return ret.Get(0).([]model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) MoveReservation(fromFlightID string, fromSeatID string, toFlightID string, toSeatID string, passengerID string) (model.Reservation, error) {
+	ret := m.Called(fromFlightID, fromSeatID, toFlightID, toSeatID, passengerID)
+	return ret.Get(0).(model.Reservation), ret.Error(1)
+}
+
+type EnqueuerMock struct {
+	mock.Mock
+}
+
+func (m *EnqueuerMock) SendMsg(msg interface{}, queue string) error {
+	ret := m.Called(msg, queue)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+		enqueuer    *EnqueuerMock
+	}
+
+	cancelled := model.Flight{
+		ID:          "f1",
+		Departure:   "2020-05-01T10:00:00+0000",
+		Origin:      "BOG",
+		Destination: "MDE",
+		Status:      model.FlightStatusCancelled,
+		Seats: []model.FlightSeat{
+			{ID: "1A", Letter: "A", Row: 1, Position: model.SeatPositionWindow, Cabin: model.CabinEconomy, PassengerID: "p1", Locator: "AAAAAA"},
+			{ID: "1B", Letter: "B", Row: 1, Position: model.SeatPositionMiddle, Cabin: model.CabinEconomy, PassengerID: "p2", Locator: "BBBBBB"},
+			{ID: "1C", Letter: "C", Row: 1, Position: model.SeatPositionAisle, Cabin: model.CabinEconomy},
+			{ID: "1D", Letter: "D", Row: 1, Position: model.SeatPositionAisle, Cabin: model.CabinEconomy, PassengerID: "p4", Locator: "DDDDDD"},
+			{ID: "2A", Letter: "A", Row: 2, Position: model.SeatPositionWindow, Cabin: model.CabinBusiness, PassengerID: "p3", Locator: "CCCCCC"},
+		},
+	}
+	next := model.Flight{
+		ID:           "f2",
+		Departure:    "2020-05-01T16:00:00+0000",
+		Origin:       "BOG",
+		Destination:  "MDE",
+		HasFreeSeats: true,
+		Seats: []model.FlightSeat{
+			{ID: "5A", Letter: "A", Row: 5, Position: model.SeatPositionWindow, Cabin: model.CabinEconomy},
+			{ID: "5B", Letter: "B", Row: 5, Position: model.SeatPositionMiddle, Cabin: model.CabinEconomy},
+			{ID: "5C", Letter: "C", Row: 5, Position: model.SeatPositionAisle, Cabin: model.CabinEconomy},
+			{ID: "6A", Letter: "A", Row: 6, Position: model.SeatPositionWindow, Cabin: model.CabinEconomy, ExitRow: true},
+			{ID: "6B", Letter: "B", Row: 6, Position: model.SeatPositionMiddle, Cabin: model.CabinEconomy, PassengerID: "p9"},
+		},
+	}
+	full := model.Flight{
+		ID:          "f3",
+		Departure:   "2020-05-01T12:00:00+0000",
+		Origin:      "BOG",
+		Destination: "MDE",
+		Seats: []model.FlightSeat{
+			{ID: "1A", Letter: "A", Row: 1, PassengerID: "p8"},
+		},
+	}
+
+	// Wide-body business cabins are A-DG-K, only D and G are next to each other
+	wideBody := func(id string, departure string, status string, passengers map[string]string) model.Flight {
+		flight := model.Flight{
+			ID:          id,
+			Departure:   departure,
+			Origin:      "BOG",
+			Destination: "MDE",
+			Status:      status,
+			Seats: aircraft.Configuration{
+				Sections: []aircraft.Section{
+					{Cabin: model.CabinBusiness, Layout: "A-DG-K", FirstRow: 1, LastRow: 2},
+				},
+			}.Seats(),
+		}
+		for i, seat := range flight.Seats {
+			flight.Seats[i].PassengerID = passengers[seat.ID]
+			if passengers[seat.ID] != "" {
+				flight.Seats[i].Locator = "EEEEEE"
+			}
+		}
+		return flight
+	}
+	cancelledWideBody := wideBody("f1", "2020-05-01T10:00:00+0000", model.FlightStatusCancelled,
+		map[string]string{"1D": "p5", "1G": "p6"})
+	nextWideBody := wideBody("f2", "2020-05-01T16:00:00+0000", "", map[string]string{"1G": "p9"})
+	nextWideBody.HasFreeSeats = true
+
+	expectMove := func(m mocks, fromSeatID string, toSeatID string, passengerID string, locator string, err error) {
+		reservation := model.Reservation{
+			Locator:     locator,
+			FlightID:    "f2",
+			SeatID:      toSeatID,
+			PassengerID: passengerID,
+			Departure:   "2020-05-01T16:00:00+0000",
+		}
+		if err != nil {
+			reservation = model.Reservation{}
+		}
+		m.flightsRepo.On("MoveReservation", "f1", fromSeatID, "f2", toSeatID, passengerID).Return(reservation, err).Once()
+		if err != nil {
+			return
+		}
+		m.enqueuer.On(
+			"SendMsg",
+			model.QueueMsgReaccommodated{
+				Type:              model.QueueMsgTypeReaccommodated,
+				Locator:           locator,
+				FromFlightID:      "f1",
+				FlightID:          "f2",
+				FlightDeparture:   "2020-05-01T16:00:00+0000",
+				FlightOrigin:      "BOG",
+				FlightDestination: "MDE",
+				SeatID:            toSeatID,
+				UserID:            passengerID,
+			},
+			"queue",
+		).Return(nil).Once()
+	}
+
+	tests := []struct {
+		name    string
+		req     Request
+		want    Report
+		wantErr error
+		mocker  func(m mocks)
+	}{
+		{
+			name: "Move the passengers keeping groups together and report who could not be moved",
+			req:  Request{FlightID: "f1"},
+			want: Report{
+				FlightID: "f1",
+				Moved: []Moved{
+					{PassengerID: "p3", Locator: "CCCCCC", FromSeatID: "2A", FlightID: "f2", SeatID: "5A", CabinChanged: true},
+					{PassengerID: "p1", Locator: "AAAAAA", FromSeatID: "1A", FlightID: "f2", SeatID: "5B"},
+					{PassengerID: "p2", Locator: "BBBBBB", FromSeatID: "1B", FlightID: "f2", SeatID: "5C"},
+				},
+				NotMoved: []NotMoved{
+					{PassengerID: "p4", Locator: "DDDDDD", SeatID: "1D", Reason: ErrNoSeatsAvailable.Error()},
+				},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(cancelled, nil).Once()
+				m.flightsRepo.On("ListFlightsByRoute", "BOG", "MDE", "2020-05-01T10:00:00Z", "2020-05-03T10:00:00Z").Return(
+					[]model.Flight{cancelled, full, next},
+					nil,
+				).Once()
+				expectMove(m, "2A", "5A", "p3", "CCCCCC", nil)
+				expectMove(m, "1A", "5B", "p1", "AAAAAA", nil)
+				expectMove(m, "1B", "5C", "p2", "BBBBBB", nil)
+			},
+		},
+		{
+			name: "Report the passengers whose new seat was taken meanwhile",
+			req:  Request{FlightID: "f1"},
+			want: Report{
+				FlightID: "f1",
+				Moved: []Moved{
+					{PassengerID: "p3", Locator: "CCCCCC", FromSeatID: "2A", FlightID: "f2", SeatID: "5A", CabinChanged: true},
+					{PassengerID: "p1", Locator: "AAAAAA", FromSeatID: "1A", FlightID: "f2", SeatID: "5B"},
+				},
+				NotMoved: []NotMoved{
+					{PassengerID: "p2", Locator: "BBBBBB", SeatID: "1B", Reason: repository.ErrSeatNotAvailable.Error()},
+					{PassengerID: "p4", Locator: "DDDDDD", SeatID: "1D", Reason: ErrNoSeatsAvailable.Error()},
+				},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(cancelled, nil).Once()
+				m.flightsRepo.On("ListFlightsByRoute", "BOG", "MDE", "2020-05-01T10:00:00Z", "2020-05-03T10:00:00Z").Return(
+					[]model.Flight{next},
+					nil,
+				).Once()
+				expectMove(m, "2A", "5A", "p3", "CCCCCC", nil)
+				expectMove(m, "1A", "5B", "p1", "AAAAAA", nil)
+				expectMove(m, "1B", "5C", "p2", "BBBBBB", repository.ErrSeatNotAvailable)
+			},
+		},
+		{
+			name: "Report every passenger when there are no other flights",
+			req:  Request{FlightID: "f1"},
+			want: Report{
+				FlightID: "f1",
+				Moved:    []Moved{},
+				NotMoved: []NotMoved{
+					{PassengerID: "p3", Locator: "CCCCCC", SeatID: "2A", Reason: ErrNoSeatsAvailable.Error()},
+					{PassengerID: "p1", Locator: "AAAAAA", SeatID: "1A", Reason: ErrNoSeatsAvailable.Error()},
+					{PassengerID: "p2", Locator: "BBBBBB", SeatID: "1B", Reason: ErrNoSeatsAvailable.Error()},
+					{PassengerID: "p4", Locator: "DDDDDD", SeatID: "1D", Reason: ErrNoSeatsAvailable.Error()},
+				},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(cancelled, nil).Once()
+				m.flightsRepo.On("ListFlightsByRoute", "BOG", "MDE", "2020-05-01T10:00:00Z", "2020-05-03T10:00:00Z").Return(
+					[]model.Flight{},
+					repository.ErrNoFlightsFound,
+				).Once()
+			},
+		},
+		{
+			name: "Keep a couple together in the middle seats of a wide-body, never across an aisle",
+			req:  Request{FlightID: "f1"},
+			want: Report{
+				FlightID: "f1",
+				Moved: []Moved{
+					{PassengerID: "p5", Locator: "EEEEEE", FromSeatID: "1D", FlightID: "f2", SeatID: "2D"},
+					{PassengerID: "p6", Locator: "EEEEEE", FromSeatID: "1G", FlightID: "f2", SeatID: "2G"},
+				},
+				NotMoved: []NotMoved{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(cancelledWideBody, nil).Once()
+				m.flightsRepo.On("ListFlightsByRoute", "BOG", "MDE", "2020-05-01T10:00:00Z", "2020-05-03T10:00:00Z").Return(
+					[]model.Flight{nextWideBody},
+					nil,
+				).Once()
+				expectMove(m, "1D", "2D", "p5", "EEEEEE", nil)
+				expectMove(m, "1G", "2G", "p6", "EEEEEE", nil)
+			},
+		},
+		{
+			name:    "Fail because the flight is not cancelled",
+			req:     Request{FlightID: "f2"},
+			wantErr: ErrFlightNotCancelled,
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f2").Return(next, nil).Once()
+			},
+		},
+		{
+			name:    "Fail because the flight could not be found",
+			req:     Request{FlightID: "f4"},
+			wantErr: repository.ErrNoFlightsFound,
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f4").Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m := mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			}
+			tt.mocker(m)
+
+			// Act
+			handler := Adapter(m.flightsRepo, m.enqueuer, "queue")
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			} else {
+				require.NoError(t, err)
+				if diff := cmp.Diff(tt.want, got); diff != "" {
+					t.Errorf("Differences found: (-want,+got)\n%s", diff)
+				}
+			}
+			m.flightsRepo.AssertExpectations(t)
+			m.enqueuer.AssertExpectations(t)
+		})
+	}
+
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/seatmap"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+const defaultLimit = 5
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+}
+
+type Response struct {
+	FlightID        string                   `json:"flight_id"`
+	PartySize       int                      `json:"party_size"`
+	Recommendations []ResponseRecommendation `json:"recommendations"`
+}
+
+type ResponseRecommendation struct {
+	Seats    []string `json:"seats"`
+	Together bool     `json:"together"`
+}
+
+// Params narrow the seats given to the party, exit rows are left out unless
+// the passengers confirmed they can help in an emergency
+type Params struct {
+	PartySize        int
+	Cabin            string
+	ExitRowConfirmed bool
+	Limit            int
+}
+
+func Adapter(flightsRepo FlightsRepository) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		flightID := req.PathParameters["id"]
+		params, err := getParams(req.QueryStringParameters)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Find the flight
+		flight, err := flightsRepo.Find(flightID)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+		if !flight.IsBookable() {
+			return internal.Error(http.StatusUnprocessableEntity, repository.ErrFlightClosed), nil
+		}
+
+		// Look for seats next to each other
+		recommendations := seatmap.Recommend(flight.Seats, params.PartySize, func(seat model.FlightSeat) bool {
+			if seatmap.Cabin(seat) != params.Cabin {
+				return false
+			}
+			return !seat.ExitRow || params.ExitRowConfirmed
+		}, params.Limit)
+
+		// Respond
+		response := Response{
+			FlightID:        flight.ID,
+			PartySize:       params.PartySize,
+			Recommendations: make([]ResponseRecommendation, len(recommendations)),
+		}
+		for i, r := range recommendations {
+			seats := make([]string, len(r.Seats))
+			for j, s := range r.Seats {
+				seats[j] = s.ID
+			}
+			response.Recommendations[i] = ResponseRecommendation{
+				Seats:    seats,
+				Together: !r.Split,
+			}
+		}
+		responseBytes, _ := json.Marshal(response)
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func getParams(query map[string]string) (Params, error) {
+	params := Params{
+		Cabin: query["cabin"],
+		Limit: defaultLimit,
+	}
+	if params.Cabin == "" {
+		params.Cabin = model.CabinEconomy
+	}
+
+	partySize, err := strconv.Atoi(query["party_size"])
+	if err != nil || partySize <= 0 {
+		return Params{}, errors.New("invalid party_size")
+	}
+	params.PartySize = partySize
+
+	if v, ok := query["exit_row_confirmed"]; ok {
+		exitRowConfirmed, err := strconv.ParseBool(v)
+		if err != nil {
+			return Params{}, errors.New("invalid exit_row_confirmed")
+		}
+		params.ExitRowConfirmed = exitRowConfirmed
+	}
+	if v, ok := query["limit"]; ok {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return Params{}, errors.New("invalid limit")
+		}
+		params.Limit = limit
+	}
+
+	return params, nil
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	lambda.Start(Adapter(flightsRepo))
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+	}
+
+	flight := model.Flight{
+		ID:        "f1",
+		Departure: "2020-05-01T00:00:00+0000",
+		Seats: []model.FlightSeat{
+			{ID: "1A", Row: 1, Letter: "A", Cabin: model.CabinBusiness, Position: model.SeatPositionWindow},
+			{ID: "1C", Row: 1, Letter: "C", Cabin: model.CabinBusiness, Position: model.SeatPositionAisle},
+			{ID: "10A", Row: 10, Letter: "A", Position: model.SeatPositionWindow, PassengerID: "p1"},
+			{ID: "10B", Row: 10, Letter: "B", Position: model.SeatPositionMiddle},
+			{ID: "10C", Row: 10, Letter: "C", Position: model.SeatPositionAisle},
+			{ID: "11A", Row: 11, Letter: "A", Position: model.SeatPositionWindow},
+			{ID: "11B", Row: 11, Letter: "B", Position: model.SeatPositionMiddle},
+			{ID: "11C", Row: 11, Letter: "C", Position: model.SeatPositionAisle, PassengerID: "p2"},
+			{ID: "12A", Row: 12, Letter: "A", Position: model.SeatPositionWindow, ExitRow: true},
+			{ID: "12B", Row: 12, Letter: "B", Position: model.SeatPositionMiddle, ExitRow: true},
+			{ID: "12C", Row: 12, Letter: "C", Position: model.SeatPositionAisle, ExitRow: true},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code with the seats next to each other out of the exit rows",
+			req: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"id": "f1"},
+				QueryStringParameters: map[string]string{"party_size": "2"},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"flight_id":"f1",
+					"party_size":2,
+					"recommendations":[
+						{"seats":["10B","10C"],"together":true},
+						{"seats":["11A","11B"],"together":true}
+					]
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code with exit rows when they are confirmed",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"id": "f1"},
+				QueryStringParameters: map[string]string{
+					"party_size":         "3",
+					"exit_row_confirmed": "true",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"flight_id":"f1",
+					"party_size":3,
+					"recommendations":[
+						{"seats":["12A","12B","12C"],"together":true}
+					]
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code with the party split in two rows",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"id": "f1"},
+				QueryStringParameters: map[string]string{
+					"party_size": "3",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"flight_id":"f1",
+					"party_size":3,
+					"recommendations":[
+						{"seats":["10B","10C","11B"],"together":false}
+					]
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because of an invalid party size",
+			req: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"id": "f1"},
+				QueryStringParameters: map[string]string{"party_size": "0"},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid party_size"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because of an invalid exit row confirmation",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{"id": "f1"},
+				QueryStringParameters: map[string]string{
+					"party_size":         "2",
+					"exit_row_confirmed": "maybe",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid exit_row_confirmed"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 404 status because the flight was not found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"id": "f2"},
+				QueryStringParameters: map[string]string{"party_size": "2"},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f2").Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the flight can not be booked",
+			req: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"id": "f1"},
+				QueryStringParameters: map[string]string{"party_size": "2"},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrFlightClosed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(model.Flight{ID: "f1", Status: model.FlightStatusCancelled}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 500 status because the flight could not be found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters:        map[string]string{"id": "f1"},
+				QueryStringParameters: map[string]string{"party_size": "2"},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(model.Flight{}, errors.New("unexpected")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+		})
+	}
+
+}
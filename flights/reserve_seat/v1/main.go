@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -14,28 +16,56 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/policy"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/pricing"
 	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/ssr"
 	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/meetupaws/flight_seat_reservation/internal/payment"
 )
 
 type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
 
 type FlightsRepository interface {
 	Find(id string) (model.Flight, error)
-	ReserveSeat(flightID string, seatID string, passengerID string) error
+	HoldSeat(reservation model.Reservation, until time.Time) error
+	ReleaseSeat(flightID string, seatID string, passengerID string) error
+	ReserveSeat(reservation model.Reservation) (model.Reservation, error)
 }
 
 type Enqueuer interface {
 	SendMsg(msg interface{}, queue string) error
+	SendDelayedMsg(msg interface{}, queue string, delay time.Duration) error
 }
 
 type Request struct {
+	FlightID         string `json:"flight_id"`
+	SeatID           string `json:"seat_id"`
+	PassengerID      string `json:"passenger_id"`
+	ExitRowConfirmed bool   `json:"exit_row_confirmed"`
+	PaymentToken     string `json:"payment_token"`
+	// SpecialRequests are IATA SSR codes, e.g. WCHR or VGML
+	SpecialRequests []string `json:"special_requests"`
+}
+
+type Response struct {
+	Locator     string `json:"locator"`
 	FlightID    string `json:"flight_id"`
 	SeatID      string `json:"seat_id"`
 	PassengerID string `json:"passenger_id"`
 }
 
-func Adapter(flightsRepo FlightsRepository, enqueuer Enqueuer, notificationsQueue string) Handler {
+// seatHoldDuration is how long a seat is kept for the passenger while the
+// payment is authorized
+const seatHoldDuration = 10 * time.Minute
+
+func Adapter(
+	flightsRepo FlightsRepository,
+	payments payment.PaymentProvider,
+	enqueuer Enqueuer,
+	notificationsQueue string,
+	waitlistQueue string,
+) Handler {
 	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		request := Request{}
 		err := json.Unmarshal([]byte(req.Body), &request)
@@ -49,6 +79,10 @@ func Adapter(flightsRepo FlightsRepository, enqueuer Enqueuer, notificationsQueu
 			internal.TrimLines(request.PassengerID) == "" {
 			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
 		}
+		specialRequests, err := ssr.Validate(request.SpecialRequests)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
 
 		// Find the flight
 		flight, err := flightsRepo.Find(request.FlightID)
@@ -59,27 +93,102 @@ func Adapter(flightsRepo FlightsRepository, enqueuer Enqueuer, notificationsQueu
 			return internal.Error(http.StatusInternalServerError, err), nil
 		}
 
-		// Reserve seat
-		err = flightsRepo.ReserveSeat(flight.ID, request.SeatID, request.PassengerID)
-		if err == repository.ErrNoSeatFoundInFlight {
-			return internal.Error(http.StatusNotFound, err), nil
+		// Check the passenger is allowed to take the seat and price it
+		seat := getSeat(flight, request.SeatID)
+		quote := pricing.Quote{}
+		if seat.ID != "" {
+			err = policy.CheckSeat(seat, policy.Passenger{
+				Cabin:            policy.EntitledCabin(flight.Fares, seat),
+				ExitRowConfirmed: request.ExitRowConfirmed,
+				SpecialRequests:  specialRequests,
+			})
+			if err != nil {
+				return internal.Error(http.StatusUnprocessableEntity, err), nil
+			}
+
+			quote, err = pricing.QuoteSeat(flight.Fares, seat)
+			if err != nil {
+				return internal.Error(http.StatusUnprocessableEntity, err), nil
+			}
 		}
-		if err == repository.ErrSeatNotAvailable {
-			return internal.Error(http.StatusUnprocessableEntity, err), nil
+
+		reservation := model.Reservation{
+			FlightID:    flight.ID,
+			SeatID:      request.SeatID,
+			PassengerID: request.PassengerID,
+			Price:       quote.Price(),
+
+			SpecialRequests: specialRequests,
 		}
+
+		// Priced seats are held until the payment is captured
+		authorization := payment.Authorization{}
+		if quote.Total > 0 {
+			if internal.TrimLines(request.PaymentToken) == "" {
+				return internal.Error(http.StatusBadRequest, errors.New("missing payment_token")), nil
+			}
+
+			err = flightsRepo.HoldSeat(reservation, time.Now().Add(seatHoldDuration))
+			if err != nil {
+				return reserveError(err), nil
+			}
+			watchHold(enqueuer, waitlistQueue, reservation)
+
+			authorization, err = payments.Authorize(payment.AuthorizationRequest{
+				Amount:    quote.Total,
+				Currency:  quote.Currency,
+				Reference: fmt.Sprintf("%v/%v/%v", flight.ID, request.SeatID, request.PassengerID),
+				Token:     request.PaymentToken,
+			})
+			if err != nil {
+				releaseSeat(flightsRepo, enqueuer, waitlistQueue, reservation)
+				if err == payment.ErrPaymentDeclined {
+					return internal.Error(http.StatusPaymentRequired, err), nil
+				}
+				return internal.Error(http.StatusBadGateway, err), nil
+			}
+			reservation.PaymentID = authorization.ID
+
+			// Take the money while the seat is still held, a seat is never
+			// confirmed without being paid
+			err = payments.Capture(authorization.ID)
+			if err != nil {
+				refundErr := payments.Refund(authorization.ID)
+				if refundErr != nil {
+					log.Printf("An error ocurred while refunding authorization %v: %v", authorization.ID, refundErr)
+				}
+				releaseSeat(flightsRepo, enqueuer, waitlistQueue, reservation)
+				return internal.Error(http.StatusBadGateway, err), nil
+			}
+		}
+
+		// Reserve seat
+		reserved, err := flightsRepo.ReserveSeat(reservation)
 		if err != nil {
-			return internal.Error(http.StatusInternalServerError, err), nil
+			if authorization.ID != "" {
+				refundErr := payments.Refund(authorization.ID)
+				if refundErr != nil {
+					log.Printf("An error ocurred while refunding authorization %v: %v", authorization.ID, refundErr)
+				}
+				releaseSeat(flightsRepo, enqueuer, waitlistQueue, reservation)
+			}
+			return reserveError(err), nil
 		}
 
 		// Send message to queue
-		seat := getSeat(flight, request.SeatID)
 		err = enqueuer.SendMsg(
 			model.QueueMsgReservedSeat{
-				FlightID:        flight.ID,
-				FlightDeparture: flight.Departure,
-				SeatLetter:      seat.Letter,
-				SeatRow:         seat.Row,
-				UserID:          request.PassengerID,
+				Type:              model.QueueMsgTypeReservedSeat,
+				Locator:           reserved.Locator,
+				FlightID:          flight.ID,
+				FlightDeparture:   flight.Departure,
+				FlightOrigin:      flight.Origin,
+				FlightDestination: flight.Destination,
+				SeatLetter:        seat.Letter,
+				SeatRow:           seat.Row,
+				UserID:            request.PassengerID,
+				Price:             quote.Price(),
+				SpecialRequests:   specialRequests,
 			},
 			notificationsQueue,
 		)
@@ -87,7 +196,67 @@ func Adapter(flightsRepo FlightsRepository, enqueuer Enqueuer, notificationsQueu
 			log.Printf("An error ocurred while sending message to queue %v: %v", notificationsQueue, err)
 		}
 
-		return internal.Respond(http.StatusOK, ""), nil
+		// Respond
+		responseBytes, _ := json.Marshal(Response{
+			Locator:     reserved.Locator,
+			FlightID:    reserved.FlightID,
+			SeatID:      reserved.SeatID,
+			PassengerID: reserved.PassengerID,
+		})
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func reserveError(err error) events.APIGatewayProxyResponse {
+	if err == repository.ErrNoSeatFoundInFlight {
+		return internal.Error(http.StatusNotFound, err)
+	}
+	if err == repository.ErrSeatNotAvailable || err == repository.ErrFlightClosed {
+		return internal.Error(http.StatusUnprocessableEntity, err)
+	}
+	if err == repository.ErrStaleFlight {
+		return internal.Error(http.StatusConflict, err)
+	}
+	return internal.Error(http.StatusInternalServerError, err)
+}
+
+// watchHold checks the hold once it runs out, a seat neither reserved nor
+// released by then is freed for the waitlist
+func watchHold(enqueuer Enqueuer, waitlistQueue string, reservation model.Reservation) {
+	err := enqueuer.SendDelayedMsg(
+		model.QueueMsgSeatHoldExpired{
+			Type:        model.QueueMsgTypeSeatHoldExpired,
+			FlightID:    reservation.FlightID,
+			SeatID:      reservation.SeatID,
+			PassengerID: reservation.PassengerID,
+		},
+		waitlistQueue,
+		seatHoldDuration,
+	)
+	if err != nil {
+		log.Printf("An error ocurred while sending message to queue %v: %v", waitlistQueue, err)
+	}
+}
+
+// releaseSeat gives the seat back and lets the waitlist offer it to somebody
+// else
+func releaseSeat(flightsRepo FlightsRepository, enqueuer Enqueuer, waitlistQueue string, reservation model.Reservation) {
+	err := flightsRepo.ReleaseSeat(reservation.FlightID, reservation.SeatID, reservation.PassengerID)
+	if err != nil {
+		log.Printf("An error ocurred while releasing seat %v of flight %v: %v", reservation.SeatID, reservation.FlightID, err)
+		return
+	}
+
+	err = enqueuer.SendMsg(
+		model.QueueMsgSeatReleased{
+			Type:     model.QueueMsgTypeSeatReleased,
+			FlightID: reservation.FlightID,
+			SeatID:   reservation.SeatID,
+		},
+		waitlistQueue,
+	)
+	if err != nil {
+		log.Printf("An error ocurred while sending message to queue %v: %v", waitlistQueue, err)
 	}
 }
 
@@ -105,14 +274,26 @@ func main() {
 	if internal.TrimLines(flightsTable) == "" {
 		panic("DYNAMODB_FLIGHTS is empty")
 	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
 	notificationsQueue := os.Getenv("NOTIFICATIONS_QUEUE")
 	if internal.TrimLines(notificationsQueue) == "" {
 		panic("NOTIFICATIONS_QUEUE is empty")
 	}
+	waitlistQueue := os.Getenv("WAITLIST_QUEUE")
+	if internal.TrimLines(waitlistQueue) == "" {
+		panic("WAITLIST_QUEUE is empty")
+	}
 	session := session.New()
 	dynamodbClient := dynamodb.New(session)
-	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	payments, err := payment.NewProvider(os.Getenv("PAYMENT_PROVIDER"), os.Getenv("STAGE"))
+	if err != nil {
+		panic(err)
+	}
 	sqsClient := sqs.New(session)
 	enqueuer := internal.NewEnqueuer(sqsClient)
-	lambda.Start(Adapter(flightsRepo, enqueuer, notificationsQueue))
+	lambda.Start(Adapter(flightsRepo, payments, enqueuer, notificationsQueue, waitlistQueue))
 }
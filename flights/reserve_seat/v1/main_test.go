@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/google/go-cmp/cmp"
 	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/policy"
 	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/ssr"
 	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/meetupaws/flight_seat_reservation/internal/payment"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -25,11 +29,40 @@ func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
 	return ret.Get(0).(model.Flight), ret.Error(1)
 }
 
-func (m *FlightsRepositoryMock) ReserveSeat(flightID string, seatID string, passengerID string) error {
+func (m *FlightsRepositoryMock) ReserveSeat(reservation model.Reservation) (model.Reservation, error) {
+	ret := m.Called(reservation)
+	return ret.Get(0).(model.Reservation), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) HoldSeat(reservation model.Reservation, until time.Time) error {
+	ret := m.Called(reservation, until)
+	return ret.Error(0)
+}
+
+func (m *FlightsRepositoryMock) ReleaseSeat(flightID string, seatID string, passengerID string) error {
 	ret := m.Called(flightID, seatID, passengerID)
 	return ret.Error(0)
 }
 
+type PaymentProviderMock struct {
+	mock.Mock
+}
+
+func (m *PaymentProviderMock) Authorize(req payment.AuthorizationRequest) (payment.Authorization, error) {
+	ret := m.Called(req)
+	return ret.Get(0).(payment.Authorization), ret.Error(1)
+}
+
+func (m *PaymentProviderMock) Capture(authorizationID string) error {
+	ret := m.Called(authorizationID)
+	return ret.Error(0)
+}
+
+func (m *PaymentProviderMock) Refund(authorizationID string) error {
+	ret := m.Called(authorizationID)
+	return ret.Error(0)
+}
+
 type EnqueuerMock struct {
 	mock.Mock
 }
@@ -39,13 +72,63 @@ func (m *EnqueuerMock) SendMsg(msg interface{}, queue string) error {
 	return ret.Error(0)
 }
 
+func (m *EnqueuerMock) SendDelayedMsg(msg interface{}, queue string, delay time.Duration) error {
+	ret := m.Called(msg, queue, delay)
+	return ret.Error(0)
+}
+
 func TestAdapter(t *testing.T) {
 
 	type mocks struct {
 		flightsRepo *FlightsRepositoryMock
+		payments    *PaymentProviderMock
 		enqueuer    *EnqueuerMock
 	}
 
+	pricedFlight := model.Flight{
+		ID:        "f1",
+		Departure: "2020-05-01T00:00:00+0000",
+		Fares: model.Fares{
+			Currency: "USD",
+			Cabins: map[string]int64{
+				model.CabinEconomy: 10000,
+			},
+			Surcharges: map[string]int64{
+				model.SeatPositionWindow: 1500,
+			},
+		},
+		Seats: []model.FlightSeat{
+			{
+				ID:       "1A",
+				Letter:   "A",
+				Row:      1,
+				Position: model.SeatPositionWindow,
+				Cabin:    model.CabinEconomy,
+			},
+		},
+	}
+	pricedReservation := model.Reservation{
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "someone@some.com",
+		Price: model.Price{
+			Amount:   11500,
+			Currency: "USD",
+		},
+	}
+	holdExpired := model.QueueMsgSeatHoldExpired{
+		Type:        model.QueueMsgTypeSeatHoldExpired,
+		FlightID:    "f1",
+		SeatID:      "1A",
+		PassengerID: "someone@some.com",
+	}
+	pricedAuthorization := payment.AuthorizationRequest{
+		Amount:    11500,
+		Currency:  "USD",
+		Reference: "f1/1A/someone@some.com",
+		Token:     "tok_visa",
+	}
+
 	type args struct {
 		notificationsQueue string
 	}
@@ -72,6 +155,12 @@ func TestAdapter(t *testing.T) {
 				Headers: map[string]string{
 					"Content-Type": "application/json",
 				},
+				Body: internal.TrimLines(`{
+					"locator":"K7QM2X",
+					"flight_id":"f1",
+					"seat_id":"s1",
+					"passenger_id":"someone@some.com"
+				}`),
 			},
 			mocks: mocks{
 				flightsRepo: &FlightsRepositoryMock{},
@@ -101,14 +190,27 @@ func TestAdapter(t *testing.T) {
 
 				m.flightsRepo.On(
 					"ReserveSeat",
-					"f1",
-					"s1",
-					"someone@some.com",
-				).Return(nil).Once()
+					model.Reservation{
+						FlightID:    "f1",
+						SeatID:      "s1",
+						PassengerID: "someone@some.com",
+					},
+				).Return(
+					model.Reservation{
+						Locator:     "K7QM2X",
+						FlightID:    "f1",
+						SeatID:      "s1",
+						PassengerID: "someone@some.com",
+						Departure:   "2020-05-01T00:00:00+0000",
+					},
+					nil,
+				).Once()
 
 				m.enqueuer.On(
 					"SendMsg",
 					model.QueueMsgReservedSeat{
+						Type:            model.QueueMsgTypeReservedSeat,
+						Locator:         "K7QM2X",
 						FlightID:        "f1",
 						FlightDeparture: "2020-05-01T00:00:00+0000",
 						SeatLetter:      "A",
@@ -257,10 +359,521 @@ func TestAdapter(t *testing.T) {
 
 				m.flightsRepo.On(
 					"ReserveSeat",
+					model.Reservation{
+						FlightID:    "f1",
+						SeatID:      "s1",
+						PassengerID: "p1",
+					},
+				).Return(model.Reservation{}, errors.New("unexpected_reserve")).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because the flight kept changing while reserving the seat",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "s1",
+						"passenger_id": "p1"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrStaleFlight),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On(
+					"Find",
+					"f1",
+				).Return(
+					model.Flight{
+						ID: "f1",
+					},
+					nil,
+				).Once()
+
+				m.flightsRepo.On(
+					"ReserveSeat",
+					model.Reservation{
+						FlightID:    "f1",
+						SeatID:      "s1",
+						PassengerID: "p1",
+					},
+				).Return(model.Reservation{}, repository.ErrStaleFlight).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the passenger did not confirm the exit row eligibility",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "s1",
+						"passenger_id": "p1"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, policy.ErrExitRowNotConfirmed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On(
+					"Find",
+					"f1",
+				).Return(
+					model.Flight{
+						ID: "f1",
+						Seats: []model.FlightSeat{
+							{
+								ID:      "s1",
+								Letter:  "A",
+								Row:     11,
+								ExitRow: true,
+							},
+						},
+					},
+					nil,
+				).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code after reserving a seat with special requests",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "s1",
+						"passenger_id": "someone@some.com",
+						"special_requests": ["wchr", "VGML"]
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"locator":"K7QM2X",
+					"flight_id":"f1",
+					"seat_id":"s1",
+					"passenger_id":"someone@some.com"
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			args: args{
+				notificationsQueue: "queue",
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On("Find", "f1").Return(
+					model.Flight{
+						ID:        "f1",
+						Departure: "2020-05-01T00:00:00+0000",
+						Seats: []model.FlightSeat{
+							{ID: "s1", Letter: "A", Row: 1},
+						},
+					},
+					nil,
+				).Once()
+				m.flightsRepo.On(
+					"ReserveSeat",
+					model.Reservation{
+						FlightID:        "f1",
+						SeatID:          "s1",
+						PassengerID:     "someone@some.com",
+						SpecialRequests: []string{"WCHR", "VGML"},
+					},
+				).Return(
+					model.Reservation{
+						Locator:         "K7QM2X",
+						FlightID:        "f1",
+						SeatID:          "s1",
+						PassengerID:     "someone@some.com",
+						Departure:       "2020-05-01T00:00:00+0000",
+						SpecialRequests: []string{"WCHR", "VGML"},
+					},
+					nil,
+				).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgReservedSeat{
+						Type:            model.QueueMsgTypeReservedSeat,
+						Locator:         "K7QM2X",
+						FlightID:        "f1",
+						FlightDeparture: "2020-05-01T00:00:00+0000",
+						SeatLetter:      "A",
+						SeatRow:         1,
+						UserID:          "someone@some.com",
+						SpecialRequests: []string{"WCHR", "VGML"},
+					},
+					a.notificationsQueue,
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because unaccompanied minors can not sit in an exit row",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "s1",
+						"passenger_id": "p1",
+						"exit_row_confirmed": true,
+						"special_requests": ["UMNR"]
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, policy.ErrExitRowNotAllowed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On("Find", "f1").Return(
+					model.Flight{
+						ID: "f1",
+						Seats: []model.FlightSeat{
+							{ID: "s1", Letter: "A", Row: 11, ExitRow: true},
+						},
+					},
+					nil,
+				).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because of an unknown special request",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "s1",
+						"passenger_id": "p1",
+						"special_requests": ["XXXX"]
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: fmt.Sprintf(`{"errors":["%s: XXXX"]}`, ssr.ErrUnknownSSR),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {},
+		},
+		{
+			name: "Get a 422 status because the seat belongs to a cabin the flight does not sell",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "s1",
+						"passenger_id": "p1",
+						"cabin": "business"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, policy.ErrCabinNotAllowed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On(
+					"Find",
 					"f1",
-					"s1",
-					"p1",
-				).Return(errors.New("unexpected_reserve")).Once()
+				).Return(
+					model.Flight{
+						ID: "f1",
+						Seats: []model.FlightSeat{
+							{
+								ID:     "s1",
+								Letter: "A",
+								Row:    1,
+								Cabin:  model.CabinBusiness,
+							},
+						},
+					},
+					nil,
+				).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code after holding, paying and reserving a priced seat",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "1A",
+						"passenger_id": "someone@some.com",
+						"payment_token": "tok_visa"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"locator":"P4TW9C",
+					"flight_id":"f1",
+					"seat_id":"1A",
+					"passenger_id":"someone@some.com"
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			args: args{
+				notificationsQueue: "queue",
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On("Find", "f1").Return(pricedFlight, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservation, mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", holdExpired, "waitlist", seatHoldDuration).Return(nil).Once()
+				m.payments.On("Authorize", pricedAuthorization).Return(payment.Authorization{
+					ID:       "auth-1",
+					Amount:   11500,
+					Currency: "USD",
+				}, nil).Once()
+
+				confirmed := pricedReservation
+				confirmed.PaymentID = "auth-1"
+				reserved := confirmed
+				reserved.Locator = "P4TW9C"
+				m.flightsRepo.On("ReserveSeat", confirmed).Return(reserved, nil).Once()
+				m.payments.On("Capture", "auth-1").Return(nil).Once()
+
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgReservedSeat{
+						Type:            model.QueueMsgTypeReservedSeat,
+						Locator:         "P4TW9C",
+						FlightID:        "f1",
+						FlightDeparture: "2020-05-01T00:00:00+0000",
+						SeatLetter:      "A",
+						SeatRow:         1,
+						UserID:          "someone@some.com",
+						Price: model.Price{
+							Amount:   11500,
+							Currency: "USD",
+						},
+					},
+					a.notificationsQueue,
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 402 status and release the seat because the payment was declined",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "1A",
+						"passenger_id": "someone@some.com",
+						"payment_token": "tok_visa"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusPaymentRequired,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, payment.ErrPaymentDeclined),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On("Find", "f1").Return(pricedFlight, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservation, mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", holdExpired, "waitlist", seatHoldDuration).Return(nil).Once()
+				m.payments.On("Authorize", pricedAuthorization).Return(payment.Authorization{}, payment.ErrPaymentDeclined).Once()
+				m.flightsRepo.On("ReleaseSeat", "f1", "1A", "someone@some.com").Return(nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgSeatReleased{
+						Type:     model.QueueMsgTypeSeatReleased,
+						FlightID: "f1",
+						SeatID:   "1A",
+					},
+					"waitlist",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 500 status and refund the payment because the seat could not be confirmed",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "1A",
+						"passenger_id": "someone@some.com",
+						"payment_token": "tok_visa"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected_reserve"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On("Find", "f1").Return(pricedFlight, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservation, mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", holdExpired, "waitlist", seatHoldDuration).Return(nil).Once()
+				m.payments.On("Authorize", pricedAuthorization).Return(payment.Authorization{ID: "auth-1"}, nil).Once()
+				m.payments.On("Capture", "auth-1").Return(nil).Once()
+
+				confirmed := pricedReservation
+				confirmed.PaymentID = "auth-1"
+				m.flightsRepo.On("ReserveSeat", confirmed).Return(model.Reservation{}, errors.New("unexpected_reserve")).Once()
+				m.payments.On("Refund", "auth-1").Return(nil).Once()
+				m.flightsRepo.On("ReleaseSeat", "f1", "1A", "someone@some.com").Return(nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgSeatReleased{
+						Type:     model.QueueMsgTypeSeatReleased,
+						FlightID: "f1",
+						SeatID:   "1A",
+					},
+					"waitlist",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 502 status, refund the payment and release the seat because the payment could not be captured",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "1A",
+						"passenger_id": "someone@some.com",
+						"payment_token": "tok_visa"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadGateway,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["provider_down"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On("Find", "f1").Return(pricedFlight, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservation, mock.AnythingOfType("time.Time")).Return(nil).Once()
+				m.enqueuer.On("SendDelayedMsg", holdExpired, "waitlist", seatHoldDuration).Return(nil).Once()
+				m.payments.On("Authorize", pricedAuthorization).Return(payment.Authorization{ID: "auth-1"}, nil).Once()
+				m.payments.On("Capture", "auth-1").Return(errors.New("provider_down")).Once()
+				m.payments.On("Refund", "auth-1").Return(nil).Once()
+				m.flightsRepo.On("ReleaseSeat", "f1", "1A", "someone@some.com").Return(nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgSeatReleased{
+						Type:     model.QueueMsgTypeSeatReleased,
+						FlightID: "f1",
+						SeatID:   "1A",
+					},
+					"waitlist",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the seat is already held by somebody else",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "1A",
+						"passenger_id": "someone@some.com",
+						"payment_token": "tok_visa"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrSeatNotAvailable),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On("Find", "f1").Return(pricedFlight, nil).Once()
+				m.flightsRepo.On("HoldSeat", pricedReservation, mock.AnythingOfType("time.Time")).Return(repository.ErrSeatNotAvailable).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because a priced seat needs a payment token",
+			req: events.APIGatewayProxyRequest{
+				Body: `{
+						"flight_id": "f1",
+						"seat_id": "1A",
+						"passenger_id": "someone@some.com"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing payment_token"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				payments:    &PaymentProviderMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks, a args) {
+				m.flightsRepo.On("Find", "f1").Return(pricedFlight, nil).Once()
 			},
 		},
 	}
@@ -268,10 +881,13 @@ func TestAdapter(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Arrange
+			if tt.mocks.payments == nil {
+				tt.mocks.payments = &PaymentProviderMock{}
+			}
 			tt.mocker(tt.mocks, tt.args)
 
 			// Act
-			handler := Adapter(tt.mocks.flightsRepo, tt.mocks.enqueuer, tt.args.notificationsQueue)
+			handler := Adapter(tt.mocks.flightsRepo, tt.mocks.payments, tt.mocks.enqueuer, tt.args.notificationsQueue, "waitlist")
 			got, err := handler(context.Background(), tt.req)
 
 			// Assert
@@ -280,6 +896,7 @@ func TestAdapter(t *testing.T) {
 				t.Errorf("Differences found: (-want,+got)\n%s", diff)
 			}
 			tt.mocks.flightsRepo.AssertExpectations(t)
+			tt.mocks.payments.AssertExpectations(t)
 		})
 	}
 
@@ -0,0 +1,113 @@
+// Command cli creates or replaces the schedules of a JSON file, e.g.
+//
+//	go run ./flights/save_schedules/cli -file schedules.json -schedules-table dev-schedules
+//
+// The file has a list of schedules like the ones generate_flights reads. It
+// prints a report of the saved and failed schedules and exits with 1 when a
+// schedule failed
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/schedule"
+)
+
+type Report struct {
+	Saved  []string `json:"saved"`
+	Failed []Failed `json:"failed"`
+}
+
+type Failed struct {
+	ScheduleID string `json:"schedule_id"`
+	Reason     string `json:"reason"`
+}
+
+func main() {
+	file := flag.String("file", "", "JSON file with the schedules, the standard input when empty")
+	schedulesTable := flag.String("schedules-table", os.Getenv("DYNAMODB_SCHEDULES"), "schedules table")
+	flag.Parse()
+
+	if *schedulesTable == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	schedules := []model.Schedule{}
+	err := json.NewDecoder(r).Decode(&schedules)
+	if err != nil {
+		fail(err)
+	}
+
+	catalog, err := aircraft.DefaultCatalog()
+	if err != nil {
+		fail(err)
+	}
+	airports, err := airport.DefaultCatalog()
+	if err != nil {
+		fail(err)
+	}
+	session := session.Must(session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	}))
+	schedulesRepo := repository.NewSchedulesRepository(dynamodb.New(session), *schedulesTable)
+
+	report := Report{
+		Saved:  []string{},
+		Failed: []Failed{},
+	}
+	for _, s := range schedules {
+		// The same checks generate_flights does, so a saved schedule generates
+		// its flights
+		err = schedule.Validate(s)
+		if err == nil {
+			_, err = airports.Find(s.Origin)
+		}
+		if err == nil {
+			_, err = airports.Find(s.Destination)
+		}
+		if err == nil {
+			_, err = catalog.Find(s.AircraftType)
+		}
+		if err == nil {
+			err = schedulesRepo.Save(s)
+		}
+		if err != nil {
+			report.Failed = append(report.Failed, Failed{ScheduleID: s.ID, Reason: err.Error()})
+			continue
+		}
+		report.Saved = append(report.Saved, s.ID)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/itinerary"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	ListFlightsByDeparture(dateFrom string, dateTo string) ([]model.Flight, error)
+}
+
+type Response []ResponseConnection
+
+type ResponseConnection struct {
+	Departure     string        `json:"departure"`
+	Arrival       string        `json:"arrival"`
+	TravelMinutes int           `json:"travel_minutes"`
+	Stop          string        `json:"stop"`
+	Legs          []ResponseLeg `json:"legs"`
+}
+
+type ResponseLeg struct {
+	ID           string `json:"id"`
+	FlightNumber string `json:"flight_number"`
+	Origin       string `json:"origin"`
+	Destination  string `json:"destination"`
+	Departure    string `json:"departure"`
+	Arrival      string `json:"arrival"`
+}
+
+// Time zones go from UTC-12 to UTC+14, a local day starts at most 14 hours
+// before the UTC one and ends at most 12 hours after it
+const (
+	earliestOffset = 14 * time.Hour
+	latestOffset   = 12 * time.Hour
+)
+
+// Adapter finds the trips with one stop from the origin to the destination
+// departing on a local date at the origin, e.g. v1/BOG/CTG/2020-05-01. Both
+// legs have free seats and the second one departs between minConnection and
+// maxConnection after the first arrives, the shortest trips come first
+func Adapter(
+	flightsRepo FlightsRepository,
+	airports itinerary.AirportCatalog,
+	minConnection time.Duration,
+	maxConnection time.Duration,
+) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		origin := strings.ToUpper(req.PathParameters["origin"])
+		destination := strings.ToUpper(req.PathParameters["destination"])
+		date := req.PathParameters["date"]
+
+		// Validations
+		if _, err := airports.Find(origin); err != nil {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid origin")), nil
+		}
+		if _, err := airports.Find(destination); err != nil || destination == origin {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid destination")), nil
+		}
+		day, err := time.Parse(model.OperatingDateLayout, date)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, errors.New("invalid date")), nil
+		}
+
+		// Look for the flights departing from the start of the date in every
+		// time zone until the second legs of the latest first leg
+		flights, err := flightsRepo.ListFlightsByDeparture(
+			model.FormatDeparture(day.Add(-earliestOffset)),
+			model.FormatDeparture(day.Add(48*time.Hour+latestOffset+maxConnection)),
+		)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Pair the flights leaving the origin on the date with the ones
+		// arriving at the destination
+		first := []model.Flight{}
+		second := []model.Flight{}
+		for _, f := range flights {
+			if !f.IsBookable() || !hasFreeSeat(f) {
+				continue
+			}
+			localDate, err := f.LocalDate()
+			if err != nil {
+				continue
+			}
+			if f.Origin == origin && f.Destination != destination && localDate == date {
+				first = append(first, f)
+			}
+			if f.Destination == destination && f.Origin != origin {
+				second = append(second, f)
+			}
+		}
+		connections := itinerary.Connections(first, second, airports, minConnection, maxConnection)
+		if len(connections) == 0 {
+			return internal.Error(http.StatusNotFound, repository.ErrNoFlightsFound), nil
+		}
+
+		// Prepare response
+		response := make(Response, len(connections))
+		for i, c := range connections {
+			rConnection := ResponseConnection{
+				Departure:     model.FormatDeparture(c.Departure),
+				Arrival:       model.FormatDeparture(c.Arrival),
+				TravelMinutes: int(c.TravelTime().Minutes()),
+				Stop:          c.Legs[0].Destination,
+				Legs:          make([]ResponseLeg, len(c.Legs)),
+			}
+			for j, leg := range c.Legs {
+				arrival, err := itinerary.Arrival(leg, airports)
+				if err != nil {
+					return internal.Error(http.StatusInternalServerError, err), nil
+				}
+				rLeg := ResponseLeg{
+					ID:          leg.ID,
+					Origin:      leg.Origin,
+					Destination: leg.Destination,
+					Departure:   leg.Departure,
+					Arrival:     model.FormatDeparture(arrival),
+				}
+				if leg.Carrier != "" {
+					rLeg.FlightNumber = model.Designator(leg.Carrier, leg.FlightNumber)
+				}
+				rConnection.Legs[j] = rLeg
+			}
+			response[i] = rConnection
+		}
+
+		// Respond
+		responseBytes, _ := json.Marshal(response)
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func hasFreeSeat(flight model.Flight) bool {
+	for _, s := range flight.Seats {
+		if s.PassengerID == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	minConnection, err := time.ParseDuration(os.Getenv("MIN_CONNECTION_TIME"))
+	if err != nil {
+		panic("MIN_CONNECTION_TIME must be a duration, e.g. 45m")
+	}
+	maxConnection, err := time.ParseDuration(os.Getenv("MAX_CONNECTION_TIME"))
+	if err != nil || maxConnection < minConnection {
+		panic("MAX_CONNECTION_TIME must be a duration longer than MIN_CONNECTION_TIME, e.g. 6h")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	airports, err := airport.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	lambda.Start(Adapter(flightsRepo, airports, minConnection, maxConnection))
+}
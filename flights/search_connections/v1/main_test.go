@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) ListFlightsByDeparture(dateFrom string, dateTo string) ([]model.Flight, error) {
+	ret := m.Called(dateFrom, dateTo)
+	return ret.Get(0).([]model.Flight), ret.Error(1)
+}
+
+func TestAdapter(t *testing.T) {
+
+	airports, err := airport.DefaultCatalog()
+	require.NoError(t, err)
+
+	freeSeats := []model.FlightSeat{{ID: "1A", Letter: "A", Row: 1}}
+	fullSeats := []model.FlightSeat{{ID: "1A", Letter: "A", Row: 1, PassengerID: "p1"}}
+	flight := func(id string, departure string, origin string, destination string, blockMinutes int, seats []model.FlightSeat) model.Flight {
+		return model.Flight{
+			ID:             id,
+			Carrier:        "AV",
+			FlightNumber:   id[1:],
+			Departure:      departure,
+			BlockMinutes:   blockMinutes,
+			Origin:         origin,
+			Destination:    destination,
+			OriginTimeZone: "America/Bogota",
+			HasFreeSeats:   true,
+			Seats:          seats,
+		}
+	}
+	flights := []model.Flight{
+		flight("f1", "2020-05-01T11:30:00Z", "BOG", "MDE", 50, freeSeats),
+		flight("f2", "2020-05-01T10:00:00Z", "BOG", "CLO", 55, freeSeats),
+		flight("f3", "2020-05-01T14:00:00Z", "MDE", "CTG", 70, freeSeats),
+		flight("f4", "2020-05-01T12:00:00Z", "CLO", "CTG", 90, freeSeats),
+		// Would be the shortest trip but it is full
+		flight("f5", "2020-05-01T11:50:00Z", "CLO", "CTG", 90, fullSeats),
+		// Direct flights are not connections
+		flight("f6", "2020-05-01T13:00:00Z", "BOG", "CTG", 90, freeSeats),
+		// Departs at 23:00 of the day before in Bogotá
+		flight("f7", "2020-05-01T04:00:00Z", "BOG", "MDE", 50, freeSeats),
+		flight("f8", "2020-05-01T06:00:00Z", "MDE", "CTG", 70, freeSeats),
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  *FlightsRepositoryMock
+		mocker func(m *FlightsRepositoryMock)
+	}{
+		{
+			name: "Get a 200 status code with the connections ranked by travel time",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"origin":      "bog",
+					"destination": "CTG",
+					"date":        "2020-05-01",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`[
+					{"departure":"2020-05-01T10:00:00Z","arrival":"2020-05-01T13:30:00Z","travel_minutes":210,"stop":"CLO","legs":[
+						{"id":"f2","flight_number":"AV2","origin":"BOG","destination":"CLO",
+							"departure":"2020-05-01T10:00:00Z","arrival":"2020-05-01T10:55:00Z"},
+						{"id":"f4","flight_number":"AV4","origin":"CLO","destination":"CTG",
+							"departure":"2020-05-01T12:00:00Z","arrival":"2020-05-01T13:30:00Z"}
+					]},
+					{"departure":"2020-05-01T11:30:00Z","arrival":"2020-05-01T15:10:00Z","travel_minutes":220,"stop":"MDE","legs":[
+						{"id":"f1","flight_number":"AV1","origin":"BOG","destination":"MDE",
+							"departure":"2020-05-01T11:30:00Z","arrival":"2020-05-01T12:20:00Z"},
+						{"id":"f3","flight_number":"AV3","origin":"MDE","destination":"CTG",
+							"departure":"2020-05-01T14:00:00Z","arrival":"2020-05-01T15:10:00Z"}
+					]}
+				]`),
+			},
+			mocks: &FlightsRepositoryMock{},
+			mocker: func(m *FlightsRepositoryMock) {
+				m.On("ListFlightsByDeparture", "2020-04-30T10:00:00Z", "2020-05-03T18:00:00Z").Return(flights, nil).Once()
+			},
+		},
+		{
+			name: "Get a 404 status code because no flights connect the airports",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"origin":      "BOG",
+					"destination": "MIA",
+					"date":        "2020-05-01",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: &FlightsRepositoryMock{},
+			mocker: func(m *FlightsRepositoryMock) {
+				m.On("ListFlightsByDeparture", "2020-04-30T10:00:00Z", "2020-05-03T18:00:00Z").Return(flights, nil).Once()
+			},
+		},
+		{
+			name: "Get a 500 status code because the repository failed",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"origin":      "BOG",
+					"destination": "CTG",
+					"date":        "2020-05-01",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["some error"]}`),
+			},
+			mocks: &FlightsRepositoryMock{},
+			mocker: func(m *FlightsRepositoryMock) {
+				m.On("ListFlightsByDeparture", "2020-04-30T10:00:00Z", "2020-05-03T18:00:00Z").
+					Return([]model.Flight{}, errors.New("some error")).Once()
+			},
+		},
+		{
+			name: "Get a 400 status code because the origin is not in the catalog",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"origin":      "XXX",
+					"destination": "CTG",
+					"date":        "2020-05-01",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid origin"]}`),
+			},
+			mocks:  &FlightsRepositoryMock{},
+			mocker: func(m *FlightsRepositoryMock) {},
+		},
+		{
+			name: "Get a 400 status code because the destination is the origin",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"origin":      "BOG",
+					"destination": "BOG",
+					"date":        "2020-05-01",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid destination"]}`),
+			},
+			mocks:  &FlightsRepositoryMock{},
+			mocker: func(m *FlightsRepositoryMock) {},
+		},
+		{
+			name: "Get a 400 status code because the date is malformed",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"origin":      "BOG",
+					"destination": "CTG",
+					"date":        "01-05-2020",
+				},
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid date"]}`),
+			},
+			mocks:  &FlightsRepositoryMock{},
+			mocker: func(m *FlightsRepositoryMock) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks, airports, 45*time.Minute, 6*time.Hour)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.AssertExpectations(t)
+		})
+	}
+
+}
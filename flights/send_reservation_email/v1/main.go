@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
@@ -10,57 +11,298 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/boardingpass"
 	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/ssr"
 	"github.com/meetupaws/flight_seat_reservation/internal"
 )
 
+var (
+	ErrUnknownMsgType = errors.New("unknown_msg_type")
+)
+
 type Handler func(ctx context.Context, event events.SQSEvent) error
 
 type FlightsRepository interface {
 	Find(id string) (model.Flight, error)
-	ReserveSeat(flightID string, seatID string, passengerID string) error
+	ReserveSeat(reservation model.Reservation) (model.Reservation, error)
+}
+
+type AirportCatalog interface {
+	Find(code string) (airport.Airport, error)
 }
 
 type Mailer interface {
 	SendEmail(subject string, body string, from string, to []string, cc []string) error
+	SendEmailWithAttachments(subject string, body string, from string, to []string, attachments []internal.Attachment) error
 }
 
 var emailTemplate = `
 Hello! %v.
-Your resevartion is confirmed, seat %v%v for the fly with id %v on on %v!
+Your resevartion is confirmed, seat %v%v for the fly with id %v on %v!
+Your booking reference is %v.
+`
+
+var itineraryTemplate = `
+Hello! %v.
+Your trip is confirmed, your booking reference is %v.
+`
+
+var legTemplate = `Seat %v%v for the fly with id %v on %v.
+`
+
+var routeTemplate = `From %v to %v.
+`
+
+var priceTemplate = `You paid %v.
+`
+
+var specialRequestsTemplate = `Special requests: %v.
+`
+
+var seatChangedTemplate = `
+Hello! %v.
+Your seat for the fly with id %v on %v was changed from %v to %v%v.
+Your booking reference is %v.
+`
+
+var waitlistOfferTemplate = `
+Hello! %v.
+A seat opened up on the fly with id %v on %v, seat %v is held for you until %v.
+Reserve it before then or it will be offered to the next passenger.
+`
+
+var flightStatusTemplate = `
+Hello! %v.
+The fly with id %v on %v is now %v.
 `
 
+var reasonTemplate = `Reason: %v.
+`
+
+var reaccommodatedTemplate = `
+Hello! %v.
+The fly with id %v was cancelled, you were moved to the fly with id %v on %v, seat %v.
+Your booking reference is %v.
+`
+
+var boardingPassTemplate = `
+Hello! %v.
+You are checked in for the fly with id %v on %v, seat %v.
+Your boarding pass is attached, show it at the gate.
+`
+
+type email struct {
+	subject     string
+	body        string
+	to          string
+	attachments []internal.Attachment
+}
+
 type Request struct {
 	FlightID    string `json:"flight_id"`
 	SeatID      string `json:"seat_id"`
 	PassengerID string `json:"passenger_id"`
 }
 
-func Adapter(mailer Mailer, senderEmail string) Handler {
+func Adapter(mailer Mailer, senderEmail string, barcodeFormat string, airports AirportCatalog) Handler {
 	return func(ctx context.Context, event events.SQSEvent) error {
+		for _, record := range event.Records {
+			msg := model.QueueMsg{}
+			err := json.Unmarshal([]byte(record.Body), &msg)
+			if err != nil {
+				return err
+			}
+
+			e, err := buildEmail(msg.Type, record.Body, barcodeFormat, airports)
+			if err != nil {
+				return err
+			}
+
+			if len(e.attachments) > 0 {
+				err = mailer.SendEmailWithAttachments(
+					e.subject,
+					e.body,
+					senderEmail,
+					[]string{e.to},
+					e.attachments,
+				)
+			} else {
+				err = mailer.SendEmail(
+					e.subject,
+					e.body,
+					senderEmail,
+					[]string{e.to},
+					nil,
+				)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// buildEmail returns the email a message turns into
+func buildEmail(msgType string, body string, barcodeFormat string, airports AirportCatalog) (email, error) {
+	switch msgType {
+	case model.QueueMsgTypeBoardingPass:
+		msgBody := model.QueueMsgBoardingPass{}
+		err := json.Unmarshal([]byte(body), &msgBody)
+		if err != nil {
+			return email{}, err
+		}
+		image, err := boardingpass.Render(msgBody.BCBP, barcodeFormat)
+		if err != nil {
+			return email{}, err
+		}
+		emailBody := fmt.Sprintf(
+			boardingPassTemplate,
+			msgBody.UserID,
+			msgBody.FlightID,
+			msgBody.FlightDeparture,
+			msgBody.SeatID,
+		)
+		emailBody += route(airports, msgBody.FlightOrigin, msgBody.FlightDestination)
+		attachments := []internal.Attachment{
+			{
+				Filename:    "boarding-pass.png",
+				ContentType: "image/png",
+				Data:        image,
+			},
+		}
+		return email{"Your boarding pass", emailBody, msgBody.UserID, attachments}, nil
+	case model.QueueMsgTypeFlightStatus:
+		msgBody := model.QueueMsgFlightStatus{}
+		err := json.Unmarshal([]byte(body), &msgBody)
+		if err != nil {
+			return email{}, err
+		}
+		emailBody := fmt.Sprintf(
+			flightStatusTemplate,
+			msgBody.UserID,
+			msgBody.FlightID,
+			msgBody.FlightDeparture,
+			msgBody.Status,
+		)
+		emailBody += route(airports, msgBody.FlightOrigin, msgBody.FlightDestination)
+		if msgBody.Reason != "" {
+			emailBody += fmt.Sprintf(reasonTemplate, msgBody.Reason)
+		}
+		return email{"Flight status update", emailBody, msgBody.UserID, nil}, nil
+	case model.QueueMsgTypeReaccommodated:
+		msgBody := model.QueueMsgReaccommodated{}
+		err := json.Unmarshal([]byte(body), &msgBody)
+		if err != nil {
+			return email{}, err
+		}
+		emailBody := fmt.Sprintf(
+			reaccommodatedTemplate,
+			msgBody.UserID,
+			msgBody.FromFlightID,
+			msgBody.FlightID,
+			msgBody.FlightDeparture,
+			msgBody.SeatID,
+			msgBody.Locator,
+		)
+		emailBody += route(airports, msgBody.FlightOrigin, msgBody.FlightDestination)
+		return email{"You were moved to another flight", emailBody, msgBody.UserID, nil}, nil
+	case model.QueueMsgTypeSeatChanged:
+		msgBody := model.QueueMsgSeatChanged{}
+		err := json.Unmarshal([]byte(body), &msgBody)
+		if err != nil {
+			return email{}, err
+		}
+		emailBody := fmt.Sprintf(
+			seatChangedTemplate,
+			msgBody.UserID,
+			msgBody.FlightID,
+			msgBody.FlightDeparture,
+			msgBody.FromSeatID,
+			msgBody.ToSeatRow,
+			msgBody.ToSeatLetter,
+			msgBody.Locator,
+		)
+		emailBody += route(airports, msgBody.FlightOrigin, msgBody.FlightDestination)
+		return email{"Flight seat changed", emailBody, msgBody.UserID, nil}, nil
+	case model.QueueMsgTypeWaitlistOffer:
+		msgBody := model.QueueMsgWaitlistOffer{}
+		err := json.Unmarshal([]byte(body), &msgBody)
+		if err != nil {
+			return email{}, err
+		}
+		emailBody := fmt.Sprintf(
+			waitlistOfferTemplate,
+			msgBody.UserID,
+			msgBody.FlightID,
+			msgBody.FlightDeparture,
+			msgBody.SeatID,
+			msgBody.ExpiresAt,
+		)
+		emailBody += route(airports, msgBody.FlightOrigin, msgBody.FlightDestination)
+		return email{"A seat is waiting for you", emailBody, msgBody.UserID, nil}, nil
+	case model.QueueMsgTypeItinerary:
+		msgBody := model.QueueMsgItinerary{}
+		err := json.Unmarshal([]byte(body), &msgBody)
+		if err != nil {
+			return email{}, err
+		}
+		emailBody := fmt.Sprintf(itineraryTemplate, msgBody.UserID, msgBody.Locator)
+		for _, leg := range msgBody.Legs {
+			emailBody += fmt.Sprintf(legTemplate, leg.SeatRow, leg.SeatLetter, leg.FlightID, leg.FlightDeparture)
+			emailBody += route(airports, leg.FlightOrigin, leg.FlightDestination)
+		}
+		if msgBody.Price.Amount > 0 {
+			emailBody += fmt.Sprintf(priceTemplate, msgBody.Price)
+		}
+		if len(msgBody.SpecialRequests) > 0 {
+			emailBody += fmt.Sprintf(specialRequestsTemplate, ssr.Describe(msgBody.SpecialRequests))
+		}
+		return email{"Trip reservation", emailBody, msgBody.UserID, nil}, nil
+	case model.QueueMsgTypeReservedSeat, "":
 		msgBody := model.QueueMsgReservedSeat{}
-		err := json.Unmarshal([]byte(event.Records[0].Body), &msgBody)
+		err := json.Unmarshal([]byte(body), &msgBody)
 		if err != nil {
-			return err
+			return email{}, err
 		}
-
 		emailBody := fmt.Sprintf(
+			emailTemplate,
 			msgBody.UserID,
 			msgBody.SeatRow,
 			msgBody.SeatLetter,
 			msgBody.FlightID,
 			msgBody.FlightDeparture,
+			msgBody.Locator,
 		)
+		emailBody += route(airports, msgBody.FlightOrigin, msgBody.FlightDestination)
+		if msgBody.Price.Amount > 0 {
+			emailBody += fmt.Sprintf(priceTemplate, msgBody.Price)
+		}
+		if len(msgBody.SpecialRequests) > 0 {
+			emailBody += fmt.Sprintf(specialRequestsTemplate, ssr.Describe(msgBody.SpecialRequests))
+		}
+		return email{"Flight seat reservation", emailBody, msgBody.UserID, nil}, nil
+	}
+	return email{}, fmt.Errorf("%w: %v", ErrUnknownMsgType, msgType)
+}
 
-		err = mailer.SendEmail(
-			"Flight seat reservation",
-			emailBody,
-			senderEmail,
-			[]string{msgBody.UserID},
-			nil,
-		)
-		return err
+// route tells the cities the flight flies between, e.g. From Bogotá (BOG) to
+// Medellín (MDE). Codes missing from the catalog are shown as they are
+func route(airports AirportCatalog, origin string, destination string) string {
+	if origin == "" || destination == "" {
+		return ""
 	}
+	return fmt.Sprintf(routeTemplate, place(airports, origin), place(airports, destination))
+}
+
+func place(airports AirportCatalog, code string) string {
+	found, err := airports.Find(code)
+	if err != nil {
+		return code
+	}
+	return found.Place()
 }
 
 func main() {
@@ -68,8 +310,16 @@ func main() {
 	if internal.TrimLines(senderEmail) == "" {
 		panic("SENDER_EMAIL is empty")
 	}
+	barcodeFormat := os.Getenv("BOARDING_PASS_BARCODE")
+	if !boardingpass.IsBarcode(barcodeFormat) {
+		panic("BOARDING_PASS_BARCODE must be qr or pdf417")
+	}
 	session := session.New()
 	sesClient := ses.New(session)
 	mailer := internal.NewMailer(sesClient)
-	lambda.Start(Adapter(mailer, senderEmail))
+	airports, err := airport.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	lambda.Start(Adapter(mailer, senderEmail, barcodeFormat, airports))
 }
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/airport"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/boardingpass"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MailerMock struct {
+	mock.Mock
+}
+
+func (m *MailerMock) SendEmail(subject string, body string, from string, to []string, cc []string) error {
+	ret := m.Called(subject, body, from, to, cc)
+	return ret.Error(0)
+}
+
+func (m *MailerMock) SendEmailWithAttachments(subject string, body string, from string, to []string, attachments []internal.Attachment) error {
+	ret := m.Called(subject, body, from, to, attachments)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	airports := airport.Catalog{
+		"BOG": {IATACode: "BOG", City: "Bogotá", TimeZone: "America/Bogota"},
+	}
+
+	tests := []struct {
+		name    string
+		event   events.SQSEvent
+		wantErr error
+		mocker  func(m *MailerMock)
+	}{
+		{
+			name: "Send the reservation email, messages without a type are reservations",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"locator":"K7QM2X","flight_id":"f1","flight_departure":"2020-05-01T00:00:00+0000",` +
+							`"seat_letter":"A","seat_row":1,"user_id":"someone@some.com","price":{"amount":11500,"currency":"USD"}}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"Flight seat reservation",
+					"\nHello! someone@some.com.\n"+
+						"Your resevartion is confirmed, seat 1A for the fly with id f1 on 2020-05-01T00:00:00+0000!\n"+
+						"Your booking reference is K7QM2X.\n"+
+						"You paid USD 115.00.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send the reservation email with the price in the decimals of the currency",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"locator":"K7QM2X","flight_id":"f1","flight_departure":"2020-05-01T00:00:00+0000",` +
+							`"seat_letter":"A","seat_row":1,"user_id":"someone@some.com","price":{"amount":15000,"currency":"JPY"}}`,
+					},
+					{
+						Body: `{"locator":"K7QM2Y","flight_id":"f1","flight_departure":"2020-05-01T00:00:00+0000",` +
+							`"seat_letter":"B","seat_row":1,"user_id":"someone@some.com","price":{"amount":35250,"currency":"KWD"}}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"Flight seat reservation",
+					"\nHello! someone@some.com.\n"+
+						"Your resevartion is confirmed, seat 1A for the fly with id f1 on 2020-05-01T00:00:00+0000!\n"+
+						"Your booking reference is K7QM2X.\n"+
+						"You paid JPY 15000.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+				m.On(
+					"SendEmail",
+					"Flight seat reservation",
+					"\nHello! someone@some.com.\n"+
+						"Your resevartion is confirmed, seat 1B for the fly with id f1 on 2020-05-01T00:00:00+0000!\n"+
+						"Your booking reference is K7QM2Y.\n"+
+						"You paid KWD 35.250.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send the reservation email with the special requests",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"reserved_seat","locator":"K7QM2X","flight_id":"f1","flight_departure":"2020-05-01T00:00:00+0000",` +
+							`"seat_letter":"A","seat_row":1,"user_id":"someone@some.com","special_requests":["WCHR","VGML"]}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"Flight seat reservation",
+					"\nHello! someone@some.com.\n"+
+						"Your resevartion is confirmed, seat 1A for the fly with id f1 on 2020-05-01T00:00:00+0000!\n"+
+						"Your booking reference is K7QM2X.\n"+
+						"Special requests: WCHR (Wheelchair, can climb stairs), VGML (Vegetarian meal).\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send the reservation email with the cities of the route, unknown airports keep their code",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"reserved_seat","locator":"K7QM2X","flight_id":"f1","flight_departure":"2020-05-01T00:00:00+0000",` +
+							`"flight_origin":"BOG","flight_destination":"MIA","seat_letter":"A","seat_row":1,"user_id":"someone@some.com"}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"Flight seat reservation",
+					"\nHello! someone@some.com.\n"+
+						"Your resevartion is confirmed, seat 1A for the fly with id f1 on 2020-05-01T00:00:00+0000!\n"+
+						"Your booking reference is K7QM2X.\n"+
+						"From Bogotá (BOG) to MIA.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send one email with every leg of the trip",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"itinerary_reserved","locator":"K7QM2X","user_id":"someone@some.com","legs":[` +
+							`{"flight_id":"f1","flight_departure":"2020-05-01T11:30:00Z","flight_origin":"BOG","flight_destination":"MDE",` +
+							`"seat_letter":"A","seat_row":1},` +
+							`{"flight_id":"f2","flight_departure":"2020-05-01T14:00:00Z","flight_origin":"MDE","flight_destination":"CTG",` +
+							`"seat_letter":"C","seat_row":3}],` +
+							`"price":{"amount":20000,"currency":"USD"},"special_requests":["VGML"]}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"Trip reservation",
+					"\nHello! someone@some.com.\n"+
+						"Your trip is confirmed, your booking reference is K7QM2X.\n"+
+						"Seat 1A for the fly with id f1 on 2020-05-01T11:30:00Z.\n"+
+						"From Bogotá (BOG) to MDE.\n"+
+						"Seat 3C for the fly with id f2 on 2020-05-01T14:00:00Z.\n"+
+						"From MDE to CTG.\n"+
+						"You paid USD 200.00.\n"+
+						"Special requests: VGML (Vegetarian meal).\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send one email per record, seat changes included",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"reserved_seat","locator":"K7QM2X","flight_id":"f1",` +
+							`"flight_departure":"2020-05-01T00:00:00+0000","seat_letter":"B","seat_row":23,"user_id":"someone@some.com"}`,
+					},
+					{
+						Body: `{"type":"seat_changed","locator":"K7QM2X","flight_id":"f1","flight_departure":"2020-05-01T00:00:00+0000",` +
+							`"from_seat_id":"23B","to_seat_letter":"C","to_seat_row":23,"user_id":"someone@some.com"}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"Flight seat reservation",
+					mock.AnythingOfType("string"),
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+				m.On(
+					"SendEmail",
+					"Flight seat changed",
+					"\nHello! someone@some.com.\n"+
+						"Your seat for the fly with id f1 on 2020-05-01T00:00:00+0000 was changed from 23B to 23C.\n"+
+						"Your booking reference is K7QM2X.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send the waitlist offer email",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"waitlist_offer","flight_id":"f1","flight_departure":"2020-05-01T00:00:00+0000",` +
+							`"seat_id":"1A","user_id":"someone@some.com","expires_at":"2020-04-30T12:15:00Z"}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"A seat is waiting for you",
+					"\nHello! someone@some.com.\n"+
+						"A seat opened up on the fly with id f1 on 2020-05-01T00:00:00+0000, seat 1A is held for you until 2020-04-30T12:15:00Z.\n"+
+						"Reserve it before then or it will be offered to the next passenger.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send the flight status email",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"flight_status_changed","flight_id":"f1","flight_departure":"2020-05-01T02:00:00+0000",` +
+							`"status":"delayed","reason":"late inbound aircraft","user_id":"someone@some.com"}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"Flight status update",
+					"\nHello! someone@some.com.\n"+
+						"The fly with id f1 on 2020-05-01T02:00:00+0000 is now delayed.\n"+
+						"Reason: late inbound aircraft.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send the reaccommodation email",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"reaccommodated","locator":"K7QM2X","from_flight_id":"f1","flight_id":"f2",` +
+							`"flight_departure":"2020-05-01T16:00:00+0000","seat_id":"5B","user_id":"someone@some.com"}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				m.On(
+					"SendEmail",
+					"You were moved to another flight",
+					"\nHello! someone@some.com.\n"+
+						"The fly with id f1 was cancelled, you were moved to the fly with id f2 on 2020-05-01T16:00:00+0000, seat 5B.\n"+
+						"Your booking reference is K7QM2X.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]string(nil),
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Send the boarding pass with its barcode attached",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"boarding_pass","locator":"K7QM2X","flight_id":"f1",` +
+							`"flight_departure":"2020-05-01T16:00:00+0000","seat_id":"12A","user_id":"someone@some.com",` +
+							`"bcbp":"M1DOE/JOHN            EK7QM2X BOGMDEAV 0123 122Y012A0001 100"}`,
+					},
+				},
+			},
+			mocker: func(m *MailerMock) {
+				image, _ := boardingpass.Render("M1DOE/JOHN            EK7QM2X BOGMDEAV 0123 122Y012A0001 100", boardingpass.BarcodeQR)
+				m.On(
+					"SendEmailWithAttachments",
+					"Your boarding pass",
+					"\nHello! someone@some.com.\n"+
+						"You are checked in for the fly with id f1 on 2020-05-01T16:00:00+0000, seat 12A.\n"+
+						"Your boarding pass is attached, show it at the gate.\n",
+					"sender@some.com",
+					[]string{"someone@some.com"},
+					[]internal.Attachment{
+						{
+							Filename:    "boarding-pass.png",
+							ContentType: "image/png",
+							Data:        image,
+						},
+					},
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Fail on an unknown message type",
+			event: events.SQSEvent{
+				Records: []events.SQSMessage{
+					{
+						Body: `{"type":"something_else"}`,
+					},
+				},
+			},
+			wantErr: ErrUnknownMsgType,
+			mocker:  func(m *MailerMock) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mailer := &MailerMock{}
+			tt.mocker(mailer)
+
+			// Act
+			handler := Adapter(mailer, "sender@some.com", boardingpass.BarcodeQR, airports)
+			err := handler(context.Background(), tt.event)
+
+			// Assert
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), "got error %v", err)
+			} else {
+				require.NoError(t, err)
+			}
+			mailer.AssertExpectations(t)
+		})
+	}
+
+}
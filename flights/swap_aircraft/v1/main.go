@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/seatmap"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	Find(id string) (model.Flight, error)
+	SwapAircraft(m model.Flight, moves []model.SeatMove) (model.Flight, error)
+}
+
+type AircraftCatalog interface {
+	Find(aircraftType string) (aircraft.Configuration, error)
+}
+
+type Enqueuer interface {
+	SendMsg(msg interface{}, queue string) error
+}
+
+type Request struct {
+	AircraftType string `json:"aircraft_type"`
+}
+
+type Response struct {
+	ID           string           `json:"id"`
+	AircraftType string           `json:"aircraft_type"`
+	Moves        []model.SeatMove `json:"moves"`
+}
+
+func Adapter(flightsRepo FlightsRepository, catalog AircraftCatalog, enqueuer Enqueuer, notificationsQueue string) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		flightID := req.PathParameters["id"]
+
+		request := Request{}
+		err := json.Unmarshal([]byte(req.Body), &request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Validations
+		if internal.TrimLines(flightID) == "" || internal.TrimLines(request.AircraftType) == "" {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+
+		configuration, err := catalog.Find(request.AircraftType)
+		if err == aircraft.ErrUnknownAircraftType {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Find the flight
+		flight, err := flightsRepo.Find(flightID)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+		if !flight.IsBookable() {
+			return internal.Error(http.StatusUnprocessableEntity, repository.ErrFlightClosed), nil
+		}
+
+		// Move the passengers to the new seat map
+		seats, moves, err := seatmap.Remap(flight.Seats, configuration.Seats())
+		if err == seatmap.ErrPassengersDoNotFit {
+			return internal.Error(http.StatusUnprocessableEntity, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+		flight.AircraftType = request.AircraftType
+		flight.Seats = seats
+
+		flight, err = flightsRepo.SwapAircraft(flight, moves)
+		if err == repository.ErrFlightClosed || err == repository.ErrTooManySeatMoves {
+			return internal.Error(http.StatusUnprocessableEntity, err), nil
+		}
+		if err == repository.ErrStaleFlight || err == repository.ErrReservationNotInSeat {
+			return internal.Error(http.StatusConflict, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Let the moved passengers know, holds are not confirmed yet
+		for _, move := range moves {
+			seat := findSeat(flight, move.ToSeatID)
+			if seat.HeldUntil != "" {
+				continue
+			}
+			err = enqueuer.SendMsg(
+				model.QueueMsgSeatChanged{
+					Type:              model.QueueMsgTypeSeatChanged,
+					Locator:           move.Locator,
+					FlightID:          flight.ID,
+					FlightDeparture:   flight.Departure,
+					FlightOrigin:      flight.Origin,
+					FlightDestination: flight.Destination,
+					FromSeatID:        move.FromSeatID,
+					ToSeatLetter:      seat.Letter,
+					ToSeatRow:         seat.Row,
+					UserID:            move.PassengerID,
+				},
+				notificationsQueue,
+			)
+			if err != nil {
+				log.Printf("An error ocurred while sending message to queue %v: %v", notificationsQueue, err)
+			}
+		}
+
+		responseBytes, _ := json.Marshal(Response{
+			ID:           flight.ID,
+			AircraftType: flight.AircraftType,
+			Moves:        moves,
+		})
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func findSeat(flight model.Flight, seatID string) model.FlightSeat {
+	for _, s := range flight.Seats {
+		if s.ID == seatID {
+			return s
+		}
+	}
+	return model.FlightSeat{}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	notificationsQueue := os.Getenv("NOTIFICATIONS_QUEUE")
+	if internal.TrimLines(notificationsQueue) == "" {
+		panic("NOTIFICATIONS_QUEUE is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	catalog, err := aircraft.DefaultCatalog()
+	if err != nil {
+		panic(err)
+	}
+	sqsClient := sqs.New(session)
+	enqueuer := internal.NewEnqueuer(sqsClient)
+	lambda.Start(Adapter(flightsRepo, catalog, enqueuer, notificationsQueue))
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/aircraft"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/seatmap"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) Find(id string) (model.Flight, error) {
+	ret := m.Called(id)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+func (m *FlightsRepositoryMock) SwapAircraft(flight model.Flight, moves []model.SeatMove) (model.Flight, error) {
+	ret := m.Called(flight, moves)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+type EnqueuerMock struct {
+	mock.Mock
+}
+
+func (m *EnqueuerMock) SendMsg(msg interface{}, queue string) error {
+	ret := m.Called(msg, queue)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+		enqueuer    *EnqueuerMock
+	}
+
+	catalog := aircraft.Catalog{
+		"SMALL": {
+			Sections: []aircraft.Section{
+				{
+					Layout:   "AB",
+					FirstRow: 1,
+					LastRow:  1,
+				},
+			},
+		},
+	}
+	flight := model.Flight{
+		ID:           "f1",
+		Departure:    "2020-05-01T00:00:00+0000",
+		AircraftType: "BIG",
+		Version:      3,
+		Seats: []model.FlightSeat{
+			{ID: "1A", Letter: "A", Row: 1, Cabin: model.CabinEconomy, PassengerID: "p1", Locator: "AAAAAA"},
+			{ID: "1C", Letter: "C", Row: 1, Cabin: model.CabinEconomy, PassengerID: "p2", Locator: "BBBBBB"},
+		},
+	}
+	swapped := flight
+	swapped.AircraftType = "SMALL"
+	swapped.Seats = catalog["SMALL"].Seats()
+	swapped.Seats[0].PassengerID = "p1"
+	swapped.Seats[0].Locator = "AAAAAA"
+	swapped.Seats[1].PassengerID = "p2"
+	swapped.Seats[1].Locator = "BBBBBB"
+	moves := []model.SeatMove{
+		{
+			PassengerID: "p2",
+			Locator:     "BBBBBB",
+			FromSeatID:  "1C",
+			ToSeatID:    "1B",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code after swapping the aircraft and notify the moved passengers",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"aircraft_type": "SMALL"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"id":"f1",
+					"aircraft_type":"SMALL",
+					"moves":[
+						{"passenger_id":"p2","locator":"BBBBBB","from_seat_id":"1C","to_seat_id":"1B"}
+					]
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				stored := swapped
+				stored.Version = 4
+				m.flightsRepo.On("SwapAircraft", swapped, moves).Return(stored, nil).Once()
+				m.enqueuer.On(
+					"SendMsg",
+					model.QueueMsgSeatChanged{
+						Type:            model.QueueMsgTypeSeatChanged,
+						Locator:         "BBBBBB",
+						FlightID:        "f1",
+						FlightDeparture: "2020-05-01T00:00:00+0000",
+						FromSeatID:      "1C",
+						ToSeatLetter:    "B",
+						ToSeatRow:       1,
+						UserID:          "p2",
+					},
+					"queue",
+				).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the passengers do not fit in the new aircraft",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"aircraft_type": "SMALL"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, seatmap.ErrPassengersDoNotFit),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				full := flight
+				full.Seats = append([]model.FlightSeat{}, flight.Seats...)
+				full.Seats = append(full.Seats, model.FlightSeat{ID: "1D", Letter: "D", Row: 1, PassengerID: "p3"})
+				m.flightsRepo.On("Find", "f1").Return(full, nil).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because the flight changed meanwhile",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"aircraft_type": "SMALL"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrStaleFlight),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				m.flightsRepo.On("SwapAircraft", swapped, moves).Return(model.Flight{}, repository.ErrStaleFlight).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because the flight was cancelled",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"aircraft_type": "SMALL"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrFlightClosed),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				cancelled := flight
+				cancelled.Status = model.FlightStatusCancelled
+				m.flightsRepo.On("Find", "f1").Return(cancelled, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because too many passengers move to fit in one transaction",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"aircraft_type": "SMALL"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrTooManySeatMoves),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(flight, nil).Once()
+				m.flightsRepo.On("SwapAircraft", swapped, moves).Return(model.Flight{}, repository.ErrTooManySeatMoves).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because the aircraft type is unknown",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"aircraft_type": "HUGE"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, aircraft.ErrUnknownAircraftType),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 404 status because the flight was not found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f2",
+				},
+				Body: `{"aircraft_type": "SMALL"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f2").Return(model.Flight{}, repository.ErrNoFlightsFound).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because the aircraft type is missing",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 500 status because the flight could not be found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"aircraft_type": "SMALL"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("Find", "f1").Return(model.Flight{}, errors.New("unexpected")).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo, catalog, tt.mocks.enqueuer, "queue")
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+			tt.mocks.enqueuer.AssertExpectations(t)
+		})
+	}
+
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/pricing"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	UpdateDeparture(id string, departure string) error
+	UpdateFares(id string, fares model.Fares) error
+}
+
+type Request struct {
+	Departure string       `json:"departure"`
+	Fares     *model.Fares `json:"fares"`
+}
+
+func Adapter(flightsRepo FlightsRepository) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		flightID := req.PathParameters["id"]
+
+		request := Request{}
+		err := json.Unmarshal([]byte(req.Body), &request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Validations
+		if internal.TrimLines(flightID) == "" ||
+			(internal.TrimLines(request.Departure) == "" && request.Fares == nil) {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+		if request.Fares != nil {
+			err = pricing.ValidateFares(*request.Fares)
+			if err != nil {
+				return internal.Error(http.StatusBadRequest, err), nil
+			}
+		}
+		departure := ""
+		if request.Departure != "" {
+			departure, err = model.NormalizeDeparture(request.Departure)
+			if err != nil {
+				return internal.Error(http.StatusBadRequest, errors.New("invalid departure")), nil
+			}
+		}
+
+		// Update departure and fares, seats and passengers are left untouched
+		if departure != "" {
+			err = flightsRepo.UpdateDeparture(flightID, departure)
+			if err == repository.ErrNoFlightsFound {
+				return internal.Error(http.StatusNotFound, err), nil
+			}
+			if err == repository.ErrStaleFlight {
+				return internal.Error(http.StatusConflict, err), nil
+			}
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+		}
+		if request.Fares != nil {
+			err = flightsRepo.UpdateFares(flightID, *request.Fares)
+			if err == repository.ErrNoFlightsFound {
+				return internal.Error(http.StatusNotFound, err), nil
+			}
+			if err != nil {
+				return internal.Error(http.StatusInternalServerError, err), nil
+			}
+		}
+
+		return internal.Respond(http.StatusOK, ""), nil
+	}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	lambda.Start(Adapter(flightsRepo))
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/pricing"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) UpdateDeparture(id string, departure string) error {
+	ret := m.Called(id, departure)
+	return ret.Error(0)
+}
+
+func (m *FlightsRepositoryMock) UpdateFares(id string, fares model.Fares) error {
+	ret := m.Called(id, fares)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code after succesfully update the departure",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"departure": "2020-05-02T00:00:00+0000"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateDeparture", "f1", "2020-05-02T00:00:00Z").Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because departure is missing",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because the departure is not a time",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"departure": "tomorrow"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid departure"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 404 status because the flight was not found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"departure": "2020-05-02T00:00:00+0000"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateDeparture", "f1", "2020-05-02T00:00:00Z").Return(repository.ErrNoFlightsFound).Once()
+			},
+		},
+		{
+			name: "Get a 409 status because the flight changed meanwhile",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"departure": "2020-05-02T00:00:00+0000"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusConflict,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrStaleFlight),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateDeparture", "f1", "2020-05-02T00:00:00Z").Return(repository.ErrStaleFlight).Once()
+			},
+		},
+		{
+			name: "Get a 500 status because the repository returned an unexpected error",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"departure": "2020-05-02T00:00:00+0000"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateDeparture", "f1", "2020-05-02T00:00:00Z").Return(errors.New("unexpected")).Once()
+			},
+		},
+		{
+			name: "Get a 200 status code after succesfully update the fares",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{
+					"fares": {
+						"currency": "USD",
+						"cabins": {"economy": 10000},
+						"surcharges": {"window": 1500}
+					}
+				}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateFares", "f1", model.Fares{
+					Currency: "USD",
+					Cabins: map[string]int64{
+						model.CabinEconomy: 10000,
+					},
+					Surcharges: map[string]int64{
+						model.SeatPositionWindow: 1500,
+					},
+				}).Return(nil).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because the fares are invalid",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"fares": {"currency": "dollars"}}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, pricing.ErrInvalidFares),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo)
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+		})
+	}
+
+}
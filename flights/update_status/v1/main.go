@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+)
+
+var (
+	ErrUnknownStatus = errors.New("unknown_status")
+)
+
+type Handler func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+
+type FlightsRepository interface {
+	UpdateStatus(id string, change model.FlightStatusChange) (model.Flight, error)
+}
+
+type Enqueuer interface {
+	SendMsg(msg interface{}, queue string) error
+}
+
+type Request struct {
+	Status    string `json:"status"`
+	Departure string `json:"departure"`
+	Reason    string `json:"reason"`
+}
+
+type Response struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Departure string `json:"departure"`
+}
+
+func Adapter(flightsRepo FlightsRepository, enqueuer Enqueuer, notificationsQueue string, now func() time.Time) Handler {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		// Get request parameters
+		flightID := req.PathParameters["id"]
+
+		request := Request{}
+		err := json.Unmarshal([]byte(req.Body), &request)
+		if err != nil {
+			return internal.Error(http.StatusBadRequest, err), nil
+		}
+
+		// Validations
+		if internal.TrimLines(flightID) == "" ||
+			internal.TrimLines(request.Status) == "" ||
+			(request.Status == model.FlightStatusDelayed && internal.TrimLines(request.Departure) == "") {
+			return internal.Error(http.StatusBadRequest, errors.New("missing required fields")), nil
+		}
+		if !model.IsFlightStatus(request.Status) {
+			return internal.Error(http.StatusBadRequest, ErrUnknownStatus), nil
+		}
+
+		// Only a delay moves the departure
+		change := model.FlightStatusChange{
+			Status:    request.Status,
+			Reason:    request.Reason,
+			ChangedAt: now().UTC().Format(time.RFC3339),
+		}
+		if request.Status == model.FlightStatusDelayed {
+			change.Departure, err = model.NormalizeDeparture(request.Departure)
+			if err != nil {
+				return internal.Error(http.StatusBadRequest, errors.New("invalid departure")), nil
+			}
+		}
+
+		flight, err := flightsRepo.UpdateStatus(flightID, change)
+		if err == repository.ErrNoFlightsFound {
+			return internal.Error(http.StatusNotFound, err), nil
+		}
+		if err == repository.ErrInvalidStatusChange {
+			return internal.Error(http.StatusUnprocessableEntity, err), nil
+		}
+		if err == repository.ErrStaleFlight {
+			return internal.Error(http.StatusConflict, err), nil
+		}
+		if err != nil {
+			return internal.Error(http.StatusInternalServerError, err), nil
+		}
+
+		// Let every passenger with a confirmed seat know, one message each so
+		// a redelivered message only repeats the email of one passenger
+		notified := map[string]bool{}
+		for _, s := range flight.Seats {
+			if s.PassengerID == "" || s.HeldUntil != "" || notified[s.PassengerID] {
+				continue
+			}
+			notified[s.PassengerID] = true
+			err = enqueuer.SendMsg(
+				model.QueueMsgFlightStatus{
+					Type:              model.QueueMsgTypeFlightStatus,
+					FlightID:          flight.ID,
+					FlightDeparture:   flight.Departure,
+					FlightOrigin:      flight.Origin,
+					FlightDestination: flight.Destination,
+					Status:            flight.Status,
+					Reason:            change.Reason,
+					UserID:            s.PassengerID,
+				},
+				notificationsQueue,
+			)
+			if err != nil {
+				log.Printf("An error ocurred while sending message to queue %v: %v", notificationsQueue, err)
+			}
+		}
+
+		responseBytes, _ := json.Marshal(Response{
+			ID:        flight.ID,
+			Status:    flight.Status,
+			Departure: flight.Departure,
+		})
+		return internal.Respond(http.StatusOK, string(responseBytes)), nil
+	}
+}
+
+func main() {
+	flightsTable := os.Getenv("DYNAMODB_FLIGHTS")
+	if internal.TrimLines(flightsTable) == "" {
+		panic("DYNAMODB_FLIGHTS is empty")
+	}
+	reservationsTable := os.Getenv("DYNAMODB_RESERVATIONS")
+	if internal.TrimLines(reservationsTable) == "" {
+		panic("DYNAMODB_RESERVATIONS is empty")
+	}
+	notificationsQueue := os.Getenv("NOTIFICATIONS_QUEUE")
+	if internal.TrimLines(notificationsQueue) == "" {
+		panic("NOTIFICATIONS_QUEUE is empty")
+	}
+	session := session.New()
+	dynamodbClient := dynamodb.New(session)
+	flightsRepo := repository.NewFlightsRepository(dynamodbClient, flightsTable, reservationsTable)
+	sqsClient := sqs.New(session)
+	enqueuer := internal.NewEnqueuer(sqsClient)
+	lambda.Start(Adapter(flightsRepo, enqueuer, notificationsQueue, time.Now))
+}
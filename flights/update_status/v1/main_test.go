@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-cmp/cmp"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/model"
+	"github.com/meetupaws/flight_seat_reservation/flights/internal/repository"
+	"github.com/meetupaws/flight_seat_reservation/internal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type FlightsRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *FlightsRepositoryMock) UpdateStatus(id string, change model.FlightStatusChange) (model.Flight, error) {
+	ret := m.Called(id, change)
+	return ret.Get(0).(model.Flight), ret.Error(1)
+}
+
+type EnqueuerMock struct {
+	mock.Mock
+}
+
+func (m *EnqueuerMock) SendMsg(msg interface{}, queue string) error {
+	ret := m.Called(msg, queue)
+	return ret.Error(0)
+}
+
+func TestAdapter(t *testing.T) {
+
+	type mocks struct {
+		flightsRepo *FlightsRepositoryMock
+		enqueuer    *EnqueuerMock
+	}
+
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	seats := []model.FlightSeat{
+		{
+			ID:          "1A",
+			PassengerID: "p1",
+		},
+		{
+			ID:          "1B",
+			PassengerID: "p2",
+			HeldUntil:   "2020-05-01T12:10:00Z",
+		},
+		{
+			ID: "1C",
+		},
+		{
+			ID:          "2A",
+			PassengerID: "p3",
+		},
+		{
+			ID:          "2B",
+			PassengerID: "p3",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		req    events.APIGatewayProxyRequest
+		want   events.APIGatewayProxyResponse
+		mocks  mocks
+		mocker func(m mocks)
+	}{
+		{
+			name: "Get a 200 status code after delaying the flight and notify its passengers",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{
+						"status": "delayed",
+						"departure": "2020-05-01T21:00:00-05:00",
+						"reason": "late inbound aircraft"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"id":"f1",
+					"status":"delayed",
+					"departure":"2020-05-02T02:00:00Z"
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateStatus", "f1", model.FlightStatusChange{
+					Status:    model.FlightStatusDelayed,
+					Departure: "2020-05-02T02:00:00Z",
+					Reason:    "late inbound aircraft",
+					ChangedAt: "2020-05-01T12:00:00Z",
+				}).Return(model.Flight{
+					ID:        "f1",
+					Departure: "2020-05-02T02:00:00Z",
+					Status:    model.FlightStatusDelayed,
+					Seats:     seats,
+				}, nil).Once()
+				for _, userID := range []string{"p1", "p3"} {
+					m.enqueuer.On(
+						"SendMsg",
+						model.QueueMsgFlightStatus{
+							Type:            model.QueueMsgTypeFlightStatus,
+							FlightID:        "f1",
+							FlightDeparture: "2020-05-02T02:00:00Z",
+							Status:          model.FlightStatusDelayed,
+							Reason:          "late inbound aircraft",
+							UserID:          userID,
+						},
+						"queue",
+					).Return(nil).Once()
+				}
+			},
+		},
+		{
+			name: "Get a 200 status code after cancelling a flight without passengers",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{
+						"status": "cancelled",
+						"departure": "2020-05-02T02:00:00+0000"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{
+					"id":"f1",
+					"status":"cancelled",
+					"departure":"2020-05-02T00:00:00+0000"
+				}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateStatus", "f1", model.FlightStatusChange{
+					Status:    model.FlightStatusCancelled,
+					ChangedAt: "2020-05-01T12:00:00Z",
+				}).Return(model.Flight{
+					ID:        "f1",
+					Departure: "2020-05-02T00:00:00+0000",
+					Status:    model.FlightStatusCancelled,
+				}, nil).Once()
+			},
+		},
+		{
+			name: "Get a 422 status because a departed flight can not be delayed",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{
+						"status": "delayed",
+						"departure": "2020-05-02T02:00:00+0000"
+					}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusUnprocessableEntity,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrInvalidStatusChange),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateStatus", "f1", mock.AnythingOfType("model.FlightStatusChange")).Return(
+					model.Flight{},
+					repository.ErrInvalidStatusChange,
+				).Once()
+			},
+		},
+		{
+			name: "Get a 404 status because the flight was not found",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f2",
+				},
+				Body: `{"status": "boarding"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusNotFound,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, repository.ErrNoFlightsFound),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateStatus", "f2", mock.AnythingOfType("model.FlightStatusChange")).Return(
+					model.Flight{},
+					repository.ErrNoFlightsFound,
+				).Once()
+			},
+		},
+		{
+			name: "Get a 400 status because a delay needs the new departure",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"status": "delayed"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["missing required fields"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because the new departure is not a time",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"status": "delayed", "departure": "2020-05-02 02:00"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["invalid departure"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 400 status because of an unknown status",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"status": "landed"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusBadRequest,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(
+					fmt.Sprintf(`{"errors":["%s"]}`, ErrUnknownStatus),
+				),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {},
+		},
+		{
+			name: "Get a 500 status because the flight could not be updated",
+			req: events.APIGatewayProxyRequest{
+				PathParameters: map[string]string{
+					"id": "f1",
+				},
+				Body: `{"status": "boarding"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusInternalServerError,
+				Headers: map[string]string{
+					"Content-Type": "application/json",
+				},
+				Body: internal.TrimLines(`{"errors":["unexpected"]}`),
+			},
+			mocks: mocks{
+				flightsRepo: &FlightsRepositoryMock{},
+				enqueuer:    &EnqueuerMock{},
+			},
+			mocker: func(m mocks) {
+				m.flightsRepo.On("UpdateStatus", "f1", mock.AnythingOfType("model.FlightStatusChange")).Return(
+					model.Flight{},
+					errors.New("unexpected"),
+				).Once()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tt.mocker(tt.mocks)
+
+			// Act
+			handler := Adapter(tt.mocks.flightsRepo, tt.mocks.enqueuer, "queue", func() time.Time { return now })
+			got, err := handler(context.Background(), tt.req)
+
+			// Assert
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Differences found: (-want,+got)\n%s", diff)
+			}
+			tt.mocks.flightsRepo.AssertExpectations(t)
+			tt.mocks.enqueuer.AssertExpectations(t)
+		})
+	}
+
+}
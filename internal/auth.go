@@ -0,0 +1,23 @@
+package internal
+
+import (
+	"errors"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// AuthenticatedEmail returns the email of the user signed in with the Cognito
+// authorizer of the endpoint
+func AuthenticatedEmail(req events.APIGatewayProxyRequest) (string, error) {
+	claims, ok := req.RequestContext.Authorizer["claims"].(map[string]interface{})
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	email, ok := claims["email"].(string)
+	if !ok || TrimLines(email) == "" {
+		return "", ErrUnauthenticated
+	}
+	return email, nil
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
@@ -12,6 +13,16 @@ type Enqueuer struct {
 }
 
 func (e *Enqueuer) SendMsg(msg interface{}, queue string) error {
+	return e.sendMsg(msg, queue, 10)
+}
+
+// SendDelayedMsg sends a message that can not be received until the delay
+// passes, SQS allows up to 15 minutes
+func (e *Enqueuer) SendDelayedMsg(msg interface{}, queue string, delay time.Duration) error {
+	return e.sendMsg(msg, queue, int64(delay/time.Second))
+}
+
+func (e *Enqueuer) sendMsg(msg interface{}, queue string, delaySeconds int64) error {
 	msgBytes, err := json.Marshal(msg)
 	if err != nil {
 		return err
@@ -25,7 +36,7 @@ func (e *Enqueuer) SendMsg(msg interface{}, queue string) error {
 	}
 
 	_, err = e.client.SendMessage(&sqs.SendMessageInput{
-		DelaySeconds: aws.Int64(10),
+		DelaySeconds: aws.Int64(delaySeconds),
 		MessageBody:  aws.String(string(msgBytes)),
 		QueueUrl:     queueURL.QueueUrl,
 	})
@@ -1,6 +1,14 @@
 package internal
 
 import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ses"
 )
@@ -9,6 +17,12 @@ type Mailer struct {
 	client *ses.SES
 }
 
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
 func (m *Mailer) SendEmail(
 	subject string,
 	body string,
@@ -38,6 +52,75 @@ func (m *Mailer) SendEmail(
 	return err
 }
 
+// SendEmailWithAttachments sends a multipart MIME email, SES only takes
+// attachments as raw messages
+func (m *Mailer) SendEmailWithAttachments(
+	subject string,
+	body string,
+	from string,
+	to []string,
+	attachments []Attachment,
+) error {
+	raw, err := rawMessage(subject, body, from, to, attachments)
+	if err != nil {
+		return err
+	}
+	_, err = m.client.SendRawEmail(&ses.SendRawEmailInput{
+		Destinations: m.toPtrSlice(to),
+		RawMessage: &ses.RawMessage{
+			Data: raw,
+		},
+		Source: aws.String(from),
+	})
+	return err
+}
+
+func rawMessage(subject string, body string, from string, to []string, attachments []Attachment) ([]byte, error) {
+	buf := bytes.Buffer{}
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=UTF-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, err = part.Write([]byte(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range attachments {
+		part, err = writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		// Lines of base64 content can not be longer than 76 characters
+		for len(encoded) > 76 {
+			fmt.Fprintf(part, "%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		fmt.Fprintf(part, "%s\r\n", encoded)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (m *Mailer) toPtrSlice(ss []string) []*string {
 	ptrSlice := []*string{}
 	for _, s := range ss {
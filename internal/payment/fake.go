@@ -0,0 +1,85 @@
+package payment
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FakeDeclinedToken makes the fake provider decline the authorization
+const FakeDeclinedToken = "tok_declined"
+
+const (
+	fakeAuthorized = "authorized"
+	fakeCaptured   = "captured"
+	fakeRefunded   = "refunded"
+)
+
+// FakeProvider keeps the payments in memory, it is meant for tests and local
+// environments
+type FakeProvider struct {
+	mux      sync.Mutex
+	payments map[string]string
+	sequence int
+}
+
+func (p *FakeProvider) Authorize(req AuthorizationRequest) (Authorization, error) {
+	if req.Token == FakeDeclinedToken || req.Amount <= 0 {
+		return Authorization{}, ErrPaymentDeclined
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.sequence++
+	id := fmt.Sprintf("fake_auth_%d", p.sequence)
+	p.payments[id] = fakeAuthorized
+
+	return Authorization{
+		ID:       id,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+	}, nil
+}
+
+func (p *FakeProvider) Capture(authorizationID string) error {
+	return p.transition(authorizationID, fakeAuthorized, fakeCaptured)
+}
+
+// Refund voids an authorization or gives the money back if it was captured
+func (p *FakeProvider) Refund(authorizationID string) error {
+	p.mux.Lock()
+	state := p.payments[authorizationID]
+	p.mux.Unlock()
+
+	if state == fakeCaptured {
+		return p.transition(authorizationID, fakeCaptured, fakeRefunded)
+	}
+	return p.transition(authorizationID, fakeAuthorized, fakeRefunded)
+}
+
+// State returns the state of an authorization, empty if it does not exist
+func (p *FakeProvider) State(authorizationID string) string {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.payments[authorizationID]
+}
+
+func (p *FakeProvider) transition(authorizationID string, from string, to string) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	state, ok := p.payments[authorizationID]
+	if !ok {
+		return ErrAuthorizationNotFound
+	}
+	if state != from {
+		return ErrInvalidTransition
+	}
+	p.payments[authorizationID] = to
+	return nil
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		payments: map[string]string{},
+	}
+}
@@ -0,0 +1,62 @@
+package payment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeProvider(t *testing.T) {
+	provider := NewFakeProvider()
+
+	// Authorize and capture
+	authorization, err := provider.Authorize(AuthorizationRequest{
+		Amount:    11500,
+		Currency:  "USD",
+		Reference: "f1/1A",
+		Token:     "tok_visa",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(11500), authorization.Amount)
+	require.Equal(t, fakeAuthorized, provider.State(authorization.ID))
+
+	require.NoError(t, provider.Capture(authorization.ID))
+	require.Equal(t, fakeCaptured, provider.State(authorization.ID))
+	require.Equal(t, ErrInvalidTransition, provider.Capture(authorization.ID))
+
+	// Refund a captured payment
+	require.NoError(t, provider.Refund(authorization.ID))
+	require.Equal(t, fakeRefunded, provider.State(authorization.ID))
+	require.Equal(t, ErrInvalidTransition, provider.Refund(authorization.ID))
+
+	// Void an authorization
+	authorization, err = provider.Authorize(AuthorizationRequest{
+		Amount:   11500,
+		Currency: "USD",
+		Token:    "tok_visa",
+	})
+	require.NoError(t, err)
+	require.NoError(t, provider.Refund(authorization.ID))
+	require.Equal(t, fakeRefunded, provider.State(authorization.ID))
+
+	// Declined and unknown authorizations
+	_, err = provider.Authorize(AuthorizationRequest{
+		Amount:   11500,
+		Currency: "USD",
+		Token:    FakeDeclinedToken,
+	})
+	require.Equal(t, ErrPaymentDeclined, err)
+	require.Equal(t, ErrAuthorizationNotFound, provider.Capture("nope"))
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := NewProvider("fake", "dev")
+	require.NoError(t, err)
+	require.IsType(t, &FakeProvider{}, provider)
+
+	_, err = NewProvider("fake", "prod")
+	require.Equal(t, ErrFakeProviderStage, err)
+
+	_, err = NewProvider("bank", "dev")
+	require.Equal(t, ErrUnknownProvider, err)
+}
@@ -0,0 +1,53 @@
+package payment
+
+import (
+	"errors"
+)
+
+var (
+	ErrPaymentDeclined       = errors.New("payment_declined")
+	ErrAuthorizationNotFound = errors.New("authorization_not_found")
+	ErrInvalidTransition     = errors.New("invalid_payment_transition")
+	ErrUnknownProvider       = errors.New("unknown_payment_provider")
+	ErrFakeProviderStage     = errors.New("fake_payment_provider_outside_dev")
+)
+
+// fakeStages are the stages the fake provider can run in, it takes no money
+var fakeStages = map[string]bool{
+	"dev":  true,
+	"test": true,
+}
+
+type AuthorizationRequest struct {
+	Amount    int64
+	Currency  string
+	Reference string
+	Token     string
+}
+
+type Authorization struct {
+	ID       string
+	Amount   int64
+	Currency string
+}
+
+// PaymentProvider moves the money of a payment, an authorization holds the
+// amount until it is captured or refunded
+type PaymentProvider interface {
+	Authorize(req AuthorizationRequest) (Authorization, error)
+	Capture(authorizationID string) error
+	Refund(authorizationID string) error
+}
+
+// NewProvider returns the payment provider of the stage the service is
+// deployed to, e.g. dev
+func NewProvider(name string, stage string) (PaymentProvider, error) {
+	switch name {
+	case "fake":
+		if !fakeStages[stage] {
+			return nil, ErrFakeProviderStage
+		}
+		return NewFakeProvider(), nil
+	}
+	return nil, ErrUnknownProvider
+}